@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"buildfly/pkg/downloader"
+
+	"github.com/spf13/cobra"
+)
+
+// newCacheCmd 创建 cache 命令
+func newCacheCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "管理缓存",
+		Long: `查看和清理下载缓存、构建缓存以及 Git 镜像。
+
+Git 依赖在缓存目录的 git/ 下为每个远程仓库保存一个裸镜像，
+之后的下载只从远程增量获取，再从本地镜像生成源码树。`,
+	}
+
+	cmd.AddCommand(newCacheLsCmd())
+	cmd.AddCommand(newCacheGCCmd())
+
+	return cmd
+}
+
+// newCacheLsCmd 创建 cache ls 命令
+func newCacheLsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "ls",
+		Short: "列出缓存内容和 Git 镜像大小",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := GlobalCLIContext.Initialize(); err != nil {
+				return fmt.Errorf("failed to initialize context: %w", err)
+			}
+			return showCacheInfo(GlobalCLIContext.CacheManager, true)
+		},
+	}
+}
+
+// newCacheGCCmd 创建 cache gc 命令
+func newCacheGCCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "gc",
+		Short: "清理过期的缓存和长期未使用的 Git 镜像",
+		Long: `清理超过 --max-cache-age 的缓存项，删除在此期间未使用的 Git 镜像，
+并对保留的镜像执行 git gc --auto。`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCacheGC()
+		},
+	}
+}
+
+// runCacheGC 执行缓存清理
+func runCacheGC() error {
+	if err := GlobalCLIContext.Initialize(); err != nil {
+		return fmt.Errorf("failed to initialize context: %w", err)
+	}
+
+	maxAge := GlobalCLIContext.parseMaxCacheAge()
+	if err := GlobalCLIContext.CacheManager.Cleanup(); err != nil {
+		return fmt.Errorf("failed to clean up expired cache: %w", err)
+	}
+
+	mirrorDir := downloader.GitMirrorDir(GlobalCLIContext.ProjectConfig.CacheDir)
+	removed, err := downloader.PruneGitMirrors(context.Background(), mirrorDir, maxAge)
+	for _, mirror := range removed {
+		printf("Removed git mirror %s (%s)\n", mirrorLabel(mirror), formatBytes(mirror.Size))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to prune git mirrors: %w", err)
+	}
+
+	var reclaimed int64
+	for _, mirror := range removed {
+		reclaimed += mirror.Size
+	}
+	printf("Cache gc completed: removed %d git mirrors unused for %s, reclaimed %s\n", len(removed), maxAge, formatBytes(reclaimed))
+	return nil
+}
+
+// gitMirrorOutput Git 镜像的结构化输出
+type gitMirrorOutput struct {
+	URL      string    `json:"url"`
+	Path     string    `json:"path"`
+	Size     int64     `json:"size"`
+	LFSSize  int64     `json:"lfs_size,omitempty"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// listGitMirrors 列出缓存目录中的 Git 镜像
+func listGitMirrors() ([]downloader.GitMirror, string, error) {
+	mirrorDir := downloader.GitMirrorDir(GlobalCLIContext.ProjectConfig.CacheDir)
+	mirrors, err := downloader.ListGitMirrors(mirrorDir)
+	return mirrors, mirrorDir, err
+}
+
+// printGitMirrors 打印 Git 镜像表格
+func printGitMirrors(mirrors []downloader.GitMirror) {
+	if len(mirrors) == 0 {
+		return
+	}
+
+	var total int64
+	for _, mirror := range mirrors {
+		total += mirror.Size
+	}
+
+	printf("\nGit mirrors: %d (%s)\n", len(mirrors), formatBytes(total))
+	printf("%-50s %-12s %s\n", "REMOTE", "SIZE", "LAST USED")
+	printLine(strings.Repeat("-", 80))
+	for _, mirror := range mirrors {
+		printf("%-50s %-12s %s\n", mirrorLabel(mirror), formatBytes(mirror.Size), mirror.LastUsed.Format("2006-01-02 15:04"))
+	}
+}
+
+// mirrorLabel 镜像的显示名称，优先使用远程 URL
+func mirrorLabel(mirror downloader.GitMirror) string {
+	if mirror.URL != "" {
+		return mirror.URL
+	}
+	return mirror.Path
+}
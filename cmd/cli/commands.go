@@ -4,11 +4,12 @@ import (
 	"buildfly/internal/constants"
 	"buildfly/pkg/cache"
 	"buildfly/pkg/config"
-	"buildfly/pkg/venv"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -168,7 +169,7 @@ func newConfigResetCmd() *cobra.Command {
 
 // runBuild 执行构建
 func runBuild(deps []string, force bool, profile string) error {
-	fmt.Println("Build command not implemented yet")
+	printLine("Build command not implemented yet")
 	return nil
 }
 
@@ -199,22 +200,22 @@ func runVersion(short bool) error {
 	buildInfo := constants.GetBuildInfo()
 
 	if short {
-		fmt.Println(buildInfo.Version)
+		printLine(buildInfo.Version)
 		return nil
 	}
 
-	fmt.Printf("BuildFly %s\n", buildInfo.Version)
-	fmt.Println("A C++ dependency manager written in Go")
-	fmt.Println()
+	printf("BuildFly %s\n", buildInfo.Version)
+	printLine("A C++ dependency manager written in Go")
+	printLine()
 
 	if buildInfo.GitCommit != "unknown" {
-		fmt.Printf("Git Commit: %s\n", buildInfo.GitCommit)
+		printf("Git Commit: %s\n", buildInfo.GitCommit)
 	}
 	if buildInfo.BuildDate != "unknown" {
-		fmt.Printf("Build Date: %s\n", buildInfo.BuildDate)
+		printf("Build Date: %s\n", buildInfo.BuildDate)
 	}
 	if buildInfo.GoVersion != "unknown" {
-		fmt.Printf("Go Version: %s\n", buildInfo.GoVersion)
+		printf("Go Version: %s\n", buildInfo.GoVersion)
 	}
 
 	return nil
@@ -229,9 +230,9 @@ func runClean(all, cache, deps, dryRun bool) error {
 		}
 		cacheDir := GlobalCLIContext.ProjectConfig.CacheDir
 		if dryRun {
-			fmt.Printf("Would clean cache directory: %s\n", cacheDir)
+			printf("Would clean cache directory: %s\n", cacheDir)
 		} else {
-			fmt.Printf("Cleaning cache directory: %s\n", cacheDir)
+			printf("Cleaning cache directory: %s\n", cacheDir)
 			if err := os.RemoveAll(cacheDir); err != nil {
 				return fmt.Errorf("failed to clean cache: %w", err)
 			}
@@ -242,9 +243,9 @@ func runClean(all, cache, deps, dryRun bool) error {
 		depsDir := "deps"
 		if _, err := os.Stat(depsDir); err == nil {
 			if dryRun {
-				fmt.Printf("Would clean dependencies directory: %s\n", depsDir)
+				printf("Would clean dependencies directory: %s\n", depsDir)
 			} else {
-				fmt.Printf("Cleaning dependencies directory: %s\n", depsDir)
+				printf("Cleaning dependencies directory: %s\n", depsDir)
 				if err := os.RemoveAll(depsDir); err != nil {
 					return fmt.Errorf("failed to clean dependencies: %w", err)
 				}
@@ -252,7 +253,7 @@ func runClean(all, cache, deps, dryRun bool) error {
 		}
 	}
 
-	fmt.Println("Clean completed")
+	printLine("Clean completed")
 	return nil
 }
 
@@ -280,7 +281,7 @@ func runInit(name, template string, force bool) error {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
-	fmt.Printf("Initialized BuildFly project '%s' with template '%s'\n", name, template)
+	printf("Initialized BuildFly project '%s' with template '%s'\n", name, template)
 	return nil
 }
 
@@ -388,63 +389,146 @@ func runList(verbose, showCache bool) error {
 	return showDependencyList(projectConfig, cacheMgr, verbose)
 }
 
+// dependencyStatus 依赖的缓存和安装状态
+type dependencyStatus struct {
+	Name           string `json:"name"`
+	Version        string `json:"version"`
+	SourceType     string `json:"source_type"`
+	BuildSystem    string `json:"build_system"`
+	Downloaded     bool   `json:"downloaded"`
+	BuildCached    bool   `json:"build_cached"`
+	Installed      bool   `json:"installed"`
+	InstallPath    string `json:"install_path"`
+	TestStatus     string `json:"test_status,omitempty"`
+	TestDurationMS int64  `json:"test_duration_ms,omitempty"`
+
+	Signature *cache.SourceSignature `json:"signature,omitempty"` // 下载缓存校验通过的源码签名
+}
+
+// dependencyListOutput list 命令的结构化输出
+type dependencyListOutput struct {
+	Project      string             `json:"project"`
+	BuildTag     string             `json:"build_tag"`
+	Dependencies []dependencyStatus `json:"dependencies"`
+}
+
+// collectDependencyStatus 收集依赖的缓存和安装状态，按名称排序
+func collectDependencyStatus(projectConfig *config.ProjectConfig, cacheMgr *cache.CacheManager) []dependencyStatus {
+	statuses := make([]dependencyStatus, 0, len(projectConfig.Dependencies))
+	for name, dep := range projectConfig.Dependencies {
+		installPath := filepath.Join(getInstallDir(projectConfig), name)
+		_, statErr := os.Stat(installPath)
+
+		status := dependencyStatus{
+			Name:        name,
+			Version:     dep.Version,
+			SourceType:  dep.Source.Type,
+			BuildSystem: dep.BuildSystem,
+			Downloaded:  cacheMgr.IsCachedDownloads(dep),
+			BuildCached: cacheMgr.IsBuildCached(dep, projectConfig.BuildTag),
+			Installed:   statErr == nil,
+			InstallPath: installPath,
+		}
+		if metadata, err := cacheMgr.LoadBuildMetadata(dep, projectConfig.BuildTag); err == nil && metadata.Test != nil {
+			status.TestStatus = metadata.Test.Status
+			status.TestDurationMS = metadata.Test.Duration.Milliseconds()
+		}
+		status.Signature = cacheMgr.DownloadSignature(dep)
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
 // showDependencyList 显示依赖列表
 func showDependencyList(projectConfig *config.ProjectConfig, cacheMgr *cache.CacheManager, verbose bool) error {
-	fmt.Printf("Dependencies in project '%s':\n", projectConfig.Project.Name)
+	if isStructuredOutput() {
+		return writeOutput("list", dependencyListOutput{
+			Project:      projectConfig.Project.Name,
+			BuildTag:     projectConfig.BuildTag.String(),
+			Dependencies: collectDependencyStatus(projectConfig, cacheMgr),
+		})
+	}
+
+	printf("Dependencies in project '%s':\n", projectConfig.Project.Name)
 
 	if len(projectConfig.Dependencies) == 0 {
-		fmt.Println("  No dependencies configured")
+		printLine("  No dependencies configured")
 		return nil
 	}
 
 	if verbose {
 		// 详细模式
-		fmt.Printf("%-15s %-10s %-9s %-7s %-10s %-12s %s\n",
-			"NAME", "VERSION", "DOWNLOAD", "BUILD", "SOURCE", "BUILD SYS", "INSTALL PATH")
-		fmt.Println(strings.Repeat("-", 80))
+		printf("%-15s %-10s %-9s %-7s %-10s %-12s %-18s %s\n",
+			"NAME", "VERSION", "DOWNLOAD", "BUILD", "SOURCE", "BUILD SYS", "TEST", "INSTALL PATH")
+		printLine(strings.Repeat("-", 100))
 	} else {
 		// 简单模式
-		fmt.Printf("%-15s %-10s %-9s %-7s %-10s %-12s\n",
+		printf("%-15s %-10s %-9s %-7s %-10s %-12s\n",
 			"NAME", "VERSION", "DOWNLOAD", "BUILD", "SOURCE", "BUILD SYS")
-		fmt.Println(strings.Repeat("-", 70))
+		printLine(strings.Repeat("-", 70))
 	}
 
-	for name, dep := range projectConfig.Dependencies {
-		// 检查缓存状态
-		downloadCached := cacheMgr.IsCachedDownloads(dep)
-		buildCached := cacheMgr.IsBuildCached(dep, projectConfig.BuildTag)
-
+	for _, status := range collectDependencyStatus(projectConfig, cacheMgr) {
 		downloadStatus := "✗"
-		if downloadCached {
+		if status.Downloaded {
 			downloadStatus = "✓"
 		}
 
 		buildStatus := "✗"
-		if buildCached {
+		if status.BuildCached {
 			buildStatus = "✓"
 		}
 
 		if verbose {
-			installPath := filepath.Join(getInstallDir(projectConfig), name)
-			fmt.Printf("%-15s %-10s %-9s %-7s %-10s %-12s %s\n",
-				name, dep.Version, downloadStatus, buildStatus, dep.Source.Type, dep.BuildSystem, installPath)
+			testStatus := formatTestStatus(cacheMgr, projectConfig.Dependencies[status.Name], projectConfig.BuildTag)
+			printf("%-15s %-10s %-9s %-7s %-10s %-12s %-18s %s\n",
+				status.Name, status.Version, downloadStatus, buildStatus, status.SourceType, status.BuildSystem, testStatus, status.InstallPath)
 		} else {
-			fmt.Printf("%-15s %-10s %-9s %-7s %-10s %-12s\n",
-				name, dep.Version, downloadStatus, buildStatus, dep.Source.Type, dep.BuildSystem)
+			printf("%-15s %-10s %-9s %-7s %-10s %-12s\n",
+				status.Name, status.Version, downloadStatus, buildStatus, status.SourceType, status.BuildSystem)
 		}
 	}
 
 	// 显示图例
-	fmt.Println("\nLegend:")
-	fmt.Println("  ✓ = cached/downloaded")
-	fmt.Println("  ✗ = not cached/downloaded")
+	printLine("\nLegend:")
+	printLine("  ✓ = cached/downloaded")
+	printLine("  ✗ = not cached/downloaded")
 
 	return nil
 }
 
+// formatTestStatus 格式化依赖最近一次测试的结果
+func formatTestStatus(cacheMgr *cache.CacheManager, dep config.Dependency, buildTag *config.BuildTag) string {
+	metadata, err := cacheMgr.LoadBuildMetadata(dep, buildTag)
+	if err != nil || metadata.Test == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%s (%s)", metadata.Test.Status, metadata.Test.Duration.Round(time.Second))
+}
+
+// cacheItemOutput 缓存项的结构化输出
+type cacheItemOutput struct {
+	Path     string    `json:"path"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	Expired  bool      `json:"expired"`
+}
+
+// cacheInfoOutput list --cache 和 cache ls 的结构化输出
+type cacheInfoOutput struct {
+	TotalSize  int64             `json:"total_size"`
+	Expired    int               `json:"expired"`
+	Items      []cacheItemOutput `json:"items"`
+	GitMirrors []gitMirrorOutput `json:"git_mirrors"`
+}
+
 // showCacheInfo 显示缓存信息
 func showCacheInfo(cacheMgr *cache.CacheManager, verbose bool) error {
-	fmt.Println("Cache Statistics:")
+	if !isStructuredOutput() {
+		printLine("Cache Statistics:")
+	}
 
 	// 获取缓存大小
 	cacheSize, err := cacheMgr.GetCacheSize()
@@ -452,11 +536,21 @@ func showCacheInfo(cacheMgr *cache.CacheManager, verbose bool) error {
 		return fmt.Errorf("failed to get cache size: %w", err)
 	}
 
-	// 获取缓存列表
-	cacheInfos, err := cacheMgr.ListCache()
+	// 获取缓存列表，Git 镜像单独列出
+	allInfos, err := cacheMgr.ListCache()
 	if err != nil {
 		return fmt.Errorf("failed to list cache: %w", err)
 	}
+	mirrors, mirrorDir, err := listGitMirrors()
+	if err != nil {
+		return fmt.Errorf("failed to list git mirrors: %w", err)
+	}
+	var cacheInfos []cache.CacheInfo
+	for _, info := range allInfos {
+		if !strings.HasPrefix(info.Path, mirrorDir+string(filepath.Separator)) {
+			cacheInfos = append(cacheInfos, info)
+		}
+	}
 
 	expiredCount := 0
 	for _, info := range cacheInfos {
@@ -465,14 +559,25 @@ func showCacheInfo(cacheMgr *cache.CacheManager, verbose bool) error {
 		}
 	}
 
-	fmt.Printf("  Total size: %s\n", formatBytes(cacheSize))
-	fmt.Printf("  Items: %d\n", len(cacheInfos))
-	fmt.Printf("  Expired: %d\n", expiredCount)
+	if isStructuredOutput() {
+		output := cacheInfoOutput{TotalSize: cacheSize, Expired: expiredCount, Items: []cacheItemOutput{}, GitMirrors: []gitMirrorOutput{}}
+		for _, info := range cacheInfos {
+			output.Items = append(output.Items, cacheItemOutput{Path: info.Path, Size: info.Size, Modified: info.ModTime, Expired: info.Expired})
+		}
+		for _, mirror := range mirrors {
+			output.GitMirrors = append(output.GitMirrors, gitMirrorOutput{URL: mirror.URL, Path: mirror.Path, Size: mirror.Size, LFSSize: mirror.LFSSize, LastUsed: mirror.LastUsed})
+		}
+		return writeOutput("cache", output)
+	}
+
+	printf("  Total size: %s\n", formatBytes(cacheSize))
+	printf("  Items: %d\n", len(cacheInfos))
+	printf("  Expired: %d\n", expiredCount)
 
 	if verbose && len(cacheInfos) > 0 {
-		fmt.Println("\nCache items:")
-		fmt.Printf("%-40s %-12s %-5s %s\n", "NAME", "SIZE", "STATUS", "MODIFIED")
-		fmt.Println(strings.Repeat("-", 75))
+		printLine("\nCache items:")
+		printf("%-40s %-12s %-5s %s\n", "NAME", "SIZE", "STATUS", "MODIFIED")
+		printLine(strings.Repeat("-", 75))
 
 		for _, info := range cacheInfos {
 			name := filepath.Base(info.Path)
@@ -484,10 +589,11 @@ func showCacheInfo(cacheMgr *cache.CacheManager, verbose bool) error {
 			}
 
 			modTime := info.ModTime.Format("2006-01-02 15:04")
-			fmt.Printf("%-40s %-12s %-5s %s\n", name, sizeStr, status, modTime)
+			printf("%-40s %-12s %-5s %s\n", name, sizeStr, status, modTime)
 		}
 	}
 
+	printGitMirrors(mirrors)
 	return nil
 }
 
@@ -516,20 +622,20 @@ func getInstallDir(projectConfig *config.ProjectConfig) string {
 
 // runConfigShow 执行配置显示
 func runConfigShow() error {
-	fmt.Printf("Config file: %s\n", GlobalCLIContext.getConfigFile())
-	fmt.Printf("Verbose mode: %v\n", GlobalCLIContext.GlobalOptions.Verbose)
+	printf("Config file: %s\n", GlobalCLIContext.getConfigFile())
+	printf("Verbose mode: %v\n", GlobalCLIContext.GlobalOptions.Verbose)
 	return nil
 }
 
 // runConfigSet 执行配置设置
 func runConfigSet(key, value string) error {
-	fmt.Printf("Set config %s = %s\n", key, value)
+	printf("Set config %s = %s\n", key, value)
 	return nil
 }
 
 // runConfigReset 执行配置重置
 func runConfigReset() error {
-	fmt.Println("Reset config")
+	printLine("Reset config")
 	return nil
 }
 
@@ -577,8 +683,8 @@ func runRun(args []string) error {
 
 	// 创建或获取 VENV 配置
 	venvConfig := GlobalCLIContext.ProjectConfig.VEnv
-	fmt.Printf("Using venv config: %+v\n", venvConfig)
-	manager, err := venv.NewManager(venvConfig, projectRoot)
+	GlobalCLIContext.Logger.Debug("Using venv config", "config", fmt.Sprintf("%+v", venvConfig))
+	manager, err := newVenvManager(venvConfig, projectRoot)
 	if err != nil {
 		return fmt.Errorf("failed to create venv manager: %w", err)
 	}
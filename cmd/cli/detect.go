@@ -23,10 +23,31 @@ func newDetectCmd() *cobra.Command {
 	return cmd
 }
 
+// detectOutput detect 命令的结构化输出
+type detectOutput struct {
+	BuildTag    string           `json:"build_tag"`
+	DirName     string           `json:"dir_name"`
+	Fields      *config.BuildTag `json:"fields"`
+	EnvBuildTag string           `json:"env_build_tag,omitempty"`
+}
+
 // runDetect 执行检测
 func runDetect(cmd *cobra.Command, args []string) error {
-	fmt.Println("🔍 检测系统构建信息...")
-	fmt.Println()
+	if isStructuredOutput() {
+		buildTag, err := config.DetectBuildTag()
+		if err != nil {
+			return fmt.Errorf("检测失败: %w", err)
+		}
+		return writeOutput("detect", detectOutput{
+			BuildTag:    buildTag.String(),
+			DirName:     buildTag.ToDirName(),
+			Fields:      buildTag,
+			EnvBuildTag: os.Getenv("BUILDFLY_BUILD_TAG"),
+		})
+	}
+
+	printLine("🔍 检测系统构建信息...")
+	printLine()
 
 	// 检测系统信息
 	buildTag, err := config.DetectBuildTag()
@@ -35,116 +56,135 @@ func runDetect(cmd *cobra.Command, args []string) error {
 	}
 
 	// 显示检测结果
-	fmt.Println("📋 检测结果:")
-	fmt.Printf("架构 (arch):     %s\n", buildTag.Arch)
-	fmt.Printf("平台 (platform): %s\n", buildTag.Platform)
-	fmt.Printf("运行时 (runtime): %s\n", buildTag.Runtime)
-	fmt.Printf("编译器 (compiler): %s\n", buildTag.Compiler)
-	fmt.Printf("C++标准 (std):    %s\n", buildTag.Std)
-	fmt.Printf("ABI (abi):        %s\n", buildTag.ABI)
+	printLine("📋 检测结果:")
+	printf("架构 (arch):     %s\n", buildTag.Arch)
+	printf("平台 (platform): %s\n", buildTag.Platform)
+	printf("运行时 (runtime): %s\n", buildTag.Runtime)
+	printf("编译器 (compiler): %s\n", buildTag.Compiler)
+	printf("C++标准 (std):    %s\n", buildTag.Std)
+	printf("ABI (abi):        %s\n", buildTag.ABI)
 	if buildTag.Target != "" {
-		fmt.Printf("目标 (target):    %s\n", buildTag.Target)
+		printf("目标 (target):    %s\n", buildTag.Target)
+	}
+	if buildTag.ISA != "" {
+		printf("指令集 (isa):     %s\n", buildTag.ISA)
+	}
+	if buildTag.Libstdcxx != "" {
+		printf("libstdc++ (libstdcxx): %s\n", buildTag.Libstdcxx)
+	}
+	if buildTag.CXX11ABI != "" {
+		printf("C++11 ABI (cxx11_abi): %s\n", buildTag.CXX11ABI)
 	}
 
 	// 显示 GPU 信息
 	if buildTag.GPU != nil {
-		fmt.Printf("GPU 后端:        %s\n", buildTag.GPU.Backend)
+		printf("GPU 后端:        %s\n", buildTag.GPU.Backend)
 		switch buildTag.GPU.Backend {
 		case "cuda":
 			if buildTag.GPU.CUDA != nil {
-				fmt.Printf("  CUDA 版本:    %s\n", buildTag.GPU.CUDA.Version)
+				printf("  CUDA 版本:    %s\n", buildTag.GPU.CUDA.Version)
 				if len(buildTag.GPU.CUDA.Arch) > 0 {
-					fmt.Printf("  GPU 架构:     %v\n", buildTag.GPU.CUDA.Arch)
+					printf("  GPU 架构:     %v\n", buildTag.GPU.CUDA.Arch)
 				}
-				fmt.Printf("  启用状态:      %t\n", buildTag.GPU.CUDA.Enabled)
+				printf("  启用状态:      %t\n", buildTag.GPU.CUDA.Enabled)
 			}
 		case "rocm":
 			if buildTag.GPU.ROCm != nil {
-				fmt.Printf("  ROCm 版本:    %s\n", buildTag.GPU.ROCm.Version)
+				printf("  ROCm 版本:    %s\n", buildTag.GPU.ROCm.Version)
 				if len(buildTag.GPU.ROCm.Arch) > 0 {
-					fmt.Printf("  GPU 架构:     %v\n", buildTag.GPU.ROCm.Arch)
+					printf("  GPU 架构:     %v\n", buildTag.GPU.ROCm.Arch)
 				}
-				fmt.Printf("  启用状态:      %t\n", buildTag.GPU.ROCm.Enabled)
+				printf("  启用状态:      %t\n", buildTag.GPU.ROCm.Enabled)
 			}
 		case "opencl":
 			if buildTag.GPU.OpenCL != nil {
-				fmt.Printf("  OpenCL 版本:  %s\n", buildTag.GPU.OpenCL.Version)
-				fmt.Printf("  启用状态:      %t\n", buildTag.GPU.OpenCL.Enabled)
+				printf("  OpenCL 版本:  %s\n", buildTag.GPU.OpenCL.Version)
+				printf("  启用状态:      %t\n", buildTag.GPU.OpenCL.Enabled)
 			}
 		}
 	} else {
-		fmt.Println("GPU 后端:        未检测到 GPU")
+		printLine("GPU 后端:        未检测到 GPU")
 	}
 
-	fmt.Println()
+	printLine()
 
 	// 显示构建标签字符串
-	fmt.Println("🏷️  构建标签:")
-	fmt.Printf("完整标签: %s\n", buildTag.String())
-	fmt.Printf("目录名:   %s\n", buildTag.ToDirName())
+	printLine("🏷️  构建标签:")
+	printf("完整标签: %s\n", buildTag.String())
+	printf("目录名:   %s\n", buildTag.ToDirName())
 
-	fmt.Println()
+	printLine()
 
 	// 显示使用示例
-	fmt.Println("💡 使用示例:")
-	fmt.Printf("命令行: ./buildfly install --build-tag \"%s\"\n", buildTag.String())
-	fmt.Printf("环境变量: export BUILDFLY_BUILD_TAG=\"%s\"\n", buildTag.String())
+	printLine("💡 使用示例:")
+	printf("命令行: ./buildfly install --build-tag \"%s\"\n", buildTag.String())
+	printf("环境变量: export BUILDFLY_BUILD_TAG=\"%s\"\n", buildTag.String())
 
-	fmt.Println()
+	printLine()
 
 	// 显示配置文件示例
-	fmt.Println("📄 配置文件示例:")
-	fmt.Println("project:")
-	fmt.Println("  name: \"my-project\"")
-	fmt.Println("  build_tag:")
-	fmt.Printf("    arch: \"%s\"\n", buildTag.Arch)
-	fmt.Printf("    platform: \"%s\"\n", buildTag.Platform)
-	fmt.Printf("    runtime: \"%s\"\n", buildTag.Runtime)
-	fmt.Printf("    compiler: \"%s\"\n", buildTag.Compiler)
-	fmt.Printf("    std: \"%s\"\n", buildTag.Std)
-	fmt.Printf("    abi: \"%s\"\n", buildTag.ABI)
+	printLine("📄 配置文件示例:")
+	printLine("project:")
+	printLine("  name: \"my-project\"")
+	printLine("  build_tag:")
+	printf("    arch: \"%s\"\n", buildTag.Arch)
+	printf("    platform: \"%s\"\n", buildTag.Platform)
+	printf("    runtime: \"%s\"\n", buildTag.Runtime)
+	printf("    compiler: \"%s\"\n", buildTag.Compiler)
+	printf("    std: \"%s\"\n", buildTag.Std)
+	printf("    abi: \"%s\"\n", buildTag.ABI)
 	if buildTag.Target != "" {
-		fmt.Printf("    target: \"%s\"\n", buildTag.Target)
+		printf("    target: \"%s\"\n", buildTag.Target)
+	}
+	// 可选字段默认不参与构建标签，需要按 CPU 指令集或 libstdc++ ABI 区分产物时取消注释
+	if buildTag.ISA != "" {
+		printf("    # isa: \"%s\"\n", buildTag.ISA)
+	}
+	if buildTag.Libstdcxx != "" {
+		printf("    # libstdcxx: \"%s\"\n", buildTag.Libstdcxx)
+	}
+	if buildTag.CXX11ABI != "" {
+		printf("    # cxx11_abi: \"%s\"\n", buildTag.CXX11ABI)
 	}
 
 	if buildTag.GPU != nil {
-		fmt.Println("    gpu:")
-		fmt.Printf("      backend: \"%s\"\n", buildTag.GPU.Backend)
+		printLine("    gpu:")
+		printf("      backend: \"%s\"\n", buildTag.GPU.Backend)
 		switch buildTag.GPU.Backend {
 		case "cuda":
 			if buildTag.GPU.CUDA != nil {
-				fmt.Printf("      cuda:\n")
-				fmt.Printf("        version: \"%s\"\n", buildTag.GPU.CUDA.Version)
-				fmt.Printf("        enabled: %t\n", buildTag.GPU.CUDA.Enabled)
+				printf("      cuda:\n")
+				printf("        version: \"%s\"\n", buildTag.GPU.CUDA.Version)
+				printf("        enabled: %t\n", buildTag.GPU.CUDA.Enabled)
 				if len(buildTag.GPU.CUDA.Arch) > 0 {
-					fmt.Printf("        arch: [%q]\n", buildTag.GPU.CUDA.Arch)
+					printf("        arch: [%q]\n", buildTag.GPU.CUDA.Arch)
 				}
 			}
 		case "rocm":
 			if buildTag.GPU.ROCm != nil {
-				fmt.Printf("      rocm:\n")
-				fmt.Printf("        version: \"%s\"\n", buildTag.GPU.ROCm.Version)
-				fmt.Printf("        enabled: %t\n", buildTag.GPU.ROCm.Enabled)
+				printf("      rocm:\n")
+				printf("        version: \"%s\"\n", buildTag.GPU.ROCm.Version)
+				printf("        enabled: %t\n", buildTag.GPU.ROCm.Enabled)
 				if len(buildTag.GPU.ROCm.Arch) > 0 {
-					fmt.Printf("        arch: [%q]\n", buildTag.GPU.ROCm.Arch)
+					printf("        arch: [%q]\n", buildTag.GPU.ROCm.Arch)
 				}
 			}
 		case "opencl":
 			if buildTag.GPU.OpenCL != nil {
-				fmt.Printf("      opencl:\n")
-				fmt.Printf("        version: \"%s\"\n", buildTag.GPU.OpenCL.Version)
-				fmt.Printf("        enabled: %t\n", buildTag.GPU.OpenCL.Enabled)
+				printf("      opencl:\n")
+				printf("        version: \"%s\"\n", buildTag.GPU.OpenCL.Version)
+				printf("        enabled: %t\n", buildTag.GPU.OpenCL.Enabled)
 			}
 		}
 	}
 
 	// 检查环境变量
-	fmt.Println()
-	fmt.Println("🌍 环境变量检查:")
+	printLine()
+	printLine("🌍 环境变量检查:")
 	if envTag := os.Getenv("BUILDFLY_BUILD_TAG"); envTag != "" {
-		fmt.Printf("BUILDFLY_BUILD_TAG: %s\n", envTag)
+		printf("BUILDFLY_BUILD_TAG: %s\n", envTag)
 	} else {
-		fmt.Println("BUILDFLY_BUILD_TAG: 未设置")
+		printLine("BUILDFLY_BUILD_TAG: 未设置")
 	}
 
 	return nil
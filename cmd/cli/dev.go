@@ -0,0 +1,208 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"buildfly/internal/errors"
+	"buildfly/pkg/utils"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cobra"
+)
+
+// newDevCmd 创建 dev 命令
+func newDevCmd() *cobra.Command {
+	var (
+		buildTag string
+		debounce time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "dev <dependency>",
+		Short: "监视本地路径依赖，修改后自动重新构建",
+		Long: `监视本地路径依赖（source.type: path）的源码树，文件变化后增量构建、
+安装并重新链接到项目，只处理这一个依赖。
+
+连续的修改会合并为一次构建（--debounce），构建失败时输出错误并继续监视。
+按 Ctrl+C 退出。`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDev(args[0], buildTag, debounce)
+		},
+	}
+
+	cmd.Flags().StringVar(&buildTag, "build-tag", "", "构建标签 (例如: arch=x86_64,platform=linux,runtime=glibc_2.35)")
+	cmd.Flags().DurationVar(&debounce, "debounce", 300*time.Millisecond, "最后一次修改后等待多久开始构建")
+
+	return cmd
+}
+
+// runDev 构建本地路径依赖并在源码变化时重新构建
+func runDev(depName, buildTag string, debounce time.Duration) error {
+	if err := GlobalCLIContext.Initialize(); err != nil {
+		return fmt.Errorf("failed to initialize context: %w", err)
+	}
+
+	projectConfig := GlobalCLIContext.ProjectConfig
+	dep, exists := projectConfig.Dependencies[depName]
+	if !exists {
+		return errors.ConfigError(fmt.Sprintf("dependency not found: %s", depName))
+	}
+	if !dep.Source.IsLocalPath() {
+		return errors.ConfigError(fmt.Sprintf("dependency %s is not a local path dependency", depName)).
+			WithHint("set `source.type: path` and `source.path` to a local checkout to use buildfly dev")
+	}
+
+	parsedBuildTag, err := resolveBuildTag(buildTag)
+	if err != nil {
+		return err
+	}
+	projectConfig.BuildTag = parsedBuildTag
+
+	// 项目目录位于依赖源码树中时，不监视构建输出
+	watcher, err := newDevWatcher(dep.Source.Path, []string{projectConfig.BuildFlyBaseDir, projectConfig.BuildDir}, debounce)
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	rebuild := func() {
+		start := time.Now()
+		printf("Building %s (%s)...\n", dep.Name, dep.Version)
+		if _, err := installLocalDependency(dep, false, false); err != nil {
+			eprintf("✗ Build of %s failed: %s\n", dep.Name, err)
+		} else {
+			printf("✓ %s is up to date (%s)\n", dep.Name, time.Since(start).Round(time.Millisecond))
+		}
+		printf("Watching %s for changes (Ctrl+C to stop)\n", dep.Source.Path)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	rebuild()
+	return watcher.Run(ctx, rebuild)
+}
+
+// devWatcher 监视源码树，文件变化后防抖调用重新构建
+type devWatcher struct {
+	watcher  *fsnotify.Watcher
+	ignore   []string // 不监视的目录
+	debounce time.Duration
+}
+
+// newDevWatcher 创建监视 root 下所有目录的监视器，跳过版本控制目录和 ignore 中的目录
+func newDevWatcher(root string, ignore []string, debounce time.Duration) (*devWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, errors.SystemErrorWithCause(err, "failed to create file watcher")
+	}
+
+	w := &devWatcher{watcher: watcher, debounce: debounce}
+	for _, dir := range ignore {
+		if dir == "" {
+			continue
+		}
+		if abs, err := filepath.Abs(dir); err == nil {
+			w.ignore = append(w.ignore, abs)
+		}
+	}
+
+	if err := w.addTree(root); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Close 停止监视
+func (w *devWatcher) Close() error {
+	return w.watcher.Close()
+}
+
+// addTree 监视 dir 及其所有子目录（inotify 不支持递归监视）
+func (w *devWatcher) addTree(dir string) error {
+	return filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			// 遍历期间被删除的目录忽略
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if path != dir && (utils.IsVCSDir(entry.Name()) || w.ignored(path)) {
+			return filepath.SkipDir
+		}
+		if err := w.watcher.Add(path); err != nil {
+			return errors.SystemErrorWithCause(err, fmt.Sprintf("failed to watch %s", path))
+		}
+		return nil
+	})
+}
+
+// ignored 检查路径是否位于不监视的目录中或版本控制目录中
+func (w *devWatcher) ignored(path string) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	for _, dir := range w.ignore {
+		if abs == dir || strings.HasPrefix(abs, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	for _, part := range strings.Split(filepath.ToSlash(abs), "/") {
+		if utils.IsVCSDir(part) {
+			return true
+		}
+	}
+	return false
+}
+
+// Run 处理文件变化事件直到 ctx 结束：最后一次变化后等待 debounce 再调用 rebuild，
+// 构建期间发生的变化会在构建结束后触发下一次构建
+func (w *devWatcher) Run(ctx context.Context, rebuild func()) error {
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op == fsnotify.Chmod || w.ignored(event.Name) {
+				continue
+			}
+			// 新建的目录需要单独监视
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := w.addTree(event.Name); err != nil {
+						GlobalCLIContext.Logger.Warn("Failed to watch new directory", "path", event.Name, "error", err)
+					}
+				}
+			}
+			timer.Reset(w.debounce)
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return nil
+			}
+			GlobalCLIContext.Logger.Warn("File watcher error", "error", err)
+		case <-timer.C:
+			rebuild()
+		}
+	}
+}
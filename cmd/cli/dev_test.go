@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDevWatcher(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"src", ".git", ".buildfly"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	watcher, err := newDevWatcher(root, []string{filepath.Join(root, ".buildfly")}, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("newDevWatcher() error = %v", err)
+	}
+	defer watcher.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	builds := make(chan struct{}, 10)
+	go watcher.Run(ctx, func() { builds <- struct{}{} })
+
+	write := func(path string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(path), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectBuilds := func(want int) {
+		t.Helper()
+		got := 0
+		timeout := time.After(500 * time.Millisecond)
+		for {
+			select {
+			case <-builds:
+				got++
+			case <-timeout:
+				if got != want {
+					t.Errorf("rebuilds = %d, want %d", got, want)
+				}
+				return
+			}
+		}
+	}
+
+	// 连续的修改合并为一次构建
+	write(filepath.Join(root, "src", "a.c"))
+	write(filepath.Join(root, "src", "b.c"))
+	write(filepath.Join(root, "CMakeLists.txt"))
+	expectBuilds(1)
+
+	// 版本控制目录和构建输出的变化不触发构建
+	write(filepath.Join(root, ".git", "index"))
+	write(filepath.Join(root, ".buildfly", "build", "out.o"))
+	expectBuilds(0)
+
+	// 新建目录中的文件也被监视
+	write(filepath.Join(root, "src", "new", "c.c"))
+	expectBuilds(1)
+	write(filepath.Join(root, "src", "new", "d.c"))
+	expectBuilds(1)
+}
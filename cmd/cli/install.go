@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"buildfly/internal/errors"
+	"buildfly/internal/logging"
 	"buildfly/pkg/builder"
 	"buildfly/pkg/cache"
 	"buildfly/pkg/config"
@@ -19,10 +22,17 @@ import (
 // newInstallCmd 创建 install 命令
 func newInstallCmd() *cobra.Command {
 	var (
-		force    bool
-		noCache  bool
-		profile  string
-		buildTag string
+		force      bool
+		noCache    bool
+		runTests   bool
+		profile    string
+		buildTag   string
+		matrix     bool
+		jobs       int
+		resultFile string
+		noLink     bool
+		timings    bool
+		timingsDir string
 	)
 
 	cmd := &cobra.Command{
@@ -34,22 +44,45 @@ func newInstallCmd() *cobra.Command {
 支持指定构建配置文件来安装特定的依赖集合。
 
 支持构建标签来区分不同的构建配置，例如：
---build-tag "arch=x86_64,platform=linux,runtime=glibc_2.35,compiler=gcc_11,std=cpp17"`,
+--build-tag "arch=x86_64,platform=linux,runtime=glibc_2.35,compiler=gcc_11,std=cpp17"
+
+使用 --matrix 按构建配置文件中的 matrix 构建所有组合，例如：
+buildfly install --profile ci --matrix --jobs 4
+
+使用 --timings 统计每个依赖下载、解压、配置、构建、安装、链接和缓存读写的耗时，
+打印汇总表格并写出 .buildfly/timings.json 和 .buildfly/timings.html；
+构建矩阵的每个变体的报告写在 .buildfly/logs/matrix/<变体>/ 中`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runInstall(args, force, noCache, profile, buildTag)
+			if matrix {
+				return runMatrixInstall(args, force, noCache, runTests, timings, profile, buildTag, jobs)
+			}
+			GlobalCLIContext.NoLink = noLink
+			return runInstall(args, force, noCache, runTests, timings, profile, buildTag, resultFile, timingsDir)
 		},
 	}
 
 	cmd.Flags().BoolVar(&force, "force", false, "强制重新安装")
 	cmd.Flags().BoolVar(&noCache, "no-cache", false, "不使用缓存")
+	cmd.Flags().BoolVar(&runTests, "test", false, "构建完成后运行依赖的测试")
 	cmd.Flags().StringVarP(&profile, "profile", "p", "", "构建配置文件")
 	cmd.Flags().StringVar(&buildTag, "build-tag", "", "构建标签 (例如: arch=x86_64,platform=linux,runtime=glibc_2.35)")
+	cmd.Flags().BoolVar(&matrix, "matrix", false, "按构建配置文件中的 matrix 构建所有组合")
+	cmd.Flags().IntVarP(&jobs, "jobs", "j", 2, "构建矩阵中并行构建的变体数")
+	cmd.Flags().BoolVar(&timings, "timings", false, "统计各阶段耗时，打印表格并写出 JSON/HTML 报告")
+
+	// 构建矩阵内部使用的参数
+	cmd.Flags().StringVar(&resultFile, "result-file", "", "将每个依赖的安装结果写入 JSON 文件")
+	cmd.Flags().BoolVar(&noLink, "no-link", false, "不链接到项目目录")
+	cmd.Flags().StringVar(&timingsDir, "timings-dir", "", "耗时报告的输出目录，默认为 .buildfly")
+	cmd.Flags().MarkHidden("result-file")
+	cmd.Flags().MarkHidden("no-link")
+	cmd.Flags().MarkHidden("timings-dir")
 
 	return cmd
 }
 
 // runInstall 执行安装
-func runInstall(deps []string, force, noCache bool, profile, buildTag string) error {
+func runInstall(deps []string, force, noCache, runTests, timings bool, profile, buildTag, resultFile, timingsDir string) error {
 	// 确保上下文已初始化
 	if err := GlobalCLIContext.Initialize(); err != nil {
 		return fmt.Errorf("failed to initialize context: %w", err)
@@ -58,74 +91,191 @@ func runInstall(deps []string, force, noCache bool, profile, buildTag string) er
 	projectConfig := GlobalCLIContext.ProjectConfig
 
 	// 解析构建标签
+	parsedBuildTag, err := resolveBuildTag(buildTag)
+	if err != nil {
+		return err
+	}
+
+	// 更新项目配置的构建标签（用于后续的构建过程）
+	// project build tag
+	projectConfig.BuildTag = parsedBuildTag
+
+	// 确定要安装的依赖
+	dependenciesToInstall, err := resolveDependencies(deps, profile)
+	if err != nil {
+		return err
+	}
+
+	if len(dependenciesToInstall) == 0 {
+		printLine("No dependencies to install")
+		return nil
+	}
+
+	// 使用上下文中的管理器
+	cacheManager := GlobalCLIContext.CacheManager
+	downloadManager := GlobalCLIContext.DownloadManager
+
+	// 记录安装前的编译器缓存统计，安装结束后报告本次新增的命中情况。
+	// 不清零统计：统计属于用户的全局缓存，并行的矩阵变体也在同时使用
+	compilerCache := GlobalCLIContext.CompilerCache
+	var compilerCacheBaseline *builder.CompilerCacheStats
+	if compilerCache != nil {
+		if compilerCacheBaseline, err = compilerCache.Stats(); err != nil {
+			GlobalCLIContext.Logger.Warn("Failed to read compiler cache stats", "tool", compilerCache.Tool, "error", err)
+		}
+	}
+
+	// 记录各阶段耗时
+	activeTimings = newInstallTimings()
+	defer func() { activeTimings = nil }()
+
+	// 安装每个依赖
+	results := make([]installResult, 0, len(dependenciesToInstall))
+	for i, dep := range dependenciesToInstall {
+		start := time.Now()
+		activeTimings.begin(dep)
+		source, err := installDependency(dep, cacheManager, downloadManager, force, noCache, runTests)
+		result := installResult{Dependency: dep.Name, Version: dep.Version, Source: source, DurationMS: time.Since(start).Milliseconds()}
+		if err != nil {
+			result.Status = installStatusFailed
+			result.Error = logging.Redact(err.Error())
+			activeTimings.finish(result)
+			results = append(results, result)
+			for _, skipped := range dependenciesToInstall[i+1:] {
+				results = append(results, installResult{Dependency: skipped.Name, Version: skipped.Version, Status: installStatusSkipped})
+			}
+			writeInstallResults(resultFile, results)
+			resolution := reportTimings(timings, timingsDir, dependenciesToInstall, parsedBuildTag)
+			setPartialOutput(newInstallOutput(parsedBuildTag, results, resolution))
+			return err
+		}
+		result.Status = installStatusInstalled
+		activeTimings.finish(result)
+		results = append(results, result)
+	}
+	writeInstallResults(resultFile, results)
+
+	printf("\nSuccessfully installed %d dependencies\n", len(dependenciesToInstall))
+	printCompilerCacheStats(compilerCache, compilerCacheBaseline)
+	resolution := reportTimings(timings, timingsDir, dependenciesToInstall, parsedBuildTag)
+	return writeInstallOutput(newInstallOutput(parsedBuildTag, results, resolution))
+}
+
+// reportTimings 汇总下载大小和构建时间，启用 --timings 时打印耗时表格并将报告写到 dir（默认为 .buildfly），
+// 未启用时只在调试日志中记录
+func reportTimings(enabled bool, dir string, deps []config.Dependency, buildTag *config.BuildTag) *config.ResolutionResult {
+	resolution := activeTimings.resolution(deps, buildTag)
+	if !enabled {
+		GlobalCLIContext.Logger.Debug("Install summary", "downloaded", formatBytes(resolution.DownloadSize), "build_time", formatPhaseDuration(resolution.BuildTime))
+		return resolution
+	}
+
+	report := activeTimings.report(resolution, buildTag)
+	printTimings(report)
+	if dir == "" {
+		dir = GlobalCLIContext.ProjectConfig.BuildFlyBaseDir
+	}
+	path, err := writeTimingsReport(dir, report)
+	if err != nil {
+		GlobalCLIContext.Logger.Warn("Failed to write timings report", "error", err)
+		return resolution
+	}
+	printf("Timings report written to %s\n", path)
+	return resolution
+}
+
+// installOutput install 命令的结构化输出
+type installOutput struct {
+	BuildTag     string          `json:"build_tag"`
+	BuildTimeMS  int64           `json:"build_time_ms"`
+	DownloadSize int64           `json:"download_size"`
+	Results      []installResult `json:"results"`
+}
+
+// newInstallOutput 创建安装命令的结构化输出
+func newInstallOutput(buildTag *config.BuildTag, results []installResult, resolution *config.ResolutionResult) installOutput {
+	return installOutput{
+		BuildTag:     buildTag.String(),
+		BuildTimeMS:  resolution.BuildTime.Milliseconds(),
+		DownloadSize: resolution.DownloadSize,
+		Results:      results,
+	}
+}
+
+// writeInstallOutput 以结构化格式输出安装结果，文本输出时不做任何事
+func writeInstallOutput(output installOutput) error {
+	if !isStructuredOutput() {
+		return nil
+	}
+	return writeOutput("install", output)
+}
+
+// printCompilerCacheStats 打印相对于安装前统计 baseline 新增的编译器缓存命中统计
+func printCompilerCacheStats(compilerCache *builder.CompilerCache, baseline *builder.CompilerCacheStats) {
+	if compilerCache == nil || baseline == nil {
+		return
+	}
+
+	current, err := compilerCache.Stats()
+	if err != nil {
+		GlobalCLIContext.Logger.Warn("Failed to read compiler cache stats", "tool", compilerCache.Tool, "error", err)
+		return
+	}
+	stats := current.Since(baseline)
+
+	printf("Compiler cache (%s): %d hits, %d misses (%.1f%% hit rate)\n",
+		compilerCache.Tool, stats.Hits, stats.Misses, stats.HitRate())
+}
+
+// resolveBuildTag 解析构建标签
+// 优先级：命令行 > 环境变量 > 配置文件 > 自动检测
+func resolveBuildTag(buildTag string) (*config.BuildTag, error) {
+	projectConfig := GlobalCLIContext.ProjectConfig
+
 	var parsedBuildTag *config.BuildTag
 	var err error
 	if buildTag != "" {
 		// 使用命令行指定的构建标签
-		parsedBuildTag, err = config.ParseBuildTag(buildTag)
+		parsedBuildTag, err = projectConfig.BuildTagDimensions.ParseBuildTag(buildTag)
 		if err != nil {
-			return fmt.Errorf("invalid build tag: %w", err)
+			return nil, fmt.Errorf("invalid build tag: %w", err)
 		}
-		if err := parsedBuildTag.Validate(); err != nil {
-			return fmt.Errorf("invalid build tag: %w", err)
+		if err := projectConfig.BuildTagDimensions.ValidateBuildTag(parsedBuildTag); err != nil {
+			return nil, fmt.Errorf("invalid build tag: %w", err)
 		}
-		fmt.Printf("Using build tag from command line: %s\n", parsedBuildTag.String())
+		printf("Using build tag from command line: %s\n", parsedBuildTag.String())
 	} else {
 		// 尝试从环境变量获取
-		if envBuildTag, err := config.GetBuildTagFromEnv(); err == nil && envBuildTag != nil {
+		if envBuildTag, err := config.GetBuildTagFromEnv(projectConfig.BuildTagDimensions); err == nil && envBuildTag != nil {
 			parsedBuildTag = envBuildTag
-			fmt.Printf("Using build tag from environment: %s\n", parsedBuildTag.String())
+			printf("Using build tag from environment: %s\n", parsedBuildTag.String())
 		} else if projectConfig.BuildTag != nil {
 			// 使用配置文件中的构建标签作为基础
 			parsedBuildTag = projectConfig.BuildTag
-			fmt.Printf("Using build tag from config: %s\n", parsedBuildTag.String())
+			printf("Using build tag from config: %s\n", parsedBuildTag.String())
 		} else {
 			// 自动检测构建标签
-			fmt.Printf("No build tag specified, auto-detecting...\n")
+			printf("No build tag specified, auto-detecting...\n")
 			parsedBuildTag, err = config.GetDefaultBuildTag(projectConfig.BuildTag)
 			if err != nil {
-				fmt.Printf("Warning: Failed to auto-detect build tag: %v\n", err)
-				fmt.Printf("Using minimal build tag...\n")
+				GlobalCLIContext.Logger.Warn("Failed to auto-detect build tag", "error", err)
+				printf("Using minimal build tag...\n")
 				parsedBuildTag = &config.BuildTag{}
 			} else {
-				fmt.Printf("Auto-detected build tag: %s\n", parsedBuildTag.String())
+				printf("Auto-detected build tag: %s\n", parsedBuildTag.String())
 			}
 		}
 	}
 
-	// 验证最终的构建标签
-	if err := parsedBuildTag.Validate(); err != nil {
-		return fmt.Errorf("invalid build tag: %w", err)
-	}
-
-	// 更新项目配置的构建标签（用于后续的构建过程）
-	// project build tag
-	projectConfig.BuildTag = parsedBuildTag
-
-	// 确定要安装的依赖
-	dependenciesToInstall, err := resolveDependencies(deps, profile)
-	if err != nil {
-		return err
-	}
-
-	if len(dependenciesToInstall) == 0 {
-		fmt.Println("No dependencies to install")
-		return nil
-	}
+	// 未指定的自定义维度使用配置中的默认值
+	projectConfig.BuildTagDimensions.ApplyDefaults(parsedBuildTag)
 
-	// 使用上下文中的管理器
-	cacheManager := GlobalCLIContext.CacheManager
-	downloadManager := GlobalCLIContext.DownloadManager
-
-	// 安装每个依赖
-	for _, dep := range dependenciesToInstall {
-		if err := installDependency(dep, cacheManager, downloadManager, force, noCache); err != nil {
-			return err
-		}
+	// 验证最终的构建标签
+	if err := projectConfig.BuildTagDimensions.ValidateBuildTag(parsedBuildTag); err != nil {
+		return nil, fmt.Errorf("invalid build tag: %w", err)
 	}
 
-	fmt.Printf("\nSuccessfully installed %d dependencies\n", len(dependenciesToInstall))
-	return nil
+	return parsedBuildTag, nil
 }
 
 // resolveDependencies 解析要安装的依赖
@@ -139,7 +289,7 @@ func resolveDependencies(deps []string, profile string) ([]config.Dependency, er
 			if dep, exists := projectConfig.Dependencies[depName]; exists {
 				dependenciesToInstall = append(dependenciesToInstall, dep)
 			} else {
-				return nil, fmt.Errorf("dependency not found: %s", depName)
+				return nil, errors.DependencyError(fmt.Sprintf("dependency not found: %s", depName))
 			}
 		}
 	} else if profile != "" {
@@ -151,7 +301,8 @@ func resolveDependencies(deps []string, profile string) ([]config.Dependency, er
 				}
 			}
 		} else {
-			return nil, fmt.Errorf("build profile not found: %s", profile)
+			return nil, errors.ConfigError(fmt.Sprintf("build profile not found: %s", profile)).
+				WithHint("check build_profiles in buildfly.yaml")
 		}
 	} else {
 		// 安装所有依赖
@@ -163,45 +314,65 @@ func resolveDependencies(deps []string, profile string) ([]config.Dependency, er
 	return dependenciesToInstall, nil
 }
 
-// installDependency 安装单个依赖
-func installDependency(dep config.Dependency, cacheManager *cache.CacheManager, downloadManager *downloader.DownloadManager, force, noCache bool) error {
-	fmt.Printf("Installing %s (%s)...\n", dep.Name, dep.Version)
+// installDependency 安装单个依赖，返回安装来源（cache、built、download）
+func installDependency(dep config.Dependency, cacheManager *cache.CacheManager, downloadManager *downloader.DownloadManager, force, noCache, runTests bool) (string, error) {
+	printf("Installing %s (%s)...\n", dep.Name, dep.Version)
+
+	// 本地路径源码就地构建，不下载也不使用缓存
+	if dep.Source.IsLocalPath() {
+		return installLocalDependency(dep, force, runTests)
+	}
 
 	// 尝试从缓存安装
 	if !force && !noCache {
-		if installed := tryInstallFromCache(dep, cacheManager, noCache); installed {
-			return nil
+		source, installed, err := tryInstallFromCache(dep, cacheManager, noCache, runTests)
+		if err != nil {
+			return "", err
+		}
+		if installed {
+			return source, nil
 		}
 	}
 
 	// 下载并安装
-	return downloadAndInstall(dep, cacheManager, downloadManager, noCache)
+	if err := downloadAndInstall(dep, cacheManager, downloadManager, noCache, runTests); err != nil {
+		return "", err
+	}
+	if dep.BuildSystem != "" && dep.BuildSystem != "none" {
+		return installSourceBuilt, nil
+	}
+	return installSourceDownload, nil
 }
 
 // tryInstallFromCache 尝试从缓存安装依赖
-func tryInstallFromCache(dep config.Dependency, cacheManager *cache.CacheManager, noCache bool) bool {
+// 下载缓存必须与锁文件中记录的校验和一致，不能确认时返回 false，重新下载并校验
+func tryInstallFromCache(dep config.Dependency, cacheManager *cache.CacheManager, noCache, runTests bool) (string, bool, error) {
 	if !cacheManager.IsCachedDownloads(dep) {
-		return false
+		return "", false, nil
+	}
+	if ok, err := useCachedDownload(dep, cacheManager); err != nil || !ok {
+		return "", false, err
 	}
 
-	fmt.Printf("  Using cached download\n")
+	printf("  Using cached download\n")
 
 	// 检查是否需要构建
 	if dep.BuildSystem != "" && dep.BuildSystem != "none" {
-		return tryBuildFromCache(dep, cacheManager, noCache)
+		source, installed := tryBuildFromCache(dep, cacheManager, noCache, runTests)
+		return source, installed, nil
 	} else {
 		// 不需要构建的依赖，直接使用下载缓存并链接到项目
-		if err := linkToProjectDir(dep); err != nil {
-			fmt.Printf("  Failed to link from cache: %v\n", err)
-			return false
+		if err := linkDependency(dep); err != nil {
+			printf("  Failed to link from cache: %v\n", err)
+			return "", false, nil
 		}
-		fmt.Printf("  ✓ Installed from cache %s\n", dep.Name)
-		return true
+		printf("  ✓ Installed from cache %s\n", dep.Name)
+		return installSourceCache, true, nil
 	}
 }
 
-// tryBuildFromCache 尝试从缓存构建依赖
-func tryBuildFromCache(dep config.Dependency, cacheManager *cache.CacheManager, noCache bool) bool {
+// tryBuildFromCache 尝试从缓存构建依赖，返回安装来源
+func tryBuildFromCache(dep config.Dependency, cacheManager *cache.CacheManager, noCache, runTests bool) (string, bool) {
 	projectConfig := GlobalCLIContext.ProjectConfig
 	currentBuildTag := projectConfig.BuildTag
 
@@ -211,56 +382,63 @@ func tryBuildFromCache(dep config.Dependency, cacheManager *cache.CacheManager,
 
 	// 检查构建缓存
 	if cacheManager.IsBuildCached(dep, currentBuildTag) {
-		fmt.Printf("  Using cached build\n")
+		printf("  Using cached build\n")
 
 		// 确保安装目录存在
 		if err := os.MkdirAll(depInstallDir, 0755); err != nil {
-			fmt.Printf("  Failed to create install dir: %v\n", err)
-			return false
+			printf("  Failed to create install dir: %v\n", err)
+			return "", false
 		}
 
-		if err := cacheManager.RetrieveBuild(dep, depInstallDir, currentBuildTag); err != nil {
-			fmt.Printf("  Failed to retrieve build from cache: %v\n", err)
+		if err := activeTimings.measure(phaseCacheRestore, func() error {
+			return cacheManager.RetrieveBuild(dep, depInstallDir, currentBuildTag)
+		}); err != nil {
+			printf("  Failed to retrieve build from cache: %v\n", err)
 		} else {
+			// 缓存的构建只包含安装结果，没有可运行测试的构建目录
+			if runTests {
+				printf("  - Tests skipped for %s: build restored from cache (use --no-cache to build and test)\n", dep.Name)
+			}
+
 			// 链接到项目目录
-			if err := linkToProjectDir(dep); err != nil {
-				fmt.Printf("  Failed to link to project: %v\n", err)
-				return false
+			if err := linkDependency(dep); err != nil {
+				printf("  Failed to link to project: %v\n", err)
+				return "", false
 			}
-			return true
+			return installSourceCache, true
 		}
 	}
 
-	fmt.Printf("  Build cache not found, will build from download cache\n")
+	printf("  Build cache not found, will build from download cache\n")
 
 	// 从下载缓存恢复源码，然后构建
 	if err := os.MkdirAll(depBuildDir, 0755); err != nil {
-		fmt.Printf("  Failed to create build dir: %v\n", err)
-		return false
+		printf("  Failed to create build dir: %v\n", err)
+		return "", false
 	}
 
-	if err := cacheManager.Retrieve(dep, depBuildDir); err != nil {
-		fmt.Printf("  Failed to retrieve from cache: %v\n", err)
-		return false
+	if err := activeTimings.measure(phaseCacheRestore, func() error { return cacheManager.Retrieve(dep, depBuildDir) }); err != nil {
+		printf("  Failed to retrieve from cache: %v\n", err)
+		return "", false
 	}
 
 	// 执行构建
-	if err := compileInBuildDir(dep, depBuildDir, depInstallDir, noCache); err != nil {
-		fmt.Printf("  Failed to build from cache: %v\n", err)
-		return false
+	if err := compileInBuildDir(dep, depBuildDir, depBuildDir, depInstallDir, noCache, runTests); err != nil {
+		printf("  Failed to build from cache: %v\n", err)
+		return "", false
 	}
 
 	// 链接到项目目录
-	if err := linkToProjectDir(dep); err != nil {
-		fmt.Printf("  Failed to link to project: %v\n", err)
-		return false
+	if err := linkDependency(dep); err != nil {
+		printf("  Failed to link to project: %v\n", err)
+		return "", false
 	}
 
-	return true
+	return installSourceBuilt, true
 }
 
 // downloadAndInstall 下载并安装依赖
-func downloadAndInstall(dep config.Dependency, cacheManager *cache.CacheManager, downloadManager *downloader.DownloadManager, noCache bool) error {
+func downloadAndInstall(dep config.Dependency, cacheManager *cache.CacheManager, downloadManager *downloader.DownloadManager, noCache, runTests bool) error {
 	// 步骤1: 下载压缩包（如果需要）
 	sourceDir, err := downloadArchivesIfNeeded(dep, cacheManager, downloadManager, noCache)
 	if err != nil {
@@ -280,13 +458,13 @@ func downloadAndInstall(dep config.Dependency, cacheManager *cache.CacheManager,
 	if dep.BuildSystem != "" && dep.BuildSystem != "none" {
 		// 步骤3: 在构建目录中编译
 		depInstallDir := getDepInstallDir(dep, currentBuildTag)
-		if err := compileInBuildDir(dep, depBuildDir, depInstallDir, noCache); err != nil {
+		if err := compileInBuildDir(dep, depBuildDir, depBuildDir, depInstallDir, noCache, runTests); err != nil {
 			return fmt.Errorf("failed to compile: %w", err)
 		}
 	}
 
 	// 步骤4: 链接到项目目录
-	if err := linkToProjectDir(dep); err != nil {
+	if err := linkDependency(dep); err != nil {
 		return fmt.Errorf("failed to link to project: %w", err)
 	}
 
@@ -295,7 +473,7 @@ func downloadAndInstall(dep config.Dependency, cacheManager *cache.CacheManager,
 
 // buildFromDownloadedSource 从下载的源码构建依赖
 func buildFromDownloadedSource(dep config.Dependency, tempDir string, cacheManager *cache.CacheManager, noCache bool) error {
-	fmt.Printf("  buildFromDownloadedSource with %s...\n", dep.BuildSystem)
+	printf("  buildFromDownloadedSource with %s...\n", dep.BuildSystem)
 
 	projectConfig := GlobalCLIContext.ProjectConfig
 	currentBuildTag := projectConfig.BuildTag
@@ -315,7 +493,7 @@ func buildFromDownloadedSource(dep config.Dependency, tempDir string, cacheManag
 	depInstallDir := getDepInstallDir(dep, currentBuildTag)
 
 	// 执行构建
-	if err := compileInBuildDir(dep, namedBuildDir, depInstallDir, noCache); err != nil {
+	if err := compileInBuildDir(dep, namedBuildDir, namedBuildDir, depInstallDir, noCache, false); err != nil {
 		return fmt.Errorf("failed to build %s: %w", dep.Name, err)
 	}
 
@@ -341,6 +519,11 @@ func buildDependency(dep config.Dependency, namedBuildDir string, noCache bool)
 		varCtx.SetBuildTag(currentBuildTag)
 	}
 
+	// 选择交叉编译工具链
+	if err := applyToolchain(varCtx, currentBuildTag); err != nil {
+		return err
+	}
+
 	// 设置基础路径
 	varCtx.InstallDir = getDepInstallDir(dep, currentBuildTag)
 	varCtx.BuildDir = getDepBuildDir(dep, currentBuildTag)
@@ -351,26 +534,39 @@ func buildDependency(dep config.Dependency, namedBuildDir string, noCache bool)
 	}
 
 	// 初始化构建执行器
-	executor := builder.NewBuildExecutor(varCtx)
+	executor := newBuildExecutor(varCtx)
 
 	// 执行构建
 	if err := executor.Execute(dep, namedBuildDir, varCtx.BuildDir, varCtx.InstallDir); err != nil {
 		return fmt.Errorf("failed to build %s: %w", dep.Name, err)
 	}
+	// 构建元数据描述共享缓存中的构建结果，不缓存时（--no-cache、本地路径依赖）不写入
+	if !noCache {
+		recordBuildEnvironment(executor, dep, currentBuildTag)
+		recordSourceSignature(dep, currentBuildTag)
+	}
+
+	// 校验构建产物的运行时要求
+	if err := verifyArtifacts(dep, varCtx.InstallDir, currentBuildTag, !noCache); err != nil {
+		return err
+	}
 
 	// 缓存构建结果
 	if !noCache {
-		fmt.Printf("  Caching build result...\n")
+		printf("  Caching build result...\n")
 		maxAge := GlobalCLIContext.parseMaxCacheAge()
 		cacheManager := cache.NewCacheManager(projectConfig.CacheDir, 1024*1024*1024, maxAge)
-		if err := cacheManager.StoreBuild(dep, varCtx.InstallDir, currentBuildTag); err != nil {
-			fmt.Printf("  Warning: failed to cache build %s: %v\n", dep.Name, err)
+		cacheManager.SetLogger(GlobalCLIContext.Logger)
+		if err := activeTimings.measure(phaseCacheStore, func() error {
+			return cacheManager.StoreBuild(dep, varCtx.InstallDir, currentBuildTag)
+		}); err != nil {
+			GlobalCLIContext.Logger.Warn("Failed to cache build", "dependency", dep.Name, "error", err)
 		} else {
-			fmt.Printf("  ✓ Cached build result in %s\n", cacheManager.GetBuildCachePath(dep, currentBuildTag))
+			printf("  ✓ Cached build result in %s\n", cacheManager.GetBuildCachePath(dep, currentBuildTag))
 		}
 	}
 
-	fmt.Printf("  ✓ Built and installed\n")
+	printf("  ✓ Built and installed\n")
 	return nil
 }
 
@@ -386,7 +582,7 @@ func installDirectly(dep config.Dependency, sourceDir, targetDir string) error {
 
 	// 直接复制到目标目录
 	// installPath := filepath.Join(targetDir, dep.Name)
-	// fmt.Printf("  Installing directly... %s -> %s \n", sourceDir, installPath)
+	// printf("  Installing directly... %s -> %s \n", sourceDir, installPath)
 	// if err := utils.CopyDir(sourceDir, installPath); err != nil {
 	// 	return fmt.Errorf("failed to copy %s: %w", dep.Name, err)
 	// }
@@ -415,7 +611,7 @@ func installDirectly(dep config.Dependency, sourceDir, targetDir string) error {
 			return fmt.Errorf("failed to create symlink for %s: %w", entry.Name(), err)
 		}
 		linkDirs = append(linkDirs, targetPath)
-		fmt.Printf("  Linked %s -> %s\n", sourcePath, targetPath)
+		printf("  Linked %s -> %s\n", sourcePath, targetPath)
 	}
 	// 记录安装信息 用于卸载
 	install_txt := filepath.Join(projectConfig.BuildFlyBaseDir, "install.txt")
@@ -430,7 +626,7 @@ func installDirectly(dep config.Dependency, sourceDir, targetDir string) error {
 		}
 	}
 
-	fmt.Printf("  ✓ Installed\n")
+	printf("  ✓ Installed\n")
 	return nil
 }
 
@@ -463,15 +659,23 @@ func getDepInstallDir(dep config.Dependency, buildTag *config.BuildTag) string {
 // downloadArchivesIfNeeded 下载压缩包（如果需要）
 func downloadArchivesIfNeeded(dep config.Dependency, cacheManager *cache.CacheManager, downloadManager *downloader.DownloadManager, noCache bool) (string, error) {
 	// 检查是否已有缓存
-	if !noCache && cacheManager.IsCachedDownloads(dep) {
-		fmt.Printf("  Using cached download\n")
+	useCache := !noCache && cacheManager.IsCachedDownloads(dep)
+	if useCache {
+		// 缓存的源码同样要与锁文件中记录的校验和一致，不能确认时重新下载并校验
+		var err error
+		if useCache, err = useCachedDownload(dep, cacheManager); err != nil {
+			return "", err
+		}
+	}
+	if useCache {
+		printf("  Using cached download\n")
 		// 创建临时目录来恢复缓存内容
 		tempDir, err := os.MkdirTemp("", "buildfly-cache-*")
 		if err != nil {
 			return "", fmt.Errorf("failed to create temp dir: %w", err)
 		}
 
-		if err := cacheManager.Retrieve(dep, tempDir); err != nil {
+		if err := activeTimings.measure(phaseCacheRestore, func() error { return cacheManager.Retrieve(dep, tempDir) }); err != nil {
 			os.RemoveAll(tempDir)
 			return "", fmt.Errorf("failed to retrieve from cache: %w", err)
 		}
@@ -488,22 +692,55 @@ func downloadArchivesIfNeeded(dep config.Dependency, cacheManager *cache.CacheMa
 	// 下载依赖
 	urls := dep.Source.GetURLs()
 	if len(urls) > 0 {
-		fmt.Printf("  Downloading from %s...\n", urls[0])
+		printf("  Downloading from %s...\n", urls[0])
 	} else {
-		fmt.Printf("  Downloading...\n")
+		printf("  Downloading...\n")
 	}
 
-	if err := downloadManager.Download(context.Background(), dep, tempDir); err != nil {
+	// 没有配置校验和的压缩包使用锁文件中记录的校验和
+	downloadDep, err := lockedDependency(dep)
+	if err != nil {
+		os.RemoveAll(tempDir)
+		return "", err
+	}
+
+	stats, err := downloadManager.DownloadWithStats(context.Background(), downloadDep, tempDir)
+	activeTimings.record(phaseDownload, stats.DownloadTime)
+	activeTimings.record(phaseExtract, stats.ExtractTime)
+	activeTimings.addBytes(stats.Bytes)
+	if err != nil {
 		os.RemoveAll(tempDir)
 		return "", fmt.Errorf("failed to download %s: %w", dep.Name, err)
 	}
+	if err := recordChecksum(dep, stats.SHA256); err != nil {
+		GlobalCLIContext.Logger.Warn("Failed to record checksum", "dependency", dep.Name, "error", err)
+	}
+	if stats.SHA256 != "" {
+		if err := cacheManager.RecordDownloadChecksum(dep, stats.SHA256); err != nil {
+			GlobalCLIContext.Logger.Warn("Failed to record checksum", "dependency", dep.Name, "error", err)
+		}
+	}
+	if signer := stats.Signer; signer != nil {
+		printf("  ✓ Verified %s signature by %s (%s)\n", signer.Type, signer.Key, signer.Fingerprint)
+		signature := &cache.SourceSignature{
+			Signature:   dep.Source.Signature,
+			Type:        signer.Type,
+			Key:         signer.Key,
+			Fingerprint: signer.Fingerprint,
+			Signer:      signer.Identity,
+			VerifiedAt:  time.Now(),
+		}
+		if err := cacheManager.RecordDownloadSignature(dep, signature); err != nil {
+			GlobalCLIContext.Logger.Warn("Failed to record signature", "dependency", dep.Name, "error", err)
+		}
+	}
 
 	// 缓存下载的源码（保留压缩包在本地 cache 目录）
 	if !noCache {
-		if err := cacheManager.Store(dep, tempDir); err != nil {
-			fmt.Printf("  Warning: failed to cache download %s: %v\n", dep.Name, err)
+		if err := activeTimings.measure(phaseCacheStore, func() error { return cacheManager.Store(dep, tempDir) }); err != nil {
+			GlobalCLIContext.Logger.Warn("Failed to cache download", "dependency", dep.Name, "error", err)
 		} else {
-			fmt.Printf("  ✓ Cached download source in %s\n", cacheManager.GetDownloadCachePath(dep))
+			printf("  ✓ Cached download source in %s\n", cacheManager.GetDownloadCachePath(dep))
 		}
 	}
 
@@ -525,13 +762,13 @@ func createBuildDirIfNeeded(dep config.Dependency, buildTag *config.BuildTag, so
 		return "", fmt.Errorf("failed to copy source to build dir: %w", err)
 	}
 
-	fmt.Printf("  Prepared build directory: %s\n", depBuildDir)
+	printf("  Prepared build directory: %s\n", depBuildDir)
 	return depBuildDir, nil
 }
 
-// compileInBuildDir 在构建目录中编译
-func compileInBuildDir(dep config.Dependency, buildDir, installDir string, noCache bool) error {
-	fmt.Printf("  Compiling %s with %s...\n", dep.Name, dep.BuildSystem)
+// compileInBuildDir 在构建目录中编译，下载的源码已复制到构建目录时 sourceDir 与 buildDir 相同
+func compileInBuildDir(dep config.Dependency, sourceDir, buildDir, installDir string, noCache, runTests bool) error {
+	printf("  Compiling %s with %s...\n", dep.Name, dep.BuildSystem)
 
 	projectConfig := GlobalCLIContext.ProjectConfig
 	currentBuildTag := projectConfig.BuildTag
@@ -544,6 +781,11 @@ func compileInBuildDir(dep config.Dependency, buildDir, installDir string, noCac
 		varCtx.SetBuildTag(currentBuildTag)
 	}
 
+	// 选择交叉编译工具链
+	if err := applyToolchain(varCtx, currentBuildTag); err != nil {
+		return err
+	}
+
 	// 设置基础路径
 	varCtx.BuildDir = buildDir
 	varCtx.InstallDir = installDir
@@ -554,31 +796,164 @@ func compileInBuildDir(dep config.Dependency, buildDir, installDir string, noCac
 	}
 
 	// 初始化构建执行器
-	executor := builder.NewBuildExecutor(varCtx)
+	executor := newBuildExecutor(varCtx)
 
 	// 执行构建
-	if err := executor.Execute(dep, buildDir, varCtx.BuildDir, varCtx.InstallDir); err != nil {
+	if err := executor.Execute(dep, sourceDir, varCtx.BuildDir, varCtx.InstallDir); err != nil {
 		return fmt.Errorf("failed to build %s: %w", dep.Name, err)
 	}
+	// 构建元数据描述共享缓存中的构建结果，不缓存时（--no-cache、本地路径依赖）不写入
+	if !noCache {
+		recordBuildEnvironment(executor, dep, currentBuildTag)
+		recordSourceSignature(dep, currentBuildTag)
+	}
+
+	// 校验构建产物的运行时要求
+	if err := verifyArtifacts(dep, varCtx.InstallDir, currentBuildTag, !noCache); err != nil {
+		return err
+	}
+
+	// 运行测试（测试失败时不缓存构建结果）
+	if runTests {
+		if err := runDependencyTests(executor, dep, sourceDir, buildDir, currentBuildTag); err != nil {
+			return err
+		}
+	}
 
 	// 缓存构建结果
 	if !noCache {
-		fmt.Printf("  Caching build result...\n")
+		printf("  Caching build result...\n")
 		maxAge := GlobalCLIContext.parseMaxCacheAge()
 		cacheManager := cache.NewCacheManager(projectConfig.CacheDir, 1024*1024*1024, maxAge)
-		if err := cacheManager.StoreBuild(dep, varCtx.InstallDir, currentBuildTag); err != nil {
-			fmt.Printf("  Warning: failed to cache build %s: %v\n", dep.Name, err)
+		cacheManager.SetLogger(GlobalCLIContext.Logger)
+		if err := activeTimings.measure(phaseCacheStore, func() error {
+			return cacheManager.StoreBuild(dep, varCtx.InstallDir, currentBuildTag)
+		}); err != nil {
+			GlobalCLIContext.Logger.Warn("Failed to cache build", "dependency", dep.Name, "error", err)
 		} else {
-			fmt.Printf("  ✓ Cached build result in %s\n", cacheManager.GetBuildCachePath(dep, currentBuildTag))
+			printf("  ✓ Cached build result in %s\n", cacheManager.GetBuildCachePath(dep, currentBuildTag))
 		}
 	}
 
-	fmt.Printf("  ✓ Compiled %s\n", dep.Name)
+	printf("  ✓ Compiled %s\n", dep.Name)
 	return nil
 }
 
+// newBuildExecutor 创建构建执行器，关联项目配置（虚拟环境、密封构建）和编译器缓存
+func newBuildExecutor(varCtx *config.VariableContext) *builder.BuildExecutor {
+	projectConfig := GlobalCLIContext.ProjectConfig
+	varCtx.ProjectConfig = projectConfig
+	varCtx.ProjectRoot = projectConfig.ProjectRoot
+
+	executor := builder.NewBuildExecutor(varCtx)
+	executor.SetCompilerCache(GlobalCLIContext.CompilerCache)
+	executor.SetLogger(GlobalCLIContext.Logger)
+	executor.SetPhaseRecorder(activeTimings.record)
+	return executor
+}
+
+// recordBuildEnvironment 将构建环境记录到构建元数据，密封构建时包含实际使用的环境变量（只保存值的哈希）
+func recordBuildEnvironment(executor *builder.BuildExecutor, dep config.Dependency, buildTag *config.BuildTag) {
+	var env map[string]string
+	hermetic := executor.Hermetic()
+	if hermetic {
+		env = executor.EffectiveEnv()
+	}
+
+	if err := GlobalCLIContext.CacheManager.RecordBuildEnvironment(dep, buildTag, hermetic, env); err != nil {
+		GlobalCLIContext.Logger.Warn("Failed to record build environment", "dependency", dep.Name, "error", err)
+	}
+}
+
+// recordSourceSignature 将下载时校验通过的源码签名记录到构建元数据
+func recordSourceSignature(dep config.Dependency, buildTag *config.BuildTag) {
+	cacheManager := GlobalCLIContext.CacheManager
+	if err := cacheManager.RecordSourceSignature(dep, buildTag, cacheManager.DownloadSignature(dep)); err != nil {
+		GlobalCLIContext.Logger.Warn("Failed to record source signature", "dependency", dep.Name, "error", err)
+	}
+}
+
+// verifyArtifacts 检查构建产物是否满足构建标签的 runtime/arch，record 为 true 时将结果记录到构建元数据
+func verifyArtifacts(dep config.Dependency, installDir string, buildTag *config.BuildTag, record bool) error {
+	mode := GlobalCLIContext.ProjectConfig.VerifyArtifacts
+	if mode == "" {
+		mode = builder.VerifyModeWarn
+	}
+	if mode == builder.VerifyModeOff {
+		return nil
+	}
+
+	req, err := builder.VerifyArtifacts(installDir, buildTag)
+	if err != nil {
+		GlobalCLIContext.Logger.Warn("Failed to verify artifacts", "dependency", dep.Name, "error", err)
+		return nil
+	}
+	if req.Files == 0 {
+		return nil
+	}
+
+	requirements := &cache.RuntimeRequirements{
+		Arch:       req.Arch,
+		GLIBC:      req.GLIBC,
+		GLIBCXX:    req.GLIBCXX,
+		Files:      req.Files,
+		Problems:   req.Problems,
+		VerifiedAt: time.Now(),
+	}
+	if record {
+		if err := GlobalCLIContext.CacheManager.RecordRequirements(dep, buildTag, requirements); err != nil {
+			GlobalCLIContext.Logger.Warn("Failed to record artifact requirements", "dependency", dep.Name, "error", err)
+		}
+	}
+
+	if len(req.Problems) == 0 {
+		printf("  ✓ Verified %d binaries (arch=%s, GLIBC_%s, GLIBCXX_%s)\n",
+			req.Files, req.Arch, valueOrDash(req.GLIBC), valueOrDash(req.GLIBCXX))
+		return nil
+	}
+
+	for _, problem := range req.Problems {
+		printf("  ✗ %s\n", problem)
+	}
+	if mode == builder.VerifyModeError {
+		return fmt.Errorf("artifacts of %s do not match build tag %s", dep.Name, buildTag.String())
+	}
+	GlobalCLIContext.Logger.Warn("Artifacts do not match build tag", "dependency", dep.Name, "build_tag", buildTag.String(), "problems", strings.Join(req.Problems, "; "))
+	return nil
+}
+
+// valueOrDash 空值显示为 "-"
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// applyToolchain 根据目标构建标签为变量上下文选择交叉编译工具链
+func applyToolchain(varCtx *config.VariableContext, buildTag *config.BuildTag) error {
+	toolchain, err := GlobalCLIContext.ProjectConfig.SelectToolchain(buildTag)
+	if err != nil {
+		return fmt.Errorf("failed to select toolchain: %w", err)
+	}
+	if toolchain != nil {
+		printf("  Cross-compiling with toolchain %s (%s)\n", toolchain.Name, toolchain.Triple)
+	}
+	varCtx.SetToolchain(toolchain)
+	return nil
+}
+
+// linkDependency 链接到项目目录并记录耗时
+func linkDependency(dep config.Dependency) error {
+	return activeTimings.measure(phaseLink, func() error { return linkToProjectDir(dep) })
+}
+
 // linkToProjectDir 链接到项目目录
 func linkToProjectDir(dep config.Dependency) error {
+	if GlobalCLIContext.NoLink {
+		return nil
+	}
+
 	projectConfig := GlobalCLIContext.ProjectConfig
 	currentBuildTag := projectConfig.BuildTag
 
@@ -626,7 +1001,7 @@ func linkToProjectDir(dep config.Dependency) error {
 			return fmt.Errorf("failed to create symlink for %s: %w", entry.Name(), err)
 		}
 		linkDirs = append(linkDirs, targetPath)
-		fmt.Printf("  Linked %s -> %s\n", targetPath, absSourcePath)
+		printf("  Linked %s -> %s\n", targetPath, absSourcePath)
 	}
 
 	// 记录安装信息用于卸载
@@ -643,7 +1018,7 @@ func linkToProjectDir(dep config.Dependency) error {
 		}
 	}
 
-	fmt.Printf("  ✓ Linked %s to project\n", dep.Name)
+	printf("  ✓ Linked %s to project\n", dep.Name)
 	return nil
 }
 
@@ -1,11 +1,15 @@
 package cli
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"buildfly/internal/errors"
 	"buildfly/pkg/cache"
 	"buildfly/pkg/config"
 )
@@ -187,3 +191,237 @@ func TestInstallCacheRetrieval(t *testing.T) {
 		t.Errorf("Expected retrieved library file to exist: %v", err)
 	}
 }
+
+func TestRecordTestResult(t *testing.T) {
+	tempDir := t.TempDir()
+
+	cacheManager := cache.NewCacheManager(filepath.Join(tempDir, "cache"), 1024*1024*1024, 24*time.Hour)
+	if err := cacheManager.Init(); err != nil {
+		t.Fatalf("Failed to init cache: %v", err)
+	}
+
+	dep := config.Dependency{Name: "test-lib", Version: "1.0.0", BuildSystem: "cmake"}
+	buildTag := &config.BuildTag{Arch: "x86_64", Platform: "linux"}
+
+	// 没有记录时返回空元数据
+	metadata, err := cacheManager.LoadBuildMetadata(dep, buildTag)
+	if err != nil {
+		t.Fatalf("Failed to load metadata: %v", err)
+	}
+	if metadata.Test != nil {
+		t.Error("Expected no test result before recording")
+	}
+
+	result := &cache.TestResult{
+		Status:   cache.TestStatusPassed,
+		Command:  "ctest",
+		Duration: 3 * time.Second,
+		RunAt:    time.Now(),
+	}
+	if err := cacheManager.RecordTestResult(dep, buildTag, result); err != nil {
+		t.Fatalf("Failed to record test result: %v", err)
+	}
+
+	metadata, err = cacheManager.LoadBuildMetadata(dep, buildTag)
+	if err != nil {
+		t.Fatalf("Failed to load metadata: %v", err)
+	}
+	if metadata.Test == nil || metadata.Test.Status != cache.TestStatusPassed {
+		t.Fatalf("Expected passed test result, got %+v", metadata.Test)
+	}
+	if metadata.Test.Duration != 3*time.Second {
+		t.Errorf("Expected duration 3s, got %s", metadata.Test.Duration)
+	}
+
+	// 不同构建标签的结果互不影响
+	other, err := cacheManager.LoadBuildMetadata(dep, nil)
+	if err != nil {
+		t.Fatalf("Failed to load metadata: %v", err)
+	}
+	if other.Test != nil {
+		t.Error("Expected no test result for default build tag")
+	}
+}
+
+func TestRecordBuildEnvironment(t *testing.T) {
+	tempDir := t.TempDir()
+
+	cacheManager := cache.NewCacheManager(filepath.Join(tempDir, "cache"), 1024*1024*1024, 24*time.Hour)
+	if err := cacheManager.Init(); err != nil {
+		t.Fatalf("Failed to init cache: %v", err)
+	}
+
+	dep := config.Dependency{Name: "test-lib", Version: "1.0.0", BuildSystem: "make"}
+	buildTag := &config.BuildTag{Arch: "x86_64", Platform: "linux"}
+
+	env := map[string]string{"PATH": "/usr/bin:/bin", "LANG": "C", "API_TOKEN": "s3cr3t-token"}
+	if err := cacheManager.RecordBuildEnvironment(dep, buildTag, true, env); err != nil {
+		t.Fatalf("Failed to record build environment: %v", err)
+	}
+
+	metadata, err := cacheManager.LoadBuildMetadata(dep, buildTag)
+	if err != nil {
+		t.Fatalf("Failed to load metadata: %v", err)
+	}
+	if !metadata.Hermetic || len(metadata.Environment) != len(env) {
+		t.Fatalf("Expected hermetic environment, got %+v", metadata)
+	}
+	// 共享缓存中只记录变量名和值的哈希
+	sum := sha256.Sum256([]byte("/usr/bin:/bin"))
+	if got := metadata.Environment["PATH"]; got != "sha256:"+hex.EncodeToString(sum[:]) {
+		t.Errorf("Expected hashed PATH, got %s", got)
+	}
+	data, err := os.ReadFile(cacheManager.GetBuildMetadataPath(dep, buildTag))
+	if err != nil {
+		t.Fatalf("Failed to read metadata: %v", err)
+	}
+	if strings.Contains(string(data), "s3cr3t-token") {
+		t.Errorf("Metadata must not contain environment values:\n%s", data)
+	}
+
+	// 非密封构建会清除之前记录的环境
+	if err := cacheManager.RecordBuildEnvironment(dep, buildTag, false, nil); err != nil {
+		t.Fatalf("Failed to record build environment: %v", err)
+	}
+
+	metadata, err = cacheManager.LoadBuildMetadata(dep, buildTag)
+	if err != nil {
+		t.Fatalf("Failed to load metadata: %v", err)
+	}
+	if metadata.Hermetic || metadata.Environment != nil {
+		t.Errorf("Expected environment to be cleared, got %+v", metadata)
+	}
+}
+
+func TestRetrieveCompatibleBuild(t *testing.T) {
+	tempDir := t.TempDir()
+
+	cacheManager := cache.NewCacheManager(filepath.Join(tempDir, "cache"), 1024*1024*1024, 24*time.Hour)
+	if err := cacheManager.Init(); err != nil {
+		t.Fatalf("Failed to init cache: %v", err)
+	}
+
+	dep := config.Dependency{Name: "test-lib", Version: "1.0.0", BuildSystem: "cmake"}
+	builtTag := &config.BuildTag{Arch: "x86_64", Platform: "linux", Runtime: "glibc_2.31", Compiler: "gcc_11"}
+	hostTag := &config.BuildTag{Arch: "x86_64", Platform: "linux", Runtime: "glibc_2.35", Compiler: "gcc_13"}
+
+	installDir := filepath.Join(tempDir, "install")
+	if err := os.MkdirAll(installDir, 0755); err != nil {
+		t.Fatalf("Failed to create install dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(installDir, "lib.a"), []byte("lib"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := cacheManager.StoreBuild(dep, installDir, builtTag); err != nil {
+		t.Fatalf("Failed to store build: %v", err)
+	}
+
+	// 较新的主机可以使用较旧 glibc/gcc 的构建
+	if !cacheManager.IsBuildCached(dep, hostTag) {
+		t.Fatal("Expected compatible build to be found")
+	}
+	if got := cacheManager.FindCompatibleBuild(dep, hostTag); got == nil || !got.Equals(builtTag) {
+		t.Fatalf("FindCompatibleBuild() = %v, want %s", got, builtTag.String())
+	}
+
+	targetDir := filepath.Join(tempDir, "target")
+	if err := cacheManager.RetrieveBuild(dep, targetDir, hostTag); err != nil {
+		t.Fatalf("Failed to retrieve compatible build: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "lib.a")); err != nil {
+		t.Errorf("Expected retrieved file: %v", err)
+	}
+
+	// 较旧的主机不能使用较新 glibc 的构建
+	oldHost := &config.BuildTag{Arch: "x86_64", Platform: "linux", Runtime: "glibc_2.28", Compiler: "gcc_13"}
+	if cacheManager.IsBuildCached(dep, oldHost) {
+		t.Error("Build for glibc 2.31 should not satisfy glibc 2.28")
+	}
+
+	// 变体构建不能用于未指定变体的请求
+	asanTag := builtTag.Clone()
+	asanTag.Sanitizer = "asan"
+	asanDep := config.Dependency{Name: "asan-lib", Version: "1.0.0", BuildSystem: "cmake"}
+	if err := cacheManager.StoreBuild(asanDep, installDir, asanTag); err != nil {
+		t.Fatalf("Failed to store build: %v", err)
+	}
+	if got := cacheManager.FindCompatibleBuild(asanDep, hostTag); got != nil {
+		t.Errorf("FindCompatibleBuild() = %s, want nil for untagged request", got.String())
+	}
+	if err := cacheManager.RetrieveBuild(asanDep, filepath.Join(tempDir, "asan-target"), hostTag); err == nil {
+		t.Error("Expected sanitizer build not to be retrieved for untagged request")
+	}
+}
+
+func TestResolveDependencies(t *testing.T) {
+	previous := GlobalCLIContext.ProjectConfig
+	GlobalCLIContext.ProjectConfig = &config.ProjectConfig{
+		Dependencies: map[string]config.Dependency{
+			"zlib": {Name: "zlib", Version: "1.3.1"},
+			"fmt":  {Name: "fmt", Version: "10.2.1"},
+		},
+		BuildProfiles: map[string]config.BuildProfile{
+			"minimal": {Dependencies: []string{"zlib"}},
+		},
+	}
+	t.Cleanup(func() { GlobalCLIContext.ProjectConfig = previous })
+
+	tests := []struct {
+		name         string
+		deps         []string
+		profile      string
+		wantCount    int
+		wantExitCode int
+	}{
+		{"all dependencies", nil, "", 2, errors.ExitOK},
+		{"named dependency", []string{"fmt"}, "", 1, errors.ExitOK},
+		{"profile", nil, "minimal", 1, errors.ExitOK},
+		{"unknown dependency", []string{"boost"}, "", 0, errors.ExitDependency},
+		{"unknown profile", nil, "release", 0, errors.ExitConfig},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveDependencies(tt.deps, tt.profile)
+			if code := errors.ExitCode(err); code != tt.wantExitCode {
+				t.Fatalf("resolveDependencies() error = %v, exit code %d, want %d", err, code, tt.wantExitCode)
+			}
+			if len(got) != tt.wantCount {
+				t.Errorf("resolveDependencies() returned %d dependencies, want %d", len(got), tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestCompileInBuildDir_NoCacheSkipsMetadata(t *testing.T) {
+	tempDir := t.TempDir()
+	hermetic := true
+
+	cacheManager := cache.NewCacheManager(filepath.Join(tempDir, "cache"), 0, 0)
+	previousCache, previousConfig := GlobalCLIContext.CacheManager, GlobalCLIContext.ProjectConfig
+	GlobalCLIContext.CacheManager = cacheManager
+	GlobalCLIContext.ProjectConfig = &config.ProjectConfig{
+		Project:  config.Project{Name: "test-project"},
+		CacheDir: filepath.Join(tempDir, "cache"),
+		BuildTag: &config.BuildTag{BuildType: "Release"},
+		Hermetic: &hermetic,
+	}
+	t.Cleanup(func() {
+		GlobalCLIContext.CacheManager, GlobalCLIContext.ProjectConfig = previousCache, previousConfig
+	})
+
+	dep := config.Dependency{Name: "test-lib", Version: "1.0.0", BuildSystem: "custom", CustomScript: "true"}
+	buildDir := filepath.Join(tempDir, "build")
+	if err := os.MkdirAll(buildDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// --no-cache 和本地路径依赖的构建结果不进入共享缓存，也不写构建元数据
+	if err := compileInBuildDir(dep, buildDir, buildDir, filepath.Join(tempDir, "install"), true, false); err != nil {
+		t.Fatalf("compileInBuildDir() error = %v", err)
+	}
+	metadataPath := cacheManager.GetBuildMetadataPath(dep, GlobalCLIContext.ProjectConfig.BuildTag)
+	if _, err := os.Stat(metadataPath); !os.IsNotExist(err) {
+		t.Errorf("Expected no build metadata with --no-cache, stat error = %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"buildfly/internal/errors"
+	"buildfly/pkg/config"
+	"buildfly/pkg/utils"
+
+	"gopkg.in/yaml.v2"
+)
+
+// localSourceStamp 构建目录中记录上次构建输入（源码指纹和构建配置）的文件
+const localSourceStamp = ".buildfly-source-stamp"
+
+// installLocalDependency 安装本地路径依赖（source.type: path）
+// 源码不下载也不复制：cmake 和自定义脚本直接使用本地目录，构建目录保持独立；
+// make/configure 在源码目录中构建，只将变化的文件同步到构建目录。
+// 源码指纹和构建配置都没有变化时跳过构建。本地源码随时会变，因此不读写共享缓存。
+func installLocalDependency(dep config.Dependency, force, runTests bool) (string, error) {
+	localDir := dep.Source.Path
+	if info, err := os.Stat(localDir); err != nil || !info.IsDir() {
+		return "", errors.ConfigError(fmt.Sprintf("local source %s of dependency %s is not a directory", localDir, dep.Name)).
+			WithHint("check source.path in buildfly.yaml; relative paths are resolved against the project root")
+	}
+	printf("  Using local source %s\n", localDir)
+
+	// 无需构建的依赖直接链接本地目录，修改立即生效
+	if dep.BuildSystem == "" || dep.BuildSystem == "none" {
+		if GlobalCLIContext.NoLink {
+			return installSourceLocal, nil
+		}
+		targetDir := filepath.Join(GlobalCLIContext.ProjectConfig.BuildFlyBaseDir, "install", dep.Name)
+		if err := activeTimings.measure(phaseLink, func() error {
+			return installDirectly(dep, dep.Source.SourcePath(localDir), targetDir)
+		}); err != nil {
+			return "", err
+		}
+		return installSourceLocal, nil
+	}
+
+	currentBuildTag := GlobalCLIContext.ProjectConfig.BuildTag
+	buildDir := getDepBuildDir(dep, currentBuildTag)
+	installDir := getDepInstallDir(dep, currentBuildTag)
+	if err := os.MkdirAll(buildDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create build dir: %w", err)
+	}
+
+	// 在构建之前计算指纹，构建期间的修改会在下次安装时重新构建
+	fingerprint, err := utils.DirFingerprint(localDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to scan local source %s: %w", localDir, err)
+	}
+	stamp, err := localBuildStamp(dep, currentBuildTag, fingerprint)
+	if err != nil {
+		return "", err
+	}
+	stampFile := filepath.Join(buildDir, localSourceStamp)
+	if !force && !runTests && localBuildUpToDate(stampFile, stamp, installDir) {
+		printf("  Local source unchanged, skipping build\n")
+		if err := linkDependency(dep); err != nil {
+			return "", fmt.Errorf("failed to link to project: %w", err)
+		}
+		return installSourceLocal, nil
+	}
+
+	sourceDir := localDir
+	if dep.BuildSystem == "make" || dep.BuildSystem == "configure" {
+		copied, removed, err := utils.SyncDir(localDir, buildDir)
+		if err != nil {
+			return "", fmt.Errorf("failed to sync local source to build dir: %w", err)
+		}
+		printf("  Synced %d changed files to %s (%d removed)\n", copied, buildDir, removed)
+		sourceDir = buildDir
+	}
+
+	// 不缓存构建结果
+	if err := compileInBuildDir(dep, sourceDir, buildDir, installDir, true, runTests); err != nil {
+		return "", fmt.Errorf("failed to compile: %w", err)
+	}
+	if err := os.WriteFile(stampFile, []byte(stamp+"\n"), 0644); err != nil {
+		GlobalCLIContext.Logger.Warn("Failed to record local source fingerprint", "dependency", dep.Name, "error", err)
+	}
+
+	if err := linkDependency(dep); err != nil {
+		return "", fmt.Errorf("failed to link to project: %w", err)
+	}
+	return installSourceBuilt, nil
+}
+
+// depSourceDir 获取依赖构建时使用的源码根目录
+// 下载的源码复制在构建目录中；本地路径依赖除 make/configure 外直接使用本地目录
+func depSourceDir(dep config.Dependency, buildDir string) string {
+	if dep.Source.IsLocalPath() && dep.BuildSystem != "make" && dep.BuildSystem != "configure" {
+		return dep.Source.Path
+	}
+	return buildDir
+}
+
+// localBuildStamp 计算本地依赖的构建标记
+// 除源码指纹外还包含依赖配置（cmake_options、构建命令、环境变量等）、构建标签（包括变体）、
+// 选用的工具链、密封设置和项目变量，任何一项变化都需要重新构建
+func localBuildStamp(dep config.Dependency, buildTag *config.BuildTag, fingerprint string) (string, error) {
+	projectConfig := GlobalCLIContext.ProjectConfig
+	toolchain, err := projectConfig.SelectToolchain(buildTag)
+	if err != nil {
+		return "", fmt.Errorf("failed to select toolchain: %w", err)
+	}
+
+	inputs := struct {
+		Fingerprint string            `yaml:"fingerprint"`
+		Dependency  config.Dependency `yaml:"dependency"`
+		BuildTag    string            `yaml:"build_tag"`
+		Toolchain   *config.Toolchain `yaml:"toolchain,omitempty"`
+		Hermetic    bool              `yaml:"hermetic"`
+		Variables   map[string]string `yaml:"variables,omitempty"`
+	}{
+		Fingerprint: fingerprint,
+		Dependency:  dep,
+		BuildTag:    buildTag.String(),
+		Toolchain:   toolchain,
+		Hermetic:    projectConfig.IsHermetic(dep),
+		Variables:   projectConfig.Project.Variables,
+	}
+	data, err := yaml.Marshal(inputs)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode build inputs of %s: %w", dep.Name, err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// localBuildUpToDate 检查上次构建的标记是否与当前一致，且安装目录仍然存在
+func localBuildUpToDate(stampFile, stamp, installDir string) bool {
+	data, err := os.ReadFile(stampFile)
+	if err != nil || strings.TrimSpace(string(data)) != stamp {
+		return false
+	}
+	entries, err := os.ReadDir(installDir)
+	return err == nil && len(entries) > 0
+}
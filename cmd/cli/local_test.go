@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"buildfly/pkg/config"
+)
+
+func TestInstallLocalDependency_NoBuild(t *testing.T) {
+	tempDir := t.TempDir()
+	localDir := filepath.Join(tempDir, "headers")
+	if err := os.MkdirAll(filepath.Join(localDir, "include"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	previous := GlobalCLIContext.ProjectConfig
+	GlobalCLIContext.ProjectConfig = &config.ProjectConfig{BuildFlyBaseDir: filepath.Join(tempDir, ".buildfly")}
+	t.Cleanup(func() { GlobalCLIContext.ProjectConfig = previous })
+
+	dep := config.Dependency{
+		Name:        "headers",
+		Version:     "dev",
+		Source:      config.SourceInfo{Type: "path", Path: localDir},
+		BuildSystem: "none",
+	}
+	source, err := installLocalDependency(dep, false, false)
+	if err != nil {
+		t.Fatalf("installLocalDependency() error = %v", err)
+	}
+	if source != installSourceLocal {
+		t.Errorf("source = %s, want %s", source, installSourceLocal)
+	}
+
+	// 直接链接到本地目录，修改立即可见
+	link := filepath.Join(tempDir, ".buildfly", "install", "headers", "include")
+	if target, err := os.Readlink(link); err != nil || target != filepath.Join(localDir, "include") {
+		t.Errorf("Readlink(%s) = %s, %v", link, target, err)
+	}
+
+	dep.Source.Path = filepath.Join(tempDir, "missing")
+	if _, err := installLocalDependency(dep, false, false); err == nil {
+		t.Error("Expected error for missing local source")
+	}
+}
+
+func TestLocalBuildUpToDate(t *testing.T) {
+	tempDir := t.TempDir()
+	stampFile := filepath.Join(tempDir, localSourceStamp)
+	installDir := filepath.Join(tempDir, "install")
+	if err := os.WriteFile(stampFile, []byte("abc\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 安装目录为空时需要重新构建
+	if localBuildUpToDate(stampFile, "abc", installDir) {
+		t.Error("Expected rebuild when install dir is missing")
+	}
+
+	if err := os.MkdirAll(filepath.Join(installDir, "lib"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if !localBuildUpToDate(stampFile, "abc", installDir) {
+		t.Error("Expected unchanged source to be up to date")
+	}
+	if localBuildUpToDate(stampFile, "def", installDir) {
+		t.Error("Expected changed source to need a rebuild")
+	}
+}
+
+func TestLocalBuildStamp(t *testing.T) {
+	previous := GlobalCLIContext.ProjectConfig
+	GlobalCLIContext.ProjectConfig = &config.ProjectConfig{}
+	t.Cleanup(func() { GlobalCLIContext.ProjectConfig = previous })
+
+	dep := config.Dependency{
+		Name:         "mylib",
+		Version:      "dev",
+		Source:       config.SourceInfo{Type: "path", Path: "/src/mylib"},
+		BuildSystem:  "cmake",
+		CMakeOptions: []string{"-DFOO=ON"},
+	}
+	base, err := localBuildStamp(dep, nil, "abc")
+	if err != nil {
+		t.Fatalf("localBuildStamp() error = %v", err)
+	}
+	if again, _ := localBuildStamp(dep, nil, "abc"); again != base {
+		t.Error("Expected stamp to be stable for the same inputs")
+	}
+
+	changedOptions := dep
+	changedOptions.CMakeOptions = []string{"-DFOO=OFF"}
+	hermetic := true
+	changedHermetic := dep
+	changedHermetic.Hermetic = &hermetic
+
+	tests := []struct {
+		name        string
+		dep         config.Dependency
+		buildTag    *config.BuildTag
+		fingerprint string
+	}{
+		{"source changed", dep, nil, "def"},
+		{"cmake options changed", changedOptions, nil, "abc"},
+		{"hermetic changed", changedHermetic, nil, "abc"},
+		{"build tag changed", dep, &config.BuildTag{Sanitizer: "asan"}, "abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := localBuildStamp(tt.dep, tt.buildTag, tt.fingerprint)
+			if err != nil {
+				t.Fatalf("localBuildStamp() error = %v", err)
+			}
+			if got == base {
+				t.Error("Expected stamp to change")
+			}
+		})
+	}
+}
+
+func TestDepSourceDir(t *testing.T) {
+	local := config.SourceInfo{Type: "path", Path: "/src/mylib"}
+	git := config.SourceInfo{Type: "git", URLS: []string{"https://example.com/mylib.git"}}
+
+	tests := []struct {
+		name string
+		dep  config.Dependency
+		want string
+	}{
+		{"downloaded cmake", config.Dependency{BuildSystem: "cmake", Source: git}, "/build"},
+		{"local cmake", config.Dependency{BuildSystem: "cmake", Source: local}, "/src/mylib"},
+		{"local custom", config.Dependency{BuildSystem: "custom", Source: local}, "/src/mylib"},
+		{"local make", config.Dependency{BuildSystem: "make", Source: local}, "/build"},
+		{"local configure", config.Dependency{BuildSystem: "configure", Source: local}, "/build"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := depSourceDir(tt.dep, "/build"); got != tt.want {
+				t.Errorf("depSourceDir() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"buildfly/internal/errors"
+	"buildfly/pkg/cache"
+	"buildfly/pkg/config"
+	"buildfly/pkg/downloader"
+
+	"github.com/spf13/cobra"
+)
+
+// 锁定结果状态
+const (
+	lockStatusLocked    = "locked"
+	lockStatusUnchanged = "unchanged"
+	lockStatusUpdated   = "updated"
+)
+
+// lockResult 单个依赖的锁定结果
+type lockResult struct {
+	Dependency string `json:"dependency"`
+	Version    string `json:"version"`
+	SHA256     string `json:"sha256"`
+	Previous   string `json:"previous,omitempty"`
+	Status     string `json:"status"` // locked, unchanged, updated
+}
+
+// newLockCmd 创建 lock 命令
+func newLockCmd() *cobra.Command {
+	var refresh []string
+
+	cmd := &cobra.Command{
+		Use:   "lock",
+		Short: "在 buildfly.lock 中记录压缩包依赖的校验和",
+		Long: `下载没有配置校验和的压缩包依赖，将 SHA256 记录到项目目录的 buildfly.lock 中。
+之后安装时下载的压缩包必须与记录的校验和一致，否则安装失败。
+
+checksum_policy: tofu 时，install 会在首次下载时自动记录校验和。
+
+上游重新打包导致校验和变化时，确认新的压缩包可信后使用 --refresh 重新下载并更新记录。`,
+		Example: `  buildfly lock
+  buildfly lock --refresh zlib`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLock(refresh)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&refresh, "refresh", nil, "重新下载指定依赖并更新已记录的校验和")
+
+	return cmd
+}
+
+// runLock 记录或刷新压缩包依赖的校验和
+func runLock(refresh []string) error {
+	if err := GlobalCLIContext.Initialize(); err != nil {
+		return fmt.Errorf("failed to initialize context: %w", err)
+	}
+
+	projectConfig := GlobalCLIContext.ProjectConfig
+	lockPath := config.LockfilePath(projectConfig.ProjectRoot)
+
+	unlock, err := config.AcquireLockfile(lockPath)
+	if err != nil {
+		return errors.SystemErrorWithCause(err, "failed to lock lockfile")
+	}
+	defer unlock()
+
+	lock, err := config.LoadLockfile(lockPath)
+	if err != nil {
+		return errors.ConfigErrorWithCause(err, "failed to load lockfile")
+	}
+
+	deps, err := lockTargets(projectConfig, lock, refresh)
+	if err != nil {
+		return err
+	}
+	if len(deps) == 0 {
+		printf("All archive dependencies are locked in %s\n", config.LockfileName)
+		if isStructuredOutput() {
+			return writeOutput("lock", []lockResult{})
+		}
+		return nil
+	}
+
+	results := make([]lockResult, 0, len(deps))
+	for _, dep := range deps {
+		printf("Locking %s (%s)...\n", dep.Name, dep.Version)
+
+		// 刷新时丢弃之前下载的压缩包和源码缓存，从远程重新获取
+		if len(refresh) > 0 {
+			if err := downloader.RemoveCachedArchive(dep); err != nil {
+				return err
+			}
+			if err := GlobalCLIContext.CacheManager.Invalidate(dep); err != nil {
+				return err
+			}
+		}
+
+		sum, err := downloadChecksum(dep)
+		if err != nil {
+			return err
+		}
+
+		result := lockResult{Dependency: dep.Name, Version: dep.Version, SHA256: sum, Status: lockStatusLocked}
+		if previous := lock.Checksum(dep); previous != "" {
+			result.Previous = previous
+			result.Status = lockStatusUnchanged
+			if previous != sum {
+				result.Status = lockStatusUpdated
+			}
+		}
+		lock.SetChecksum(dep, sum)
+		results = append(results, result)
+
+		switch result.Status {
+		case lockStatusUpdated:
+			printf("  ✓ sha256 changed: %s -> %s\n", result.Previous, sum)
+		case lockStatusUnchanged:
+			printf("  ✓ sha256 unchanged: %s\n", sum)
+		default:
+			printf("  ✓ sha256 %s\n", sum)
+		}
+	}
+
+	if err := lock.Save(lockPath); err != nil {
+		return errors.SystemErrorWithCause(err, "failed to save lockfile")
+	}
+	printf("Updated %s\n", lockPath)
+
+	if isStructuredOutput() {
+		return writeOutput("lock", results)
+	}
+	return nil
+}
+
+// lockTargets 确定需要锁定的依赖：指定了 refresh 时为这些依赖，否则为所有尚未记录校验和的压缩包依赖
+func lockTargets(projectConfig *config.ProjectConfig, lock *config.Lockfile, refresh []string) ([]config.Dependency, error) {
+	var deps []config.Dependency
+
+	if len(refresh) > 0 {
+		for _, name := range refresh {
+			dep, exists := projectConfig.Dependencies[name]
+			if !exists {
+				return nil, errors.ConfigError(fmt.Sprintf("dependency not found: %s", name))
+			}
+			dep.Name = name
+			if dep.Source.Type != "archive" {
+				return nil, errors.ConfigError(fmt.Sprintf("dependency %s is not an archive dependency", name)).
+					WithHint("only archive sources are recorded in " + config.LockfileName)
+			}
+			if dep.Source.HasChecksum() {
+				return nil, errors.ConfigError(fmt.Sprintf("checksum of %s is set in buildfly.yaml", name)).
+					WithHint("update the checksum in buildfly.yaml, or remove it to record the checksum in " + config.LockfileName)
+			}
+			deps = append(deps, dep)
+		}
+		return deps, nil
+	}
+
+	names := make([]string, 0, len(projectConfig.Dependencies))
+	for name := range projectConfig.Dependencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		dep := projectConfig.Dependencies[name]
+		dep.Name = name
+		if dep.Source.Type == "archive" && !dep.Source.HasChecksum() && lock.Checksum(dep) == "" {
+			deps = append(deps, dep)
+		}
+	}
+	return deps, nil
+}
+
+// downloadChecksum 下载压缩包依赖并返回压缩包的 SHA256
+func downloadChecksum(dep config.Dependency) (string, error) {
+	tempDir, err := os.MkdirTemp("", "buildfly-lock-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	stats, err := GlobalCLIContext.DownloadManager.DownloadWithStats(context.Background(), dep, tempDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", dep.Name, err)
+	}
+	if stats.SHA256 == "" {
+		return "", errors.DownloadError(fmt.Sprintf("no checksum computed for %s", dep.Name))
+	}
+	return stats.SHA256, nil
+}
+
+// lockedDependency 为没有配置校验和的压缩包依赖填入锁文件中记录的 SHA256，下载时据此校验
+func lockedDependency(dep config.Dependency) (config.Dependency, error) {
+	if dep.Source.Type != "archive" || dep.Source.HasChecksum() {
+		return dep, nil
+	}
+
+	// 锁文件通过重命名整体替换，读取时不需要加锁
+	lock, err := config.LoadLockfile(config.LockfilePath(GlobalCLIContext.ProjectConfig.ProjectRoot))
+	if err != nil {
+		return dep, errors.ConfigErrorWithCause(err, "failed to load lockfile")
+	}
+	if sum := lock.Checksum(dep); sum != "" {
+		dep.Source.SHA256 = sum
+	}
+	return dep, nil
+}
+
+// recordChecksum 使用 tofu 策略时将首次下载的压缩包校验和记录到锁文件，已有记录时不修改
+func recordChecksum(dep config.Dependency, sum string) error {
+	projectConfig := GlobalCLIContext.ProjectConfig
+	if projectConfig.ChecksumPolicy != config.ChecksumPolicyTOFU || sum == "" ||
+		dep.Source.Type != "archive" || dep.Source.HasChecksum() {
+		return nil
+	}
+
+	lockPath := config.LockfilePath(projectConfig.ProjectRoot)
+	unlock, err := config.AcquireLockfile(lockPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	lock, err := config.LoadLockfile(lockPath)
+	if err != nil {
+		return err
+	}
+	if lock.Checksum(dep) != "" {
+		return nil
+	}
+	lock.SetChecksum(dep, sum)
+	if err := lock.Save(lockPath); err != nil {
+		return err
+	}
+	printf("  ✓ Recorded sha256 %s in %s\n", sum, config.LockfileName)
+	return nil
+}
+
+// useCachedDownload 判断下载缓存能否满足锁文件：缓存记录的压缩包校验和必须与锁文件一致，
+// tofu 策略下锁文件没有记录时写入缓存的校验和。不能确认时返回 false，重新下载并校验
+func useCachedDownload(dep config.Dependency, cacheManager *cache.CacheManager) (bool, error) {
+	if dep.Source.Type != "archive" || dep.Source.HasChecksum() {
+		return true, nil
+	}
+
+	locked, err := lockedDependency(dep)
+	if err != nil {
+		return false, err
+	}
+	sum := cacheManager.DownloadChecksum(dep)
+	if expected := locked.Source.SHA256; expected != "" {
+		return strings.EqualFold(sum, expected), nil
+	}
+	if GlobalCLIContext.ProjectConfig.ChecksumPolicy != config.ChecksumPolicyTOFU {
+		return true, nil
+	}
+	if sum == "" {
+		return false, nil
+	}
+	if err := recordChecksum(dep, sum); err != nil {
+		GlobalCLIContext.Logger.Warn("Failed to record checksum", "dependency", dep.Name, "error", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,237 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"buildfly/pkg/cache"
+	"buildfly/pkg/config"
+)
+
+// setupLockTest 使用临时项目目录作为锁文件位置
+func setupLockTest(t *testing.T, policy string) *config.ProjectConfig {
+	t.Helper()
+	projectConfig := &config.ProjectConfig{
+		ProjectRoot:    t.TempDir(),
+		ChecksumPolicy: policy,
+		Dependencies: map[string]config.Dependency{
+			"zlib":   {Version: "1.3.1", Source: config.SourceInfo{Type: "archive", URLS: []string{"https://example.com/zlib-1.3.1.tar.gz"}}},
+			"bzip2":  {Version: "1.0.8", Source: config.SourceInfo{Type: "archive", URLS: []string{"https://example.com/bzip2-1.0.8.tar.gz"}}},
+			"pinned": {Version: "2.0", Source: config.SourceInfo{Type: "archive", SHA256: "aaaa"}},
+			"fmt":    {Version: "10.2.1", Source: config.SourceInfo{Type: "git", URLS: []string{"https://github.com/fmtlib/fmt.git"}}},
+		},
+	}
+	for name, dep := range projectConfig.Dependencies {
+		dep.Name = name
+		projectConfig.Dependencies[name] = dep
+	}
+
+	previous := GlobalCLIContext.ProjectConfig
+	GlobalCLIContext.ProjectConfig = projectConfig
+	t.Cleanup(func() { GlobalCLIContext.ProjectConfig = previous })
+	return projectConfig
+}
+
+func TestRecordChecksum(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		dep    string
+		want   string
+	}{
+		{"tofu records first download", config.ChecksumPolicyTOFU, "zlib", "1111"},
+		{"default policy does not record", "", "zlib", ""},
+		{"none policy does not record", config.ChecksumPolicyNone, "zlib", ""},
+		{"configured checksum is not recorded", config.ChecksumPolicyTOFU, "pinned", ""},
+		{"git dependency is not recorded", config.ChecksumPolicyTOFU, "fmt", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectConfig := setupLockTest(t, tt.policy)
+			dep := projectConfig.Dependencies[tt.dep]
+
+			if err := recordChecksum(dep, "1111"); err != nil {
+				t.Fatalf("recordChecksum() error = %v", err)
+			}
+			lock, err := config.LoadLockfile(config.LockfilePath(projectConfig.ProjectRoot))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := lock.Checksum(dep); got != tt.want {
+				t.Errorf("locked checksum = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordChecksum_KeepsExisting(t *testing.T) {
+	projectConfig := setupLockTest(t, config.ChecksumPolicyTOFU)
+	dep := projectConfig.Dependencies["zlib"]
+
+	if err := recordChecksum(dep, "1111"); err != nil {
+		t.Fatal(err)
+	}
+	// 已有记录时首次使用信任不覆盖，只能通过 lock --refresh 更新
+	if err := recordChecksum(dep, "2222"); err != nil {
+		t.Fatal(err)
+	}
+
+	locked, err := lockedDependency(dep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if locked.Source.SHA256 != "1111" {
+		t.Errorf("locked SHA256 = %q, want 1111", locked.Source.SHA256)
+	}
+
+	// 版本变化后不使用旧版本的校验和
+	dep.Version = "1.3.2"
+	if locked, _ := lockedDependency(dep); locked.Source.SHA256 != "" {
+		t.Errorf("locked SHA256 for new version = %q, want empty", locked.Source.SHA256)
+	}
+}
+
+func TestLockedDependency_NoLockfile(t *testing.T) {
+	projectConfig := setupLockTest(t, "")
+	dep := projectConfig.Dependencies["zlib"]
+
+	locked, err := lockedDependency(dep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if locked.Source.HasChecksum() {
+		t.Errorf("expected no checksum without lockfile, got %+v", locked.Source)
+	}
+	if _, err := os.Stat(config.LockfilePath(projectConfig.ProjectRoot)); !os.IsNotExist(err) {
+		t.Error("lockfile should not be created by lookups")
+	}
+}
+
+func TestUseCachedDownload(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   string
+		locked   string // 锁文件中记录的校验和
+		cached   string // 下载缓存记录的校验和
+		want     bool
+		wantLock string
+	}{
+		{"matches lockfile", "", "1111", "1111", true, "1111"},
+		{"differs from lockfile", "", "1111", "2222", false, "1111"},
+		{"no cached checksum with lockfile", "", "1111", "", false, "1111"},
+		{"tofu records cached checksum", config.ChecksumPolicyTOFU, "", "2222", true, "2222"},
+		{"tofu without cached checksum", config.ChecksumPolicyTOFU, "", "", false, ""},
+		{"default policy without lockfile", "", "", "", true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectConfig := setupLockTest(t, tt.policy)
+			dep := projectConfig.Dependencies["zlib"]
+			lockPath := config.LockfilePath(projectConfig.ProjectRoot)
+			cacheManager := cache.NewCacheManager(t.TempDir(), 0, 0)
+
+			if tt.locked != "" {
+				lock, err := config.LoadLockfile(lockPath)
+				if err != nil {
+					t.Fatal(err)
+				}
+				lock.SetChecksum(dep, tt.locked)
+				if err := lock.Save(lockPath); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.cached != "" {
+				if err := cacheManager.RecordDownloadChecksum(dep, tt.cached); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := useCachedDownload(dep, cacheManager)
+			if err != nil {
+				t.Fatalf("useCachedDownload() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("useCachedDownload() = %v, want %v", got, tt.want)
+			}
+			lock, err := config.LoadLockfile(lockPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if locked := lock.Checksum(dep); locked != tt.wantLock {
+				t.Errorf("locked checksum = %q, want %q", locked, tt.wantLock)
+			}
+		})
+	}
+}
+
+func TestLockTargets(t *testing.T) {
+	projectConfig := setupLockTest(t, "")
+	lock := &config.Lockfile{Dependencies: map[string]config.LockedDependency{}}
+	lock.SetChecksum(projectConfig.Dependencies["bzip2"], "1111")
+
+	// 默认只锁定尚未记录的压缩包依赖
+	deps, err := lockTargets(projectConfig, lock, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deps) != 1 || deps[0].Name != "zlib" {
+		t.Errorf("lockTargets() = %v, want [zlib]", deps)
+	}
+
+	// 刷新已记录的依赖
+	deps, err = lockTargets(projectConfig, lock, []string{"bzip2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deps) != 1 || deps[0].Name != "bzip2" {
+		t.Errorf("lockTargets(refresh bzip2) = %v, want [bzip2]", deps)
+	}
+
+	for _, name := range []string{"missing", "fmt", "pinned"} {
+		if _, err := lockTargets(projectConfig, lock, []string{name}); err == nil {
+			t.Errorf("lockTargets(refresh %s) expected error", name)
+		}
+	}
+}
+
+func TestTryInstallFromCache_LockfileMismatch(t *testing.T) {
+	projectConfig := setupLockTest(t, "")
+	projectConfig.BuildFlyBaseDir = filepath.Join(projectConfig.ProjectRoot, ".buildfly")
+	dep := projectConfig.Dependencies["zlib"]
+	cacheManager := cache.NewCacheManager(t.TempDir(), 0, 0)
+
+	// 下载缓存中的压缩包与锁文件记录的不一致（被篡改或已过期）
+	downloadCachePath := cacheManager.GetDownloadCachePath(dep)
+	if err := os.MkdirAll(downloadCachePath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(downloadCachePath, "zlib.h"), []byte("tampered"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cacheManager.RecordDownloadChecksum(dep, "2222"); err != nil {
+		t.Fatal(err)
+	}
+	lockPath := config.LockfilePath(projectConfig.ProjectRoot)
+	lock, err := config.LoadLockfile(lockPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock.SetChecksum(dep, "1111")
+	if err := lock.Save(lockPath); err != nil {
+		t.Fatal(err)
+	}
+
+	source, installed, err := tryInstallFromCache(dep, cacheManager, false, false)
+	if err != nil {
+		t.Fatalf("tryInstallFromCache() error = %v", err)
+	}
+	if installed {
+		t.Errorf("tryInstallFromCache() installed from %s, want fresh download", source)
+	}
+	if _, err := os.Stat(filepath.Join(projectConfig.BuildFlyBaseDir, "install", "zlib")); !os.IsNotExist(err) {
+		t.Errorf("Expected nothing to be linked, got %v", err)
+	}
+}
@@ -0,0 +1,394 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"buildfly/pkg/config"
+)
+
+// 安装结果状态
+const (
+	installStatusInstalled = "installed"
+	installStatusFailed    = "failed"
+	installStatusSkipped   = "skipped"
+)
+
+// 安装来源
+const (
+	installSourceCache    = "cache"    // 复用构建缓存（或无需构建依赖的下载缓存）
+	installSourceBuilt    = "built"    // 从源码构建
+	installSourceDownload = "download" // 无需构建，直接使用下载的文件
+	installSourceLocal    = "local"    // 本地路径源码没有变化，复用上次的构建
+)
+
+// installResult 单个依赖的安装结果
+type installResult struct {
+	Dependency string `json:"dependency"`
+	Version    string `json:"version,omitempty"`
+	Status     string `json:"status"`           // installed, failed, skipped
+	Source     string `json:"source,omitempty"` // cache, built, download
+	DurationMS int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// matrixVariant 构建矩阵中的一个变体
+type matrixVariant struct {
+	Label    string // 矩阵维度的取值，例如 build_type=Debug,compiler=gcc_11
+	BuildTag *config.BuildTag
+}
+
+// matrixOutcome 变体的构建结果
+type matrixOutcome struct {
+	Variant matrixVariant
+	Results []installResult
+	LogFile string
+	Err     error
+}
+
+// writeInstallResults 将安装结果写入 JSON 文件，未指定文件时不写入
+func writeInstallResults(resultFile string, results []installResult) {
+	if resultFile == "" {
+		return
+	}
+
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err == nil {
+		err = os.WriteFile(resultFile, data, 0644)
+	}
+	if err != nil {
+		GlobalCLIContext.Logger.Warn("Failed to write install results", "path", resultFile, "error", err)
+	}
+}
+
+// expandMatrix 展开构建矩阵，每个组合在基础构建标签上覆盖对应的维度
+func expandMatrix(base *config.BuildTag, matrix map[string][]string, dimensions config.BuildTagDimensions) ([]matrixVariant, error) {
+	keys := make([]string, 0, len(matrix))
+	for key := range matrix {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// 计算所有组合（笛卡尔积）
+	combinations := [][]string{{}}
+	for _, key := range keys {
+		var next [][]string
+		for _, combination := range combinations {
+			for _, value := range matrix[key] {
+				pairs := append(append([]string{}, combination...), fmt.Sprintf("%s=%s", key, value))
+				next = append(next, pairs)
+			}
+		}
+		combinations = next
+	}
+
+	variants := make([]matrixVariant, 0, len(combinations))
+	for _, combination := range combinations {
+		label := strings.Join(combination, ",")
+
+		tagStr := label
+		if baseStr := base.String(); baseStr != "" {
+			tagStr = baseStr + "," + label
+		}
+
+		buildTag, err := dimensions.ParseBuildTag(tagStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid matrix variant %s: %w", label, err)
+		}
+		if err := dimensions.ValidateBuildTag(buildTag); err != nil {
+			return nil, fmt.Errorf("invalid matrix variant %s: %w", label, err)
+		}
+
+		variants = append(variants, matrixVariant{Label: label, BuildTag: buildTag})
+	}
+
+	return variants, nil
+}
+
+// runMatrixInstall 按构建配置文件中的 matrix 安装所有变体
+// 源码只下载一次，各个变体在独立的子进程中并行构建，互不影响
+func runMatrixInstall(deps []string, force, noCache, runTests, timings bool, profile, buildTag string, jobs int) error {
+	if err := GlobalCLIContext.Initialize(); err != nil {
+		return fmt.Errorf("failed to initialize context: %w", err)
+	}
+
+	projectConfig := GlobalCLIContext.ProjectConfig
+
+	if profile == "" {
+		return fmt.Errorf("--matrix requires --profile")
+	}
+	buildProfile, exists := projectConfig.BuildProfiles[profile]
+	if !exists {
+		return fmt.Errorf("build profile not found: %s", profile)
+	}
+	if len(buildProfile.Matrix) == 0 {
+		return fmt.Errorf("build profile %s has no matrix", profile)
+	}
+
+	baseBuildTag, err := resolveBuildTag(buildTag)
+	if err != nil {
+		return err
+	}
+
+	variants, err := expandMatrix(baseBuildTag, buildProfile.Matrix, projectConfig.BuildTagDimensions)
+	if err != nil {
+		return err
+	}
+
+	dependencies, err := resolveDependencies(deps, profile)
+	if err != nil {
+		return err
+	}
+	if len(dependencies) == 0 {
+		printLine("No dependencies to install")
+		return nil
+	}
+
+	// 预先下载源码，所有变体共享下载缓存
+	if !noCache {
+		prefetchSources(dependencies)
+	}
+
+	if jobs < 1 {
+		jobs = 1
+	}
+	printf("\nBuilding %d variants (%d in parallel)...\n", len(variants), jobs)
+
+	logDir := filepath.Join(projectConfig.BuildFlyBaseDir, "logs", "matrix")
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	outcomes := make([]matrixOutcome, len(variants))
+	semaphore := make(chan struct{}, jobs)
+	var wg sync.WaitGroup
+
+	for i, variant := range variants {
+		wg.Add(1)
+		go func(i int, variant matrixVariant) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			printf("  [%s] started\n", variant.Label)
+			outcomes[i] = runMatrixVariant(variant, deps, profile, force, noCache, runTests, timings, logDir)
+			if outcomes[i].Err != nil {
+				printf("  [%s] ✗ failed, see %s\n", variant.Label, outcomes[i].LogFile)
+			} else {
+				printf("  [%s] ✓ done\n", variant.Label)
+			}
+		}(i, variant)
+	}
+	wg.Wait()
+
+	failed := printMatrixSummary(outcomes, dependencies)
+	if err := writeMatrixOutput(outcomes); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d matrix variants failed", failed, len(variants))
+	}
+	return nil
+}
+
+// matrixVariantOutput 构建矩阵变体的结构化输出
+type matrixVariantOutput struct {
+	Variant  string          `json:"variant"`
+	BuildTag string          `json:"build_tag"`
+	LogFile  string          `json:"log_file"`
+	Error    string          `json:"error,omitempty"`
+	Results  []installResult `json:"results"`
+}
+
+// writeMatrixOutput 以结构化格式输出构建矩阵的结果，文本输出时不做任何事
+func writeMatrixOutput(outcomes []matrixOutcome) error {
+	if !isStructuredOutput() {
+		return nil
+	}
+
+	variants := make([]matrixVariantOutput, 0, len(outcomes))
+	for _, outcome := range outcomes {
+		variant := matrixVariantOutput{
+			Variant:  outcome.Variant.Label,
+			BuildTag: outcome.Variant.BuildTag.String(),
+			LogFile:  outcome.LogFile,
+			Results:  outcome.Results,
+		}
+		if outcome.Err != nil {
+			variant.Error = outcome.Err.Error()
+		}
+		variants = append(variants, variant)
+	}
+	return writeOutput("matrix", variants)
+}
+
+// prefetchSources 下载依赖源码到下载缓存
+func prefetchSources(dependencies []config.Dependency) {
+	cacheManager := GlobalCLIContext.CacheManager
+	downloadManager := GlobalCLIContext.DownloadManager
+
+	for _, dep := range dependencies {
+		if cacheManager.IsCachedDownloads(dep) {
+			continue
+		}
+
+		printf("Fetching %s (%s)...\n", dep.Name, dep.Version)
+		sourceDir, err := downloadArchivesIfNeeded(dep, cacheManager, downloadManager, false)
+		if err != nil {
+			// 下载失败时由各个变体自行重试并报告错误
+			GlobalCLIContext.Logger.Warn("Failed to fetch source", "dependency", dep.Name, "error", err)
+			continue
+		}
+		os.RemoveAll(sourceDir)
+	}
+}
+
+// runMatrixVariant 在子进程中安装一个变体，输出写入日志文件
+func runMatrixVariant(variant matrixVariant, deps []string, profile string, force, noCache, runTests, timings bool, logDir string) matrixOutcome {
+	outcome := matrixOutcome{
+		Variant: variant,
+		LogFile: filepath.Join(logDir, variant.BuildTag.ToDirName()+".log"),
+	}
+	resultFile := filepath.Join(logDir, variant.BuildTag.ToDirName()+".json")
+	os.Remove(resultFile)
+
+	executable, err := os.Executable()
+	if err != nil {
+		outcome.Err = fmt.Errorf("failed to locate buildfly executable: %w", err)
+		return outcome
+	}
+
+	// 并行的变体各自写出耗时报告，不能共用 .buildfly/timings.json
+	timingsDir := ""
+	if timings {
+		timingsDir = filepath.Join(logDir, variant.BuildTag.ToDirName())
+	}
+	args := matrixVariantArgs(variant, deps, profile, force, noCache, runTests, resultFile, timingsDir)
+
+	logFile, err := os.Create(outcome.LogFile)
+	if err != nil {
+		outcome.Err = fmt.Errorf("failed to create log file: %w", err)
+		return outcome
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command(executable, args...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	outcome.Err = cmd.Run()
+
+	if data, err := os.ReadFile(resultFile); err == nil {
+		json.Unmarshal(data, &outcome.Results)
+	}
+
+	return outcome
+}
+
+// matrixVariantArgs 构造安装单个变体的命令行参数，timingsDir 不为空时启用 --timings
+func matrixVariantArgs(variant matrixVariant, deps []string, profile string, force, noCache, runTests bool, resultFile, timingsDir string) []string {
+	args := []string{"install", "--profile", profile, "--build-tag", variant.BuildTag.String(),
+		"--result-file", resultFile, "--no-link"}
+	if force {
+		args = append(args, "--force")
+	}
+	if noCache {
+		args = append(args, "--no-cache")
+	}
+	if runTests {
+		args = append(args, "--test")
+	}
+	if timingsDir != "" {
+		args = append(args, "--timings", "--timings-dir", timingsDir)
+	}
+
+	// 传递全局选项
+	options := GlobalCLIContext.GlobalOptions
+	if options.ConfigFile != "" {
+		args = append(args, "--config", options.ConfigFile)
+	}
+	if options.CacheDir != "" {
+		args = append(args, "--cache-dir", options.CacheDir)
+	}
+	if options.MaxCacheAge != "" {
+		args = append(args, "--max-cache-age", options.MaxCacheAge)
+	}
+	if options.Verbose {
+		args = append(args, "--verbose")
+	}
+	if options.LogLevel != "" {
+		args = append(args, "--log-level", options.LogLevel)
+	}
+	if options.LogFormat != "" {
+		args = append(args, "--log-format", options.LogFormat)
+	}
+
+	return append(args, deps...)
+}
+
+// printMatrixSummary 打印 变体 × 依赖 的结果表，返回失败的变体数
+func printMatrixSummary(outcomes []matrixOutcome, dependencies []config.Dependency) int {
+	names := make([]string, 0, len(dependencies))
+	for _, dep := range dependencies {
+		names = append(names, dep.Name)
+	}
+	sort.Strings(names)
+
+	labelWidth := len("VARIANT")
+	for _, outcome := range outcomes {
+		if len(outcome.Variant.Label) > labelWidth {
+			labelWidth = len(outcome.Variant.Label)
+		}
+	}
+
+	printf("\nMatrix summary:\n")
+	printf("%-*s", labelWidth+2, "VARIANT")
+	for _, name := range names {
+		printf("  %-12s", name)
+	}
+	printLine()
+	printLine(strings.Repeat("-", labelWidth+2+len(names)*14))
+
+	failed := 0
+	for _, outcome := range outcomes {
+		if outcome.Err != nil {
+			failed++
+		}
+
+		statuses := make(map[string]string)
+		for _, result := range outcome.Results {
+			statuses[result.Dependency] = result.Status
+		}
+
+		printf("%-*s", labelWidth+2, outcome.Variant.Label)
+		for _, name := range names {
+			printf("  %-12s", formatInstallStatus(statuses[name], outcome.Err))
+		}
+		printLine()
+	}
+
+	return failed
+}
+
+// formatInstallStatus 格式化依赖在某个变体中的安装状态
+func formatInstallStatus(status string, variantErr error) string {
+	switch status {
+	case installStatusInstalled:
+		return "ok"
+	case installStatusFailed:
+		return "FAILED"
+	case installStatusSkipped:
+		return "skipped"
+	default:
+		// 子进程没有写出结果（例如初始化失败）
+		if variantErr != nil {
+			return "ERROR"
+		}
+		return "-"
+	}
+}
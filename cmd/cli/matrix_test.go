@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"buildfly/pkg/config"
+)
+
+func TestExpandMatrix(t *testing.T) {
+	base := &config.BuildTag{Arch: "x86_64", Platform: "linux", Compiler: "gcc_11"}
+	matrix := map[string][]string{
+		"build_type": {"Release", "Debug"},
+		"compiler":   {"gcc_11", "clang_15"},
+	}
+
+	variants, err := expandMatrix(base, matrix, nil)
+	if err != nil {
+		t.Fatalf("expandMatrix() error = %v", err)
+	}
+
+	wantLabels := []string{
+		"build_type=Release,compiler=gcc_11",
+		"build_type=Release,compiler=clang_15",
+		"build_type=Debug,compiler=gcc_11",
+		"build_type=Debug,compiler=clang_15",
+	}
+	if len(variants) != len(wantLabels) {
+		t.Fatalf("expandMatrix() returned %d variants, want %d", len(variants), len(wantLabels))
+	}
+
+	dirs := make(map[string]bool)
+	for i, variant := range variants {
+		if variant.Label != wantLabels[i] {
+			t.Errorf("variant[%d].Label = %s, want %s", i, variant.Label, wantLabels[i])
+		}
+		if variant.BuildTag.Arch != "x86_64" || variant.BuildTag.Platform != "linux" {
+			t.Errorf("variant %s lost base build tag: %s", variant.Label, variant.BuildTag.String())
+		}
+		dirs[variant.BuildTag.ToDirName()] = true
+	}
+
+	// 矩阵维度覆盖基础构建标签
+	if variants[1].BuildTag.Compiler != "clang_15" || variants[2].BuildTag.BuildType != "Debug" {
+		t.Errorf("matrix values not applied: %s, %s", variants[1].BuildTag.String(), variants[2].BuildTag.String())
+	}
+
+	// 每个变体使用独立的目录，不会互相覆盖
+	if len(dirs) != len(variants) {
+		t.Errorf("expected %d distinct build directories, got %d", len(variants), len(dirs))
+	}
+
+	// 无效的维度取值
+	if _, err := expandMatrix(base, map[string][]string{"link": {"dynamic"}}, nil); err == nil {
+		t.Error("expandMatrix() expected error for invalid link value")
+	}
+}
+
+func TestMatrixVariantArgs(t *testing.T) {
+	previous := GlobalCLIContext.GlobalOptions
+	GlobalCLIContext.GlobalOptions = &GlobalOptions{Verbose: true}
+	t.Cleanup(func() { GlobalCLIContext.GlobalOptions = previous })
+
+	variant := matrixVariant{Label: "compiler=gcc_11", BuildTag: &config.BuildTag{Arch: "x86_64", Compiler: "gcc_11"}}
+
+	tests := []struct {
+		name       string
+		timingsDir string
+		want       string
+	}{
+		{"without timings", "", "install --profile ci --build-tag arch=x86_64,compiler=gcc_11 --result-file /logs/v.json --no-link --test --verbose zlib"},
+		{"with timings", "/logs/v", "install --profile ci --build-tag arch=x86_64,compiler=gcc_11 --result-file /logs/v.json --no-link --test --timings --timings-dir /logs/v --verbose zlib"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := matrixVariantArgs(variant, []string{"zlib"}, "ci", false, false, true, "/logs/v.json", tt.timingsDir)
+			if got := strings.Join(args, " "); got != tt.want {
+				t.Errorf("matrixVariantArgs() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"buildfly/internal/errors"
+	"buildfly/internal/logging"
+
+	"gopkg.in/yaml.v2"
+)
+
+// 输出格式
+const (
+	outputFormatText = "text"
+	outputFormatJSON = "json"
+	outputFormatYAML = "yaml"
+)
+
+// outputSchemaVersion 结构化输出的 schema 版本，字段有不兼容变更时递增
+const outputSchemaVersion = 1
+
+// outputDocument 结构化输出的顶层结构
+type outputDocument struct {
+	SchemaVersion int         `json:"schema_version"`
+	Kind          string      `json:"kind"` // detect, list, cache, install, matrix, lock, error
+	Data          interface{} `json:"data"`
+}
+
+// setupOutput 检查输出格式，结构化输出时将进度信息输出到标准错误，标准输出只留给结构化结果
+func setupOutput(format string) error {
+	switch format {
+	case "", outputFormatText:
+		return nil
+	case outputFormatJSON, outputFormatYAML:
+		logging.SetConsole(os.Stderr)
+		return nil
+	default:
+		return fmt.Errorf("invalid output format: %s (supported: text, json, yaml)", format)
+	}
+}
+
+// printf 输出进度信息
+// 命令输出的文本都经过 printf、printLine、eprintf 和 writeOutput，统一在这里隐藏下载凭据等密钥
+func printf(format string, args ...interface{}) {
+	io.WriteString(logging.Console(), logging.Redact(fmt.Sprintf(format, args...)))
+}
+
+// printLine 输出一行进度信息
+func printLine(args ...interface{}) {
+	io.WriteString(logging.Console(), logging.Redact(fmt.Sprintln(args...)))
+}
+
+// eprintf 输出到标准错误，用于不经过日志记录器的错误信息
+func eprintf(format string, args ...interface{}) {
+	io.WriteString(os.Stderr, logging.Redact(fmt.Sprintf(format, args...)))
+}
+
+// isStructuredOutput 是否使用 JSON/YAML 输出
+func isStructuredOutput() bool {
+	format := GlobalCLIContext.GlobalOptions.Output
+	return format == outputFormatJSON || format == outputFormatYAML
+}
+
+// writeOutput 将结果以结构化格式写到标准输出
+func writeOutput(kind string, data interface{}) error {
+	return encodeOutput(os.Stdout, GlobalCLIContext.GlobalOptions.Output, kind, data)
+}
+
+// encodeOutput 按指定格式编码结果
+// YAML 由 JSON 转换而来，保证两种格式的字段名一致
+func encodeOutput(w io.Writer, format, kind string, data interface{}) error {
+	doc := outputDocument{SchemaVersion: outputSchemaVersion, Kind: kind, Data: data}
+
+	content, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+
+	if format == outputFormatYAML {
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.UseNumber()
+		var generic interface{}
+		if err := decoder.Decode(&generic); err != nil {
+			return fmt.Errorf("failed to encode output: %w", err)
+		}
+		if content, err = yaml.Marshal(yamlValue(generic)); err != nil {
+			return fmt.Errorf("failed to encode output: %w", err)
+		}
+	} else {
+		content = append(content, '\n')
+	}
+
+	// 错误信息等字段中可能带有下载凭据
+	_, err = io.WriteString(w, logging.Redact(string(content)))
+	return err
+}
+
+// yamlValue 将 JSON 数字转换为整数或浮点数，避免 YAML 中出现科学计数法或带引号的数字
+func yamlValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = yamlValue(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = yamlValue(item)
+		}
+		return v
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	default:
+		return v
+	}
+}
+
+// errorOutput 命令失败时的结构化输出
+type errorOutput struct {
+	Message  string              `json:"message"`
+	Code     string              `json:"code,omitempty"`
+	ExitCode int                 `json:"exit_code"`
+	Hint     string              `json:"hint,omitempty"`
+	Chain    []errors.ChainEntry `json:"chain"`
+	Partial  interface{}         `json:"partial,omitempty"` // 失败前已经得到的结果，例如部分依赖的安装结果
+}
+
+// partialOutput 命令失败时随错误一起输出的部分结果
+var partialOutput interface{}
+
+// setPartialOutput 记录命令失败前的部分结果，由 reportError 输出
+func setPartialOutput(data interface{}) {
+	partialOutput = data
+}
+
+// reportError 输出命令的错误：文本模式输出错误和修复建议，结构化模式输出完整的错误链
+func reportError(err error) {
+	if isStructuredOutput() {
+		output := errorOutput{
+			Message:  err.Error(),
+			Code:     errors.Category(err),
+			ExitCode: errors.ExitCode(err),
+			Hint:     errors.Hint(err),
+			Chain:    errors.Chain(err),
+			Partial:  partialOutput,
+		}
+		if writeErr := writeOutput("error", output); writeErr == nil {
+			return
+		}
+	}
+
+	eprintf("Error: %s\n", err)
+	if hint := errors.Hint(err); hint != "" {
+		eprintf("Hint: %s\n", hint)
+	}
+}
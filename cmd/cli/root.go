@@ -1,7 +1,10 @@
 package cli
 
 import (
-	"fmt"
+	"os"
+
+	"buildfly/internal/errors"
+	"buildfly/internal/logging"
 
 	"github.com/spf13/cobra"
 )
@@ -18,23 +21,50 @@ var rootCmd = &cobra.Command{
 - 多种构建系统支持（CMake、Make、Configure、自定义脚本）
 - 依赖缓存和版本管理
 - 跨平台支持`,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		// 初始化全局设置
-		if GlobalCLIContext.GlobalOptions.Verbose {
-			fmt.Println("Verbose mode enabled")
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// 参数解析成功后的错误不再打印用法
+		cmd.SilenceUsage = true
+
+		options := GlobalCLIContext.GlobalOptions
+
+		// 结构化输出时进度信息输出到标准错误
+		if err := setupOutput(options.Output); err != nil {
+			return err
+		}
+
+		// 初始化日志
+		if err := GlobalCLIContext.InitLogger(); err != nil {
+			return err
+		}
+
+		// 安静模式下不输出进度信息，警告和错误由日志记录器输出到标准错误
+		if options.Quiet {
+			if devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0); err == nil {
+				logging.SetConsole(devNull)
+			}
 		}
 
 		// 初始化上下文
 		if err := GlobalCLIContext.Initialize(); err != nil {
-			fmt.Printf("Failed to initialize CLI context: %v\n", err)
+			GlobalCLIContext.Logger.Debug("Failed to initialize CLI context", "error", err)
 		}
+		return nil
 	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
-func Execute() error {
-	return rootCmd.Execute()
+// 返回进程退出码，错误按 internal/errors 的类别映射为不同的退出码
+func Execute() int {
+	defer GlobalCLIContext.Close()
+
+	// 错误由 reportError 统一输出
+	rootCmd.SilenceErrors = true
+	err := rootCmd.Execute()
+	if err != nil {
+		reportError(err)
+	}
+	return errors.ExitCode(err)
 }
 
 func init() {
@@ -43,13 +73,22 @@ func init() {
 	rootCmd.PersistentFlags().BoolVarP(&GlobalCLIContext.GlobalOptions.Verbose, "verbose", "v", false, "详细输出")
 	rootCmd.PersistentFlags().StringVar(&GlobalCLIContext.GlobalOptions.CacheDir, "cache-dir", "", "缓存目录路径")
 	rootCmd.PersistentFlags().StringVar(&GlobalCLIContext.GlobalOptions.MaxCacheAge, "max-cache-age", "7d", "最大缓存时间")
+	rootCmd.PersistentFlags().StringVar(&GlobalCLIContext.GlobalOptions.Output, "output", outputFormatText, "输出格式 (text, json, yaml)，用于 detect、list、install")
+	rootCmd.PersistentFlags().StringVar(&GlobalCLIContext.GlobalOptions.LogLevel, "log-level", "", "日志级别 (debug, info, warn, error)，默认 info，--verbose 时为 debug")
+	rootCmd.PersistentFlags().BoolVarP(&GlobalCLIContext.GlobalOptions.Quiet, "quiet", "q", false, "只输出警告和错误")
+	rootCmd.PersistentFlags().StringVar(&GlobalCLIContext.GlobalOptions.LogFile, "log-file", "", "将日志写入文件")
+	rootCmd.PersistentFlags().StringVar(&GlobalCLIContext.GlobalOptions.LogFormat, "log-format", "text", "日志格式 (text, json)")
 
 	// 添加子命令
 	rootCmd.AddCommand(newInstallCmd())
+	rootCmd.AddCommand(newDevCmd())
+	rootCmd.AddCommand(newLockCmd())
+	rootCmd.AddCommand(newTestCmd())
 	rootCmd.AddCommand(newBuildCmd())
 	rootCmd.AddCommand(newCleanCmd())
 	rootCmd.AddCommand(newInitCmd())
 	rootCmd.AddCommand(newListCmd())
+	rootCmd.AddCommand(newCacheCmd())
 	rootCmd.AddCommand(newConfigCmd())
 	rootCmd.AddCommand(newDetectCmd())
 	rootCmd.AddCommand(newVenvCmd())
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"buildfly/pkg/cache"
+	"buildfly/pkg/config"
+)
+
+func TestRecordSourceSignature(t *testing.T) {
+	cacheManager := cache.NewCacheManager(t.TempDir(), 0, 0)
+	previous := GlobalCLIContext.CacheManager
+	GlobalCLIContext.CacheManager = cacheManager
+	t.Cleanup(func() { GlobalCLIContext.CacheManager = previous })
+
+	dep := config.Dependency{Name: "openssl", Version: "3.0.0", Source: config.SourceInfo{
+		Type:      "archive",
+		URLS:      []string{"https://example.com/openssl-3.0.0.tar.gz"},
+		Signature: "https://example.com/openssl-3.0.0.tar.gz.asc",
+	}}
+	buildTag := &config.BuildTag{BuildType: "Release"}
+
+	// 下载缓存存在但没有签名记录时不使用缓存，重新下载并校验
+	if err := os.MkdirAll(cacheManager.GetDownloadCachePath(dep), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if cacheManager.IsCachedDownloads(dep) {
+		t.Error("download without verified signature must not be used from cache")
+	}
+
+	signature := &cache.SourceSignature{
+		Signature:   dep.Source.Signature,
+		Type:        config.SignatureOpenPGP,
+		Key:         "openssl",
+		Fingerprint: "BA5473A2B0587B07FB27CF2D216094DFD0CB81EF",
+		Signer:      "OpenSSL <openssl@openssl.org>",
+		VerifiedAt:  time.Now(),
+	}
+	if err := cacheManager.RecordDownloadSignature(dep, signature); err != nil {
+		t.Fatal(err)
+	}
+	if !cacheManager.IsCachedDownloads(dep) {
+		t.Error("download with verified signature should be cached")
+	}
+
+	recordSourceSignature(dep, buildTag)
+	metadata, err := cacheManager.LoadBuildMetadata(dep, buildTag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.Signature == nil || metadata.Signature.Fingerprint != signature.Fingerprint || metadata.Signature.Key != "openssl" {
+		t.Errorf("build metadata signature = %+v, want %+v", metadata.Signature, signature)
+	}
+
+	// 签名文件变化后需要重新校验
+	changed := dep
+	changed.Source.Signature = "https://example.com/openssl-3.0.0.tar.gz.sig"
+	if cacheManager.DownloadSignature(changed) != nil || cacheManager.IsCachedDownloads(changed) {
+		t.Error("signature recorded for another signature file must not be used")
+	}
+
+	// 不再配置签名时清除构建元数据中的记录
+	unsigned := dep
+	unsigned.Source.Signature = ""
+	recordSourceSignature(unsigned, buildTag)
+	metadata, err = cacheManager.LoadBuildMetadata(dep, buildTag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.Signature != nil {
+		t.Errorf("build metadata signature = %+v, want nil", metadata.Signature)
+	}
+}
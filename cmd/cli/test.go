@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"buildfly/internal/logging"
+	"buildfly/pkg/builder"
+	"buildfly/pkg/cache"
+	"buildfly/pkg/config"
+
+	"github.com/spf13/cobra"
+)
+
+// newTestCmd 创建 test 命令
+func newTestCmd() *cobra.Command {
+	var (
+		profile  string
+		buildTag string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "test [dependency...]",
+		Short: "运行依赖的测试",
+		Long: `在依赖的构建目录中运行测试。
+
+优先使用依赖配置中的 build_commands.test，否则根据构建系统使用默认命令：
+  cmake:          ctest
+  make/configure: make check
+
+依赖需要先通过 'buildfly install' 构建，测试结果会记录到构建元数据中，
+可以通过 'buildfly list --verbose' 查看。`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTest(args, profile, buildTag)
+		},
+	}
+
+	cmd.Flags().StringVarP(&profile, "profile", "p", "", "构建配置文件")
+	cmd.Flags().StringVar(&buildTag, "build-tag", "", "构建标签 (例如: arch=x86_64,platform=linux,runtime=glibc_2.35)")
+
+	return cmd
+}
+
+// runTest 执行依赖测试
+func runTest(deps []string, profile, buildTag string) error {
+	// 确保上下文已初始化
+	if err := GlobalCLIContext.Initialize(); err != nil {
+		return fmt.Errorf("failed to initialize context: %w", err)
+	}
+
+	projectConfig := GlobalCLIContext.ProjectConfig
+
+	parsedBuildTag, err := resolveBuildTag(buildTag)
+	if err != nil {
+		return err
+	}
+	projectConfig.BuildTag = parsedBuildTag
+
+	dependenciesToTest, err := resolveDependencies(deps, profile)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, dep := range dependenciesToTest {
+		if dep.BuildSystem == "" || dep.BuildSystem == "none" {
+			continue
+		}
+
+		printf("Testing %s (%s)...\n", dep.Name, dep.Version)
+
+		buildDir := getDepBuildDir(dep, parsedBuildTag)
+		if _, err := os.Stat(buildDir); err != nil {
+			printf("  Build directory not found, run 'buildfly install %s' first\n", dep.Name)
+			failed++
+			continue
+		}
+
+		varCtx := config.NewVariableContext(projectConfig.Project, dep.Name)
+		varCtx.SetBuildTag(parsedBuildTag)
+		varCtx.InstallDir = getDepInstallDir(dep, parsedBuildTag)
+		if err := applyToolchain(varCtx, parsedBuildTag); err != nil {
+			return err
+		}
+		executor := newBuildExecutor(varCtx)
+
+		if err := runDependencyTests(executor, dep, depSourceDir(dep, buildDir), buildDir, parsedBuildTag); err != nil {
+			printf("  %v\n", err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("tests failed for %d dependencies", failed)
+	}
+	return nil
+}
+
+// runDependencyTests 运行单个依赖的测试并记录结果到构建元数据
+func runDependencyTests(executor *builder.BuildExecutor, dep config.Dependency, sourceDir, buildDir string, buildTag *config.BuildTag) error {
+	printf("  Running tests for %s...\n", dep.Name)
+
+	start := time.Now()
+	command, skipped, err := executor.RunTests(dep, sourceDir, buildDir)
+	result := &cache.TestResult{
+		Command:  command,
+		Duration: time.Since(start),
+		RunAt:    start,
+	}
+	activeTimings.record(phaseTest, result.Duration)
+
+	switch {
+	case err != nil:
+		result.Status = cache.TestStatusFailed
+		result.Error = logging.Redact(err.Error())
+	case skipped:
+		result.Status = cache.TestStatusSkipped
+	default:
+		result.Status = cache.TestStatusPassed
+	}
+
+	if recordErr := GlobalCLIContext.CacheManager.RecordTestResult(dep, buildTag, result); recordErr != nil {
+		GlobalCLIContext.Logger.Warn("Failed to record test result", "dependency", dep.Name, "error", recordErr)
+	}
+
+	switch result.Status {
+	case cache.TestStatusFailed:
+		return fmt.Errorf("tests failed for %s: %w", dep.Name, err)
+	case cache.TestStatusSkipped:
+		printf("  - No tests found for %s, skipped\n", dep.Name)
+	default:
+		printf("  ✓ Tests passed for %s (%s)\n", dep.Name, result.Duration.Round(time.Millisecond))
+	}
+
+	return nil
+}
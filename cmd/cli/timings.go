@@ -0,0 +1,391 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"buildfly/pkg/builder"
+	"buildfly/pkg/config"
+)
+
+// 安装阶段，configure/build/install 由构建执行器报告
+const (
+	phaseDownload     = "download"
+	phaseExtract      = "extract"
+	phaseConfigure    = builder.PhaseConfigure
+	phaseBuild        = builder.PhaseBuild
+	phaseInstall      = builder.PhaseInstall
+	phaseTest         = "test"
+	phaseLink         = "link"
+	phaseCacheStore   = "cache_store"
+	phaseCacheRestore = "cache_restore"
+)
+
+// timingPhases 报告中阶段的显示顺序
+var timingPhases = []string{
+	phaseCacheRestore, phaseDownload, phaseExtract,
+	phaseConfigure, phaseBuild, phaseInstall, phaseTest,
+	phaseCacheStore, phaseLink,
+}
+
+// buildPhases 计入构建时间的阶段
+var buildPhases = []string{phaseConfigure, phaseBuild, phaseInstall}
+
+// phaseTiming 单个阶段的耗时
+type phaseTiming struct {
+	Phase      string `json:"phase"`
+	DurationMS int64  `json:"duration_ms"`
+
+	duration time.Duration
+}
+
+// dependencyTiming 单个依赖的耗时统计
+type dependencyTiming struct {
+	Dependency    string        `json:"dependency"`
+	Version       string        `json:"version"`
+	Status        string        `json:"status"`
+	Source        string        `json:"source,omitempty"`
+	DurationMS    int64         `json:"duration_ms"`
+	DownloadBytes int64         `json:"download_bytes"`
+	Phases        []phaseTiming `json:"phases"`
+}
+
+// phase 获取阶段的耗时，未记录时返回 0
+func (dt *dependencyTiming) phase(name string) time.Duration {
+	for _, phase := range dt.Phases {
+		if phase.Phase == name {
+			return phase.duration
+		}
+	}
+	return 0
+}
+
+// installTimings 记录一次安装中每个依赖各阶段的耗时和下载的字节数
+// 方法对 nil 接收者安全，未启用记录时不做任何事
+type installTimings struct {
+	mu           sync.Mutex
+	startedAt    time.Time
+	current      *dependencyTiming
+	dependencies []*dependencyTiming
+}
+
+// activeTimings 当前安装使用的耗时记录器
+var activeTimings *installTimings
+
+// newInstallTimings 创建耗时记录器
+func newInstallTimings() *installTimings {
+	return &installTimings{startedAt: time.Now()}
+}
+
+// begin 开始记录一个依赖
+func (t *installTimings) begin(dep config.Dependency) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.current = &dependencyTiming{Dependency: dep.Name, Version: dep.Version}
+	t.dependencies = append(t.dependencies, t.current)
+}
+
+// finish 结束当前依赖的记录
+func (t *installTimings) finish(result installResult) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.current == nil {
+		return
+	}
+	t.current.Status = result.Status
+	t.current.Source = result.Source
+	t.current.DurationMS = result.DurationMS
+	t.current = nil
+}
+
+// record 累加当前依赖某个阶段的耗时，同一阶段多次执行时合并
+func (t *installTimings) record(phase string, duration time.Duration) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.current == nil {
+		return
+	}
+	for i := range t.current.Phases {
+		if t.current.Phases[i].Phase == phase {
+			t.current.Phases[i].duration += duration
+			t.current.Phases[i].DurationMS = t.current.Phases[i].duration.Milliseconds()
+			return
+		}
+	}
+	t.current.Phases = append(t.current.Phases, phaseTiming{Phase: phase, DurationMS: duration.Milliseconds(), duration: duration})
+}
+
+// addBytes 累加当前依赖下载的字节数
+func (t *installTimings) addBytes(n int64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.current != nil {
+		t.current.DownloadBytes += n
+	}
+}
+
+// measure 执行 fn 并记录为指定阶段的耗时
+func (t *installTimings) measure(phase string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	t.record(phase, time.Since(start))
+	return err
+}
+
+// resolution 汇总下载大小和构建时间
+func (t *installTimings) resolution(deps []config.Dependency, buildTag *config.BuildTag) *config.ResolutionResult {
+	result := &config.ResolutionResult{}
+	for _, dep := range deps {
+		result.Dependencies = append(result.Dependencies, config.ResolvedDependency{
+			Dependency:  dep,
+			InstallPath: getDepInstallDir(dep, buildTag),
+		})
+	}
+	if t == nil {
+		return result
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, timing := range t.dependencies {
+		result.DownloadSize += timing.DownloadBytes
+		for _, phase := range buildPhases {
+			result.BuildTime += timing.phase(phase)
+		}
+	}
+	return result
+}
+
+// timingsReport 耗时报告
+type timingsReport struct {
+	BuildTag     string              `json:"build_tag"`
+	StartedAt    time.Time           `json:"started_at"`
+	TotalMS      int64               `json:"total_ms"`
+	BuildTimeMS  int64               `json:"build_time_ms"`
+	DownloadSize int64               `json:"download_size"`
+	Dependencies []*dependencyTiming `json:"dependencies"`
+}
+
+// report 生成耗时报告
+func (t *installTimings) report(resolution *config.ResolutionResult, buildTag *config.BuildTag) timingsReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return timingsReport{
+		BuildTag:     buildTag.String(),
+		StartedAt:    t.startedAt,
+		TotalMS:      time.Since(t.startedAt).Milliseconds(),
+		BuildTimeMS:  resolution.BuildTime.Milliseconds(),
+		DownloadSize: resolution.DownloadSize,
+		Dependencies: t.dependencies,
+	}
+}
+
+// printTimings 打印每个依赖各阶段耗时的表格，只显示至少一个依赖用到的阶段
+func printTimings(report timingsReport) {
+	var phases []string
+	for _, phase := range timingPhases {
+		for _, timing := range report.Dependencies {
+			if timing.phase(phase) > 0 {
+				phases = append(phases, phase)
+				break
+			}
+		}
+	}
+
+	nameWidth := len("DEPENDENCY")
+	for _, timing := range report.Dependencies {
+		if len(timing.Dependency) > nameWidth {
+			nameWidth = len(timing.Dependency)
+		}
+	}
+
+	printf("\nTimings:\n")
+	printf("%-*s  %-9s", nameWidth, "DEPENDENCY", "SOURCE")
+	for _, phase := range phases {
+		printf("  %13s", phase)
+	}
+	printf("  %9s  %10s\n", "TOTAL", "DOWNLOADED")
+	printLine(strings.Repeat("-", nameWidth+11+len(phases)*15+23))
+
+	for _, timing := range report.Dependencies {
+		printf("%-*s  %-9s", nameWidth, timing.Dependency, valueOrDash(timing.Source))
+		for _, phase := range phases {
+			printf("  %13s", formatPhaseDuration(timing.phase(phase)))
+		}
+		printf("  %9s  %10s\n", formatPhaseDuration(time.Duration(timing.DurationMS)*time.Millisecond), formatBytes(timing.DownloadBytes))
+	}
+
+	printf("\nTotal %s, build %s, downloaded %s\n",
+		formatPhaseDuration(time.Duration(report.TotalMS)*time.Millisecond),
+		formatPhaseDuration(time.Duration(report.BuildTimeMS)*time.Millisecond),
+		formatBytes(report.DownloadSize))
+}
+
+// formatPhaseDuration 格式化阶段耗时，未执行的阶段显示为 "-"
+func formatPhaseDuration(d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
+
+// writeTimingsReport 将耗时报告写入 timings.json 和 timings.html，返回 JSON 报告路径
+func writeTimingsReport(dir string, report timingsReport) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create timings report directory: %w", err)
+	}
+
+	content, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode timings report: %w", err)
+	}
+	jsonPath := filepath.Join(dir, "timings.json")
+	if err := os.WriteFile(jsonPath, append(content, '\n'), 0644); err != nil {
+		return "", fmt.Errorf("failed to write timings report: %w", err)
+	}
+
+	htmlFile, err := os.Create(filepath.Join(dir, "timings.html"))
+	if err != nil {
+		return "", fmt.Errorf("failed to write timings report: %w", err)
+	}
+	defer htmlFile.Close()
+	if err := timingsHTMLTemplate.Execute(htmlFile, newTimingsHTMLData(report)); err != nil {
+		return "", fmt.Errorf("failed to write timings report: %w", err)
+	}
+
+	return jsonPath, nil
+}
+
+// timingsHTMLRow HTML 报告中的一行
+type timingsHTMLRow struct {
+	Dependency string
+	Version    string
+	Source     string
+	Total      string
+	Downloaded string
+	Bars       []timingsHTMLBar
+}
+
+// timingsHTMLBar HTML 报告中某个阶段的耗时条
+type timingsHTMLBar struct {
+	Phase    string
+	Duration string
+	Percent  float64 // 占所有依赖中最长耗时的百分比
+}
+
+// timingsHTMLData HTML 报告的模板数据
+type timingsHTMLData struct {
+	Report timingsReport
+	Total  string
+	Build  string
+	Size   string
+	Phases []string
+	Rows   []timingsHTMLRow
+}
+
+// newTimingsHTMLData 将耗时报告转换为 HTML 模板数据
+func newTimingsHTMLData(report timingsReport) timingsHTMLData {
+	data := timingsHTMLData{
+		Report: report,
+		Total:  formatPhaseDuration(time.Duration(report.TotalMS) * time.Millisecond),
+		Build:  formatPhaseDuration(time.Duration(report.BuildTimeMS) * time.Millisecond),
+		Size:   formatBytes(report.DownloadSize),
+		Phases: timingPhases,
+	}
+
+	var longest time.Duration
+	for _, timing := range report.Dependencies {
+		var sum time.Duration
+		for _, phase := range timing.Phases {
+			sum += phase.duration
+		}
+		if sum > longest {
+			longest = sum
+		}
+	}
+
+	for _, timing := range report.Dependencies {
+		row := timingsHTMLRow{
+			Dependency: timing.Dependency,
+			Version:    timing.Version,
+			Source:     valueOrDash(timing.Source),
+			Total:      formatPhaseDuration(time.Duration(timing.DurationMS) * time.Millisecond),
+			Downloaded: formatBytes(timing.DownloadBytes),
+		}
+		for _, phase := range timingPhases {
+			duration := timing.phase(phase)
+			if duration <= 0 || longest <= 0 {
+				continue
+			}
+			row.Bars = append(row.Bars, timingsHTMLBar{
+				Phase:    phase,
+				Duration: formatPhaseDuration(duration),
+				Percent:  float64(duration) / float64(longest) * 100,
+			})
+		}
+		data.Rows = append(data.Rows, row)
+	}
+	return data
+}
+
+// timingsHTMLTemplate HTML 耗时报告模板，每个依赖一行，按阶段显示堆叠的耗时条
+var timingsHTMLTemplate = template.Must(template.New("timings").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>buildfly install timings</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { padding: 4px 8px; border-bottom: 1px solid #ddd; text-align: left; white-space: nowrap; }
+td.bars { width: 60%; }
+.bar { display: inline-block; height: 14px; }
+.legend span { display: inline-block; margin-right: 1em; }
+.legend i { display: inline-block; width: 12px; height: 12px; margin-right: 4px; vertical-align: middle; }
+.cache_restore { background: #9e9e9e; } .download { background: #42a5f5; } .extract { background: #26c6da; }
+.configure { background: #ffca28; } .build { background: #ef5350; } .install { background: #ab47bc; }
+.test { background: #8d6e63; } .cache_store { background: #66bb6a; } .link { background: #78909c; }
+</style>
+</head>
+<body>
+<h1>Install timings</h1>
+<p>Build tag: <code>{{.Report.BuildTag}}</code><br>
+Started: {{.Report.StartedAt.Format "2006-01-02 15:04:05"}}<br>
+Total: {{.Total}}, build: {{.Build}}, downloaded: {{.Size}}</p>
+<p class="legend">{{range .Phases}}<span><i class="{{.}}"></i>{{.}}</span>{{end}}</p>
+<table>
+<tr><th>Dependency</th><th>Version</th><th>Source</th><th>Total</th><th>Downloaded</th><th>Phases</th></tr>
+{{range .Rows}}<tr>
+<td>{{.Dependency}}</td><td>{{.Version}}</td><td>{{.Source}}</td><td>{{.Total}}</td><td>{{.Downloaded}}</td>
+<td class="bars">{{range .Bars}}<span class="bar {{.Phase}}" style="width: {{printf "%.2f" .Percent}}%" title="{{.Phase}}: {{.Duration}}"></span>{{end}}</td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
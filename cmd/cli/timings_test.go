@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"buildfly/pkg/config"
+)
+
+func TestInstallTimings(t *testing.T) {
+	tempDir := t.TempDir()
+	previous := GlobalCLIContext.ProjectConfig
+	GlobalCLIContext.ProjectConfig = &config.ProjectConfig{InstallDir: filepath.Join(tempDir, "install")}
+	t.Cleanup(func() { GlobalCLIContext.ProjectConfig = previous })
+
+	zlib := config.Dependency{Name: "zlib", Version: "1.3"}
+	fmtlib := config.Dependency{Name: "fmt", Version: "10.2.1"}
+
+	timings := newInstallTimings()
+	timings.begin(zlib)
+	timings.record(phaseDownload, 2*time.Second)
+	timings.addBytes(1024)
+	timings.record(phaseConfigure, time.Second)
+	timings.record(phaseBuild, 3*time.Second)
+	timings.record(phaseBuild, time.Second) // 同一阶段多次执行时合并
+	timings.record(phaseInstall, 500*time.Millisecond)
+	timings.finish(installResult{Status: installStatusInstalled, Source: installSourceBuilt, DurationMS: 8000})
+
+	timings.begin(fmtlib)
+	timings.record(phaseCacheRestore, 200*time.Millisecond)
+	timings.finish(installResult{Status: installStatusInstalled, Source: installSourceCache, DurationMS: 300})
+
+	// 没有进行中的依赖时忽略记录
+	timings.record(phaseBuild, time.Hour)
+
+	resolution := timings.resolution([]config.Dependency{zlib, fmtlib}, nil)
+	if resolution.DownloadSize != 1024 {
+		t.Errorf("DownloadSize = %d, want 1024", resolution.DownloadSize)
+	}
+	if resolution.BuildTime != 5500*time.Millisecond {
+		t.Errorf("BuildTime = %v, want 5.5s", resolution.BuildTime)
+	}
+	if len(resolution.Dependencies) != 2 || resolution.Dependencies[0].InstallPath == "" {
+		t.Errorf("unexpected resolved dependencies: %+v", resolution.Dependencies)
+	}
+
+	report := timings.report(resolution, &config.BuildTag{Arch: "x86_64"})
+	if _, err := writeTimingsReport(tempDir, report); err != nil {
+		t.Fatalf("writeTimingsReport() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "timings.json"))
+	if err != nil {
+		t.Fatalf("failed to read JSON report: %v", err)
+	}
+	var decoded timingsReport
+	if err := json.Unmarshal(content, &decoded); err != nil {
+		t.Fatalf("invalid JSON report: %v", err)
+	}
+	if decoded.BuildTimeMS != 5500 || len(decoded.Dependencies) != 2 {
+		t.Errorf("unexpected report: %+v", decoded)
+	}
+	if phases := decoded.Dependencies[0].Phases; len(phases) != 4 || phases[1].Phase != phaseConfigure || phases[2].DurationMS != 4000 {
+		t.Errorf("unexpected phases: %+v", phases)
+	}
+
+	html, err := os.ReadFile(filepath.Join(tempDir, "timings.html"))
+	if err != nil {
+		t.Fatalf("failed to read HTML report: %v", err)
+	}
+	for _, want := range []string{"zlib", "arch=x86_64", `class="bar build"`} {
+		if !strings.Contains(string(html), want) {
+			t.Errorf("HTML report missing %q", want)
+		}
+	}
+}
+
+func TestInstallTimingsNil(t *testing.T) {
+	// 未启用记录时所有方法都不做任何事
+	var timings *installTimings
+	timings.begin(config.Dependency{Name: "zlib"})
+	timings.record(phaseBuild, time.Second)
+	timings.addBytes(1)
+	if err := timings.measure(phaseLink, func() error { return nil }); err != nil {
+		t.Errorf("measure() error = %v", err)
+	}
+}
@@ -20,7 +20,7 @@ func newVenvCmd() *cobra.Command {
 		PersistentPreRun: func(cmd *cobra.Command, args []string) {
 			// 确保上下文已初始化
 			if err := GlobalCLIContext.Initialize(); err != nil {
-				fmt.Printf("Failed to initialize CLI context: %v\n", err)
+				printf("Failed to initialize CLI context: %v\n", err)
 				os.Exit(1)
 			}
 		},
@@ -217,11 +217,11 @@ func runVenvInit(force bool, uvVersion string, rootDir string, backend string) e
 		venvConfig.Backend = venv.BackendType(backend)
 	}
 
-	fmt.Printf("Initializing virtual environment with backend %s in projectRoot:%s venvRoot:%s\n",
+	printf("Initializing virtual environment with backend %s in projectRoot:%s venvRoot:%s\n",
 		venvConfig.Backend, projectRoot, venvConfig.RootDir)
 
 	// 检查是否已初始化
-	manager, err := venv.NewManager(venvConfig, projectRoot)
+	manager, err := newVenvManager(venvConfig, projectRoot)
 	if err != nil {
 		return fmt.Errorf("failed to create venv manager: %w", err)
 	}
@@ -233,8 +233,8 @@ func runVenvInit(force bool, uvVersion string, rootDir string, backend string) e
 		if status.RootDir != "" {
 			// 检查环境目录是否存在
 			if _, err := os.Stat(status.RootDir); err == nil {
-				fmt.Printf("Virtual environment already exists at: %s\n", status.RootDir)
-				fmt.Println("Use --force to reinitialize")
+				printf("Virtual environment already exists at: %s\n", status.RootDir)
+				printLine("Use --force to reinitialize")
 				return nil
 			}
 		}
@@ -247,9 +247,9 @@ func runVenvInit(force bool, uvVersion string, rootDir string, backend string) e
 
 	// 显示激活脚本路径
 	activationScript := manager.GetActivationScript()
-	fmt.Printf("\nTo activate the environment, run:\n")
-	fmt.Printf("  source %s\n", activationScript)
-	fmt.Printf("Or use: buildfly venv activate\n")
+	printf("\nTo activate the environment, run:\n")
+	printf("  source %s\n", activationScript)
+	printf("Or use: buildfly venv activate\n")
 
 	return nil
 }
@@ -313,7 +313,7 @@ func runVenvRun(args []string) error {
 	}
 
 	// 创建管理器
-	manager, err := venv.NewManager(venvConfig, projectRoot)
+	manager, err := newVenvManager(venvConfig, projectRoot)
 	if err != nil {
 		return fmt.Errorf("failed to create venv manager: %w", err)
 	}
@@ -343,7 +343,7 @@ func runVenvActivate(shell bool) error {
 		projectRoot = cwd
 	}
 
-	manager, err := venv.NewManager(venvConfig, projectRoot)
+	manager, err := newVenvManager(venvConfig, projectRoot)
 	if err != nil {
 		return fmt.Errorf("failed to create venv manager: %w", err)
 	}
@@ -366,11 +366,11 @@ func runVenvActivate(shell bool) error {
 
 	// 显示环境信息
 	status := manager.GetStatus()
-	fmt.Printf("Environment activated successfully!\n")
-	fmt.Printf("Root: %s\n", status.RootDir)
-	fmt.Printf("Backend: %s\n", venvConfig.Backend)
+	printf("Environment activated successfully!\n")
+	printf("Root: %s\n", status.RootDir)
+	printf("Backend: %s\n", venvConfig.Backend)
 	if venvConfig.Backend == "uv" && status.UVInstalled {
-		fmt.Printf("UV Version: %s\n", status.UVVersion)
+		printf("UV Version: %s\n", status.UVVersion)
 	}
 
 	return nil
@@ -381,7 +381,7 @@ func runVenvDeactivate() error {
 	// 检查是否有激活的环境
 	envRoot := os.Getenv("BUILDFLY_ENV_ROOT")
 	if envRoot == "" {
-		fmt.Println("No active virtual environment found")
+		printLine("No active virtual environment found")
 		return nil
 	}
 
@@ -390,7 +390,7 @@ func runVenvDeactivate() error {
 		RootDir: envRoot,
 	}
 
-	manager, err := venv.NewManager(venvConfig, filepath.Dir(envRoot))
+	manager, err := newVenvManager(venvConfig, filepath.Dir(envRoot))
 	if err != nil {
 		return fmt.Errorf("failed to create venv manager: %w", err)
 	}
@@ -419,38 +419,38 @@ func runVenvStatus(verbose bool, jsonOutput bool) error {
 	// 如果没有配置，显示未配置状态
 	if venvConfig == nil {
 		if jsonOutput {
-			fmt.Println(`{"status": "not_configured", "message": "Virtual environment not configured"}`)
+			printLine(`{"status": "not_configured", "message": "Virtual environment not configured"}`)
 		} else {
-			fmt.Println("Virtual environment not configured")
-			fmt.Println("Use 'buildfly venv init' to initialize")
+			printLine("Virtual environment not configured")
+			printLine("Use 'buildfly venv init' to initialize")
 		}
 		return nil
 	}
 
 	// 在详细模式下显示配置信息
 	if verbose {
-		fmt.Printf("Configuration:\n")
-		fmt.Printf("  Backend: %s\n", venvConfig.Backend)
-		fmt.Printf("  Enabled: %t\n", venvConfig.Enabled)
+		printf("Configuration:\n")
+		printf("  Backend: %s\n", venvConfig.Backend)
+		printf("  Enabled: %t\n", venvConfig.Enabled)
 		if venvConfig.RootDir != "" {
-			fmt.Printf("  Root Directory: %s\n", venvConfig.RootDir)
+			printf("  Root Directory: %s\n", venvConfig.RootDir)
 		}
 		if venvConfig.UVVersion != "" {
-			fmt.Printf("  UV Version: %s\n", venvConfig.UVVersion)
+			printf("  UV Version: %s\n", venvConfig.UVVersion)
 		}
-		fmt.Printf("  Auto Activate: %t\n", venvConfig.AutoActivate)
+		printf("  Auto Activate: %t\n", venvConfig.AutoActivate)
 
 		// 显示 C++ 工具配置
-		fmt.Printf("  C++ Tools:\n")
-		fmt.Printf("    CMake: enabled=%t, version=%s\n", venvConfig.CPPTools.CMake.Enabled, venvConfig.CPPTools.CMake.Version)
-		fmt.Printf("    Ninja: enabled=%t, version=%s\n", venvConfig.CPPTools.Ninja.Enabled, venvConfig.CPPTools.Ninja.Version)
+		printf("  C++ Tools:\n")
+		printf("    CMake: enabled=%t, version=%s\n", venvConfig.CPPTools.CMake.Enabled, venvConfig.CPPTools.CMake.Version)
+		printf("    Ninja: enabled=%t, version=%s\n", venvConfig.CPPTools.Ninja.Enabled, venvConfig.CPPTools.Ninja.Version)
 
 		// 显示 Python 配置
-		fmt.Printf("  Python: version=%s, packages=%v\n", venvConfig.Python.Version, venvConfig.Python.Packages)
-		fmt.Printf("\n")
+		printf("  Python: version=%s, packages=%v\n", venvConfig.Python.Version, venvConfig.Python.Packages)
+		printf("\n")
 	}
 
-	manager, err := venv.NewManager(venvConfig, projectRoot)
+	manager, err := newVenvManager(venvConfig, projectRoot)
 	if err != nil {
 		return fmt.Errorf("failed to create venv manager: %w", err)
 	}
@@ -460,44 +460,44 @@ func runVenvStatus(verbose bool, jsonOutput bool) error {
 	if jsonOutput {
 		// JSON 输出
 		if venvConfig.Backend == "uv" {
-			fmt.Printf(`{"status": "ok", "backend": "uv", "root_dir": "%s", "activated": %t, "uv_installed": %t, "uv_version": "%s"}`,
+			printf(`{"status": "ok", "backend": "uv", "root_dir": "%s", "activated": %t, "uv_installed": %t, "uv_version": "%s"}`,
 				status.RootDir, status.Activated, status.UVInstalled, status.UVVersion)
 		} else if venvConfig.Backend == "pixi" {
-			fmt.Printf(`{"status": "ok", "backend": "pixi", "root_dir": "%s", "activated": %t, "pixi_installed": %t, "pixi_version": "%s"}`,
+			printf(`{"status": "ok", "backend": "pixi", "root_dir": "%s", "activated": %t, "pixi_installed": %t, "pixi_version": "%s"}`,
 				status.RootDir, status.Activated, status.PixiInstalled, status.PixiVersion)
 		} else {
-			fmt.Printf(`{"status": "ok", "backend": "%s", "root_dir": "%s", "activated": %t}`,
+			printf(`{"status": "ok", "backend": "%s", "root_dir": "%s", "activated": %t}`,
 				venvConfig.Backend, status.RootDir, status.Activated)
 		}
 	} else {
 		// 人类可读输出
-		fmt.Printf("Virtual Environment Status:\n")
-		fmt.Printf("  Backend: %s\n", venvConfig.Backend)
-		fmt.Printf("  Root Directory: %s\n", status.RootDir)
-		fmt.Printf("  Activated: %t\n", status.Activated)
+		printf("Virtual Environment Status:\n")
+		printf("  Backend: %s\n", venvConfig.Backend)
+		printf("  Root Directory: %s\n", status.RootDir)
+		printf("  Activated: %t\n", status.Activated)
 
 		if venvConfig.Backend == "uv" {
-			fmt.Printf("  UV Installed: %t\n", status.UVInstalled)
+			printf("  UV Installed: %t\n", status.UVInstalled)
 			if status.UVInstalled {
-				fmt.Printf("  UV Version: %s\n", status.UVVersion)
+				printf("  UV Version: %s\n", status.UVVersion)
 			}
 		} else if venvConfig.Backend == "pixi" {
-			fmt.Printf("  Pixi Installed: %t\n", status.PixiInstalled)
+			printf("  Pixi Installed: %t\n", status.PixiInstalled)
 			if status.PixiInstalled {
-				fmt.Printf("  Pixi Version: %s\n", status.PixiVersion)
+				printf("  Pixi Version: %s\n", status.PixiVersion)
 			}
 		}
 
-		fmt.Printf("  Python Version: %s\n", status.PythonVersion)
-		fmt.Printf("  Created: %s\n", status.CreatedAt.Format("2006-01-02 15:04:05"))
+		printf("  Python Version: %s\n", status.PythonVersion)
+		printf("  Created: %s\n", status.CreatedAt.Format("2006-01-02 15:04:05"))
 		if status.Activated {
-			fmt.Printf("  Last Activated: %s\n", status.LastActivated.Format("2006-01-02 15:04:05"))
+			printf("  Last Activated: %s\n", status.LastActivated.Format("2006-01-02 15:04:05"))
 		}
 
 		if verbose && len(status.InstalledTools) > 0 {
-			fmt.Printf("\nInstalled Tools:\n")
+			printf("\nInstalled Tools:\n")
 			for name, version := range status.InstalledTools {
-				fmt.Printf("  %s: %s\n", name, version)
+				printf("  %s: %s\n", name, version)
 			}
 		}
 	}
@@ -522,7 +522,7 @@ func runVenvReset(force bool) error {
 		projectRoot = cwd
 	}
 
-	manager, err := venv.NewManager(venvConfig, projectRoot)
+	manager, err := newVenvManager(venvConfig, projectRoot)
 	if err != nil {
 		return fmt.Errorf("failed to create venv manager: %w", err)
 	}
@@ -531,17 +531,17 @@ func runVenvReset(force bool) error {
 	status := manager.GetStatus()
 
 	if status.RootDir == "" {
-		fmt.Println("No virtual environment to reset")
+		printLine("No virtual environment to reset")
 		return nil
 	}
 
 	// 确认重置
 	if !force {
-		fmt.Printf("Are you sure you want to reset the virtual environment at %s? [y/N]: ", status.RootDir)
+		printf("Are you sure you want to reset the virtual environment at %s? [y/N]: ", status.RootDir)
 		var response string
 		fmt.Scanln(&response)
 		if response != "y" && response != "Y" {
-			fmt.Println("Reset cancelled")
+			printLine("Reset cancelled")
 			return nil
 		}
 	}
@@ -549,7 +549,7 @@ func runVenvReset(force bool) error {
 	// 停用环境（如果已激活）
 	if manager.IsActivated() {
 		if err := manager.Deactivate(); err != nil {
-			fmt.Printf("Warning: failed to deactivate environment: %v\n", err)
+			GlobalCLIContext.Logger.Warn("Failed to deactivate environment", "error", err)
 		}
 	}
 
@@ -558,7 +558,7 @@ func runVenvReset(force bool) error {
 		return fmt.Errorf("failed to reset virtual environment: %w", err)
 	}
 
-	fmt.Println("Virtual environment reset successfully")
+	printLine("Virtual environment reset successfully")
 	return nil
 }
 
@@ -579,7 +579,7 @@ func runVenvList() error {
 		projectRoot = cwd
 	}
 
-	manager, err := venv.NewManager(venvConfig, projectRoot)
+	manager, err := newVenvManager(venvConfig, projectRoot)
 	if err != nil {
 		return fmt.Errorf("failed to create venv manager: %w", err)
 	}
@@ -587,14 +587,24 @@ func runVenvList() error {
 	status := manager.GetStatus()
 
 	if len(status.InstalledTools) == 0 {
-		fmt.Println("No tools installed yet")
+		printLine("No tools installed yet")
 		return nil
 	}
 
-	fmt.Println("Installed Tools:")
+	printLine("Installed Tools:")
 	for name, version := range status.InstalledTools {
-		fmt.Printf("  %s: %s\n", name, version)
+		printf("  %s: %s\n", name, version)
 	}
 
 	return nil
 }
+
+// newVenvManager 创建虚拟环境管理器，使用 CLI 上下文中的日志记录器
+func newVenvManager(venvConfig *venv.VEnvConfig, projectRoot string) (*venv.Manager, error) {
+	manager, err := venv.NewManager(venvConfig, projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	manager.SetLogger(GlobalCLIContext.Logger)
+	return manager, nil
+}
@@ -1,7 +1,9 @@
 package errors
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 )
 
 // 错误类型定义
@@ -27,6 +29,7 @@ var (
 	ErrInvalidURL       = New("invalid URL")
 	ErrNetworkError     = New("network error")
 	ErrChecksumMismatch = New("checksum mismatch")
+	ErrInvalidSignature = New("invalid signature")
 	ErrFileNotFound     = New("file not found")
 	ErrPermissionDenied = New("permission denied")
 
@@ -54,6 +57,7 @@ type Error struct {
 	Code    string
 	Message string
 	Cause   error
+	Hint    string // 修复建议
 }
 
 // New 创建新的错误
@@ -88,6 +92,13 @@ func WrapWithCode(err error, code, message string) *Error {
 	}
 }
 
+// WithHint 返回带修复建议的错误副本（不修改预定义的错误）
+func (e *Error) WithHint(hint string) *Error {
+	clone := *e
+	clone.Hint = hint
+	return &clone
+}
+
 // Error 实现 error 接口
 func (e *Error) Error() string {
 	if e.Cause != nil {
@@ -139,6 +150,26 @@ func DownloadErrorWithCause(err error, message string) *Error {
 	return WrapWithCode(err, "DOWNLOAD_ERROR", message)
 }
 
+// ChecksumMismatchError 校验和不匹配错误
+func ChecksumMismatchError(message string) *Error {
+	return WrapWithCode(nil, "CHECKSUM_MISMATCH", message)
+}
+
+// ChecksumMismatchErrorWithCause 带原因的校验和不匹配错误
+func ChecksumMismatchErrorWithCause(err error, message string) *Error {
+	return WrapWithCode(err, "CHECKSUM_MISMATCH", message)
+}
+
+// SignatureError 签名校验失败错误
+func SignatureError(message string) *Error {
+	return WrapWithCode(nil, "SIGNATURE_INVALID", message)
+}
+
+// SignatureErrorWithCause 带原因的签名校验失败错误
+func SignatureErrorWithCause(err error, message string) *Error {
+	return WrapWithCode(err, "SIGNATURE_INVALID", message)
+}
+
 // BuildError 构建错误
 func BuildError(message string) *Error {
 	return WrapWithCode(nil, "BUILD_ERROR", message)
@@ -168,3 +199,98 @@ func SystemError(message string) *Error {
 func SystemErrorWithCause(err error, message string) *Error {
 	return WrapWithCode(err, "SYSTEM_ERROR", message)
 }
+
+// 错误类别对应的进程退出码，CI 可以据此区分失败原因
+const (
+	ExitOK         = 0
+	ExitGeneral    = 1 // 未分类的错误（例如命令行参数错误）
+	ExitConfig     = 2
+	ExitDependency = 3
+	ExitDownload   = 4
+	ExitBuild      = 5
+	ExitCache      = 6
+	ExitSystem     = 7
+)
+
+// exitCodes 错误码到退出码的映射
+var exitCodes = map[string]int{
+	"CONFIG_ERROR":      ExitConfig,
+	"DEPENDENCY_ERROR":  ExitDependency,
+	"DOWNLOAD_ERROR":    ExitDownload,
+	"CHECKSUM_MISMATCH": ExitDownload,
+	"SIGNATURE_INVALID": ExitDownload,
+	"BUILD_ERROR":       ExitBuild,
+	"CACHE_ERROR":       ExitCache,
+	"SYSTEM_ERROR":      ExitSystem,
+}
+
+// defaultHints 没有具体修复建议时按类别给出的通用建议
+var defaultHints = map[string]string{
+	"CONFIG_ERROR":      "check buildfly.yaml (and ~/.config/buildfly/config.yaml), run `buildfly config show` to see the effective config",
+	"DEPENDENCY_ERROR":  "check the dependency name and version in buildfly.yaml, run `buildfly list` to see configured dependencies",
+	"DOWNLOAD_ERROR":    "check network access and proxy settings, then retry; add mirror URLs to the dependency source for redundancy",
+	"CHECKSUM_MISMATCH": "upstream may have re-rolled the tarball; verify the new archive, then run `buildfly lock --refresh <dependency>`",
+	"SIGNATURE_INVALID": "do not use the archive; check that source.signature belongs to this release and that the signer's public key is in trusted_keys",
+	"BUILD_ERROR":       "rerun with `--log-level debug` and inspect the build directory under .buildfly/build",
+	"CACHE_ERROR":       "the cache may be stale or corrupted, run `buildfly clean --cache` or retry with `--no-cache`",
+	"SYSTEM_ERROR":      "install the missing tool or run `buildfly detect` to check the toolchain",
+}
+
+// Category 获取错误链中最外层的错误码，未分类时返回空字符串
+func Category(err error) string {
+	for err != nil {
+		if e, ok := err.(*Error); ok && e.Code != "" {
+			return e.Code
+		}
+		err = errors.Unwrap(err)
+	}
+	return ""
+}
+
+// ExitCode 获取错误对应的进程退出码
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	if code, ok := exitCodes[Category(err)]; ok {
+		return code
+	}
+	return ExitGeneral
+}
+
+// Hint 获取错误链中的修复建议，外层（上下文更完整）的建议优先，没有时使用类别的通用建议
+func Hint(err error) string {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if custom, ok := e.(*Error); ok && custom.Hint != "" {
+			return custom.Hint
+		}
+	}
+	return defaultHints[Category(err)]
+}
+
+// ChainEntry 错误链中的一层
+type ChainEntry struct {
+	Message string `json:"message"`
+	Code    string `json:"code,omitempty"`
+	Hint    string `json:"hint,omitempty"`
+}
+
+// Chain 展开错误链，每层只保留该层自己的消息
+func Chain(err error) []ChainEntry {
+	var chain []ChainEntry
+	for err != nil {
+		next := errors.Unwrap(err)
+
+		entry := ChainEntry{Message: err.Error()}
+		if e, ok := err.(*Error); ok {
+			entry = ChainEntry{Message: e.Message, Code: e.Code, Hint: e.Hint}
+		} else if next != nil {
+			// fmt.Errorf("...: %w") 的消息包含下一层的消息
+			entry.Message = strings.TrimSuffix(entry.Message, ": "+next.Error())
+		}
+
+		chain = append(chain, entry)
+		err = next
+	}
+	return chain
+}
@@ -0,0 +1,83 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCategoryAndExitCode(t *testing.T) {
+	cause := ChecksumMismatchError("checksum mismatch for zlib.tar.gz")
+	tests := []struct {
+		name         string
+		err          error
+		wantCategory string
+		wantExitCode int
+	}{
+		{"nil", nil, "", ExitOK},
+		{"plain error", fmt.Errorf("unknown flag"), "", ExitGeneral},
+		{"config", ConfigError("invalid buildfly.yaml"), "CONFIG_ERROR", ExitConfig},
+		{"checksum", cause, "CHECKSUM_MISMATCH", ExitDownload},
+		{"signature", SignatureError("bad signature"), "SIGNATURE_INVALID", ExitDownload},
+		{"outermost code wins", DependencyErrorWithCause(cause, "failed to download zlib"), "DEPENDENCY_ERROR", ExitDependency},
+		{"wrapped by fmt.Errorf", fmt.Errorf("failed to install zlib: %w", BuildError("make failed")), "BUILD_ERROR", ExitBuild},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Category(tt.err); got != tt.wantCategory {
+				t.Errorf("Category() = %q, want %q", got, tt.wantCategory)
+			}
+			if got := ExitCode(tt.err); got != tt.wantExitCode {
+				t.Errorf("ExitCode() = %d, want %d", got, tt.wantExitCode)
+			}
+		})
+	}
+}
+
+func TestHint(t *testing.T) {
+	cause := ChecksumMismatchError("checksum mismatch for zlib.tar.gz").WithHint("run `buildfly lock --refresh zlib`")
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"custom hint", cause, "run `buildfly lock --refresh zlib`"},
+		{"outer hint wins", DependencyErrorWithCause(cause, "failed to download zlib").WithHint("check mirrors"), "check mirrors"},
+		{"inner hint when outer has none", fmt.Errorf("failed to install zlib: %w", DependencyErrorWithCause(cause, "failed to download zlib")), "run `buildfly lock --refresh zlib`"},
+		{"category default", CacheError("corrupted cache entry"), defaultHints["CACHE_ERROR"]},
+		{"plain error", fmt.Errorf("unknown flag"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Hint(tt.err); got != tt.want {
+				t.Errorf("Hint() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChain(t *testing.T) {
+	cause := ChecksumMismatchError("checksum mismatch for zlib.tar.gz").WithHint("run `buildfly lock --refresh zlib`")
+	err := fmt.Errorf("failed to install zlib: %w", DependencyErrorWithCause(cause, "failed to download zlib"))
+
+	chain := Chain(err)
+	want := []ChainEntry{
+		{Message: "failed to install zlib"},
+		{Message: "failed to download zlib", Code: "DEPENDENCY_ERROR"},
+		{Message: "checksum mismatch for zlib.tar.gz", Code: "CHECKSUM_MISMATCH", Hint: "run `buildfly lock --refresh zlib`"},
+	}
+	if len(chain) != len(want) {
+		t.Fatalf("Chain() = %+v, want %+v", chain, want)
+	}
+	for i := range want {
+		if chain[i] != want[i] {
+			t.Errorf("Chain()[%d] = %+v, want %+v", i, chain[i], want[i])
+		}
+	}
+
+	if chain := Chain(nil); len(chain) != 0 {
+		t.Errorf("Chain(nil) = %+v, want empty", chain)
+	}
+}
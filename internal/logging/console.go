@@ -0,0 +1,28 @@
+package logging
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// console 进度信息和构建工具输出的目标
+var (
+	consoleMu sync.RWMutex
+	console   io.Writer = os.Stdout
+)
+
+// SetConsole 设置进度信息的输出目标：结构化输出时为标准错误，安静模式下丢弃
+// 警告和错误由日志记录器输出到标准错误，不受影响
+func SetConsole(w io.Writer) {
+	consoleMu.Lock()
+	defer consoleMu.Unlock()
+	console = w
+}
+
+// Console 获取进度信息的输出目标，子进程的标准输出也写到这里
+func Console() io.Writer {
+	consoleMu.RLock()
+	defer consoleMu.RUnlock()
+	return console
+}
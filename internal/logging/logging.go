@@ -0,0 +1,213 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// 日志格式
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Options 日志选项
+type Options struct {
+	Level   string // debug, info, warn, error，为空时由 Verbose/Quiet 决定
+	Verbose bool   // 未指定 Level 时使用 debug
+	Quiet   bool   // 未指定 Level 时只输出警告和错误
+	File    string // 日志文件路径，为空时输出到标准错误
+	Format  string // text, json
+}
+
+// New 根据选项创建日志记录器，返回的 io.Closer 用于关闭日志文件
+func New(opts Options) (*slog.Logger, io.Closer, error) {
+	level, err := resolveLevel(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var w io.Writer = os.Stderr
+	var closer io.Closer = nopCloser{}
+	if opts.File != "" {
+		file, err := os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		w = file
+		closer = file
+	}
+
+	// 所有格式都隐藏 RegisterSecret 登记的密钥
+	handlerOpts := &slog.HandlerOptions{Level: level, ReplaceAttr: redactAttr}
+
+	var handler slog.Handler
+	switch opts.Format {
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, handlerOpts)
+	case "", FormatText:
+		if opts.File != "" {
+			// 日志文件使用带时间戳的 key=value 格式
+			handler = slog.NewTextHandler(w, handlerOpts)
+		} else {
+			handler = NewConsoleHandler(w, level)
+		}
+	default:
+		closer.Close()
+		return nil, nil, fmt.Errorf("invalid log format: %s (supported: text, json)", opts.Format)
+	}
+
+	return slog.New(handler), closer, nil
+}
+
+// resolveLevel 确定日志级别，显式指定的级别优先于 --verbose/--quiet
+func resolveLevel(opts Options) (slog.Level, error) {
+	switch {
+	case opts.Level != "":
+		return ParseLevel(opts.Level)
+	case opts.Quiet:
+		return slog.LevelWarn, nil
+	case opts.Verbose:
+		return slog.LevelDebug, nil
+	default:
+		return slog.LevelInfo, nil
+	}
+}
+
+// ParseLevel 解析日志级别
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("invalid log level: %s (supported: debug, info, warn, error)", level)
+	}
+}
+
+// OrDefault 返回 logger，为 nil 时返回 slog 默认记录器
+func OrDefault(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return slog.Default()
+	}
+	return logger
+}
+
+// nopCloser 输出到标准错误时不需要关闭
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+// ConsoleHandler 面向终端的简洁日志格式：info 只输出消息，其他级别带前缀
+//
+//	Installing CMake 3.28...
+//	Warning: failed to download from https://... error="..."
+type ConsoleHandler struct {
+	w      io.Writer
+	level  slog.Leveler
+	attrs  []slog.Attr
+	prefix string // WithGroup 设置的分组前缀
+	mu     *sync.Mutex
+}
+
+// NewConsoleHandler 创建终端日志处理器
+func NewConsoleHandler(w io.Writer, level slog.Leveler) *ConsoleHandler {
+	return &ConsoleHandler{w: w, level: level, mu: &sync.Mutex{}}
+}
+
+// Enabled 实现 slog.Handler
+func (h *ConsoleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// Handle 实现 slog.Handler
+func (h *ConsoleHandler) Handle(_ context.Context, record slog.Record) error {
+	var sb strings.Builder
+	switch {
+	case record.Level >= slog.LevelError:
+		sb.WriteString("Error: ")
+	case record.Level >= slog.LevelWarn:
+		sb.WriteString("Warning: ")
+	case record.Level < slog.LevelInfo:
+		sb.WriteString("debug: ")
+	}
+	sb.WriteString(record.Message)
+
+	for _, attr := range h.attrs {
+		writeAttr(&sb, "", attr)
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		writeAttr(&sb, h.prefix, attr)
+		return true
+	})
+	sb.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, Redact(sb.String()))
+	return err
+}
+
+// WithAttrs 实现 slog.Handler
+func (h *ConsoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append(append([]slog.Attr{}, h.attrs...), prefixAttrs(h.prefix, attrs)...)
+	return &clone
+}
+
+// WithGroup 实现 slog.Handler
+func (h *ConsoleHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.prefix = h.prefix + name + "."
+	return &clone
+}
+
+// prefixAttrs 为属性名添加分组前缀
+func prefixAttrs(prefix string, attrs []slog.Attr) []slog.Attr {
+	if prefix == "" {
+		return attrs
+	}
+	prefixed := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		prefixed[i] = slog.Attr{Key: prefix + attr.Key, Value: attr.Value}
+	}
+	return prefixed
+}
+
+// writeAttr 以 key=value 的形式写出属性，分组属性展开为 group.key=value
+func writeAttr(sb *strings.Builder, prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if attr.Key != "" {
+			groupPrefix += attr.Key + "."
+		}
+		for _, child := range attr.Value.Group() {
+			writeAttr(sb, groupPrefix, child)
+		}
+		return
+	}
+
+	value := attr.Value.String()
+	if strings.ContainsAny(value, " \t\"=") || value == "" {
+		value = fmt.Sprintf("%q", value)
+	}
+	fmt.Fprintf(sb, " %s%s=%s", prefix, attr.Key, value)
+}
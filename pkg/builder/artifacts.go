@@ -0,0 +1,153 @@
+package builder
+
+import (
+	"debug/elf"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"buildfly/internal/errors"
+	"buildfly/pkg/config"
+)
+
+// 产物校验模式
+const (
+	VerifyModeWarn  = "warn"  // 不匹配时给出警告（默认）
+	VerifyModeError = "error" // 不匹配时构建失败
+	VerifyModeOff   = "off"   // 不校验
+)
+
+// ArtifactRequirements 构建产物的运行时要求
+type ArtifactRequirements struct {
+	Arch     string   // 产物架构
+	GLIBC    string   // 需要的最高 GLIBC 版本
+	GLIBCXX  string   // 需要的最高 GLIBCXX 版本
+	Files    int      // 检查的 ELF 文件数量
+	Problems []string // 与构建标签不匹配的问题
+}
+
+// elfArchs ELF 机器类型到构建标签架构的映射
+var elfArchs = map[elf.Machine]string{
+	elf.EM_X86_64:  "x86_64",
+	elf.EM_AARCH64: "arm64",
+	elf.EM_386:     "i386",
+	elf.EM_ARM:     "arm",
+	elf.EM_RISCV:   "riscv64",
+	elf.EM_PPC64:   "ppc64le",
+	elf.EM_S390:    "s390x",
+}
+
+// VerifyArtifacts 检查安装目录中的可执行文件和共享库是否满足构建标签的 arch/runtime/libstdcxx
+// 读取每个 ELF 文件的架构和版本化符号依赖（GLIBC_2.x、GLIBCXX_3.4.x）
+func VerifyArtifacts(installDir string, buildTag *config.BuildTag) (*ArtifactRequirements, error) {
+	req := &ArtifactRequirements{}
+
+	var maxGLIBC, maxGLIBCXX string
+	if buildTag != nil {
+		if name, version, _ := config.ParseVersionedValue(buildTag.Runtime); name == "glibc" {
+			maxGLIBC = version
+		}
+		if name, version, _ := config.ParseVersionedValue(buildTag.Libstdcxx); name == "glibcxx" {
+			maxGLIBCXX = version
+		}
+	}
+
+	err := filepath.WalkDir(installDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() || !isELF(path) {
+			return nil
+		}
+
+		f, err := elf.Open(path)
+		if err != nil {
+			return nil
+		}
+		defer f.Close()
+
+		// 只检查可执行文件和共享库
+		if f.Type != elf.ET_EXEC && f.Type != elf.ET_DYN {
+			return nil
+		}
+		req.Files++
+
+		relPath, _ := filepath.Rel(installDir, path)
+
+		arch := elfArchs[f.Machine]
+		if arch == "" {
+			arch = strings.ToLower(strings.TrimPrefix(f.Machine.String(), "EM_"))
+		}
+		if req.Arch == "" {
+			req.Arch = arch
+		}
+		if buildTag != nil && buildTag.Arch != "" && buildTag.Arch != "any" &&
+			config.NormalizeArch(arch) != config.NormalizeArch(buildTag.Arch) {
+			req.Problems = append(req.Problems, fmt.Sprintf("%s is built for %s, expected %s", relPath, arch, buildTag.Arch))
+		}
+
+		glibc, glibcxx := elfVersionRequirements(f)
+		if maxGLIBC != "" && glibc != "" && config.CompareVersions(glibc, maxGLIBC) > 0 {
+			req.Problems = append(req.Problems, fmt.Sprintf("%s requires GLIBC_%s, newer than runtime %s", relPath, glibc, buildTag.Runtime))
+		}
+		if maxGLIBCXX != "" && glibcxx != "" && config.CompareVersions(glibcxx, maxGLIBCXX) > 0 {
+			req.Problems = append(req.Problems, fmt.Sprintf("%s requires GLIBCXX_%s, newer than libstdcxx %s", relPath, glibcxx, buildTag.Libstdcxx))
+		}
+		req.GLIBC = maxVersion(req.GLIBC, glibc)
+		req.GLIBCXX = maxVersion(req.GLIBCXX, glibcxx)
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.BuildErrorWithCause(err, "failed to inspect build artifacts")
+	}
+
+	return req, nil
+}
+
+// elfVersionRequirements 获取 ELF 文件需要的最高 GLIBC 和 GLIBCXX 版本
+func elfVersionRequirements(f *elf.File) (glibc, glibcxx string) {
+	needs, err := f.DynamicVersionNeeds()
+	if err != nil {
+		return "", ""
+	}
+
+	for _, need := range needs {
+		for _, dep := range need.Needs {
+			switch {
+			case strings.HasPrefix(dep.Dep, "GLIBCXX_"):
+				glibcxx = maxVersion(glibcxx, strings.TrimPrefix(dep.Dep, "GLIBCXX_"))
+			case strings.HasPrefix(dep.Dep, "GLIBC_") && !strings.HasPrefix(dep.Dep, "GLIBC_PRIVATE"):
+				glibc = maxVersion(glibc, strings.TrimPrefix(dep.Dep, "GLIBC_"))
+			}
+		}
+	}
+
+	return glibc, glibcxx
+}
+
+// maxVersion 返回两个版本中较高的一个，空版本视为最低
+func maxVersion(a, b string) string {
+	if a == "" {
+		return b
+	}
+	if b != "" && config.CompareVersions(b, a) > 0 {
+		return b
+	}
+	return a
+}
+
+// isELF 通过文件头判断是否为 ELF 文件
+func isELF(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	magic := make([]byte, len(elf.ELFMAG))
+	n, _ := file.Read(magic)
+	return n == len(magic) && string(magic) == elf.ELFMAG
+}
@@ -0,0 +1,90 @@
+package builder
+
+import (
+	"debug/elf"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"buildfly/pkg/config"
+)
+
+// testdata/libgreet.so 是 x86_64 的共享库，需要 GLIBC_2.2.5 和 GLIBCXX_3.4.21，生成方式：
+//
+//	g++ -O2 -shared -fPIC -o libgreet.so greet.cc && strip libgreet.so
+//
+// greet.cc 中的函数拼接并输出 std::string
+func TestVerifyArtifacts(t *testing.T) {
+	tests := []struct {
+		name     string
+		buildTag *config.BuildTag
+		problem  string
+	}{
+		{"no build tag", nil, ""},
+		{"matching build tag", &config.BuildTag{Arch: "x86_64", Runtime: "glibc_2.17", Libstdcxx: "glibcxx_3.4.21"}, ""},
+		{"arch alias", &config.BuildTag{Arch: "amd64"}, ""},
+		{"arch mismatch", &config.BuildTag{Arch: "arm64"}, "built for x86_64, expected arm64"},
+		{"glibc too new", &config.BuildTag{Runtime: "glibc_2.2"}, "requires GLIBC_2.2.5"},
+		{"glibcxx too new", &config.BuildTag{Libstdcxx: "glibcxx_3.4.19"}, "requires GLIBCXX_3.4.21"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := VerifyArtifacts("testdata", tt.buildTag)
+			if err != nil {
+				t.Fatalf("VerifyArtifacts() error = %v", err)
+			}
+			if req.Files != 1 || req.Arch != "x86_64" || req.GLIBC != "2.2.5" || req.GLIBCXX != "3.4.21" {
+				t.Errorf("VerifyArtifacts() = %+v, want 1 x86_64 file requiring GLIBC_2.2.5 and GLIBCXX_3.4.21", req)
+			}
+
+			if tt.problem == "" {
+				if len(req.Problems) != 0 {
+					t.Errorf("Problems = %v, want none", req.Problems)
+				}
+				return
+			}
+			if len(req.Problems) != 1 || !strings.Contains(req.Problems[0], tt.problem) {
+				t.Errorf("Problems = %v, want %q", req.Problems, tt.problem)
+			}
+		})
+	}
+}
+
+func TestVerifyArtifacts_Executable(t *testing.T) {
+	executable, err := os.Executable()
+	if err != nil {
+		t.Skip("test executable not available")
+	}
+	f, err := elf.Open(executable)
+	if err != nil {
+		t.Skip("test executable is not an ELF file")
+	}
+	machine := f.Machine
+	f.Close()
+
+	dir := t.TempDir()
+	data, err := os.ReadFile(executable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "test"), data, 0755); err != nil {
+		t.Fatal(err)
+	}
+	// 非 ELF 文件不参与校验
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("not an elf file"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := VerifyArtifacts(dir, nil)
+	if err != nil {
+		t.Fatalf("VerifyArtifacts() error = %v", err)
+	}
+	if req.Files != 1 {
+		t.Errorf("Files = %d, want 1", req.Files)
+	}
+	if want := elfArchs[machine]; want != "" && req.Arch != want {
+		t.Errorf("Arch = %q, want %q", req.Arch, want)
+	}
+}
@@ -0,0 +1,205 @@
+package builder
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"buildfly/internal/errors"
+	"buildfly/pkg/config"
+)
+
+// CompilerCache 编译器缓存（ccache/sccache）
+type CompilerCache struct {
+	Tool    string // ccache 或 sccache
+	Path    string // 可执行文件路径
+	Dir     string // 缓存目录
+	MaxSize string // 最大缓存大小
+}
+
+// CompilerCacheStats 编译器缓存命中统计
+type CompilerCacheStats struct {
+	Hits   int64
+	Misses int64
+}
+
+// NewCompilerCache 根据配置创建编译器缓存，未启用时返回 nil
+// 缓存目录默认位于 buildfly 缓存目录下
+func NewCompilerCache(cfg *config.CompilerCacheConfig, cacheDir string) (*CompilerCache, error) {
+	if cfg == nil || !cfg.Enabled {
+		return nil, nil
+	}
+
+	tool := cfg.Tool
+	if tool == "" {
+		tool = "ccache"
+	}
+	if tool != "ccache" && tool != "sccache" {
+		return nil, errors.ConfigError(fmt.Sprintf("unsupported compiler cache tool: %s", tool))
+	}
+
+	path, err := exec.LookPath(tool)
+	if err != nil {
+		return nil, errors.BuildErrorWithCause(err, fmt.Sprintf("compiler cache tool not found: %s", tool))
+	}
+
+	dir := cfg.Dir
+	if dir == "" {
+		dir = filepath.Join(cacheDir, tool)
+	}
+	if absDir, err := filepath.Abs(dir); err == nil {
+		dir = absDir
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.BuildErrorWithCause(err, "failed to create compiler cache directory")
+	}
+
+	return &CompilerCache{
+		Tool:    tool,
+		Path:    path,
+		Dir:     dir,
+		MaxSize: cfg.MaxSize,
+	}, nil
+}
+
+// EnvVars 获取编译器缓存的环境变量
+func (cc *CompilerCache) EnvVars() map[string]string {
+	vars := make(map[string]string)
+	switch cc.Tool {
+	case "sccache":
+		vars["SCCACHE_DIR"] = cc.Dir
+		if cc.MaxSize != "" {
+			vars["SCCACHE_CACHE_SIZE"] = cc.MaxSize
+		}
+	default:
+		vars["CCACHE_DIR"] = cc.Dir
+		if cc.MaxSize != "" {
+			vars["CCACHE_MAXSIZE"] = cc.MaxSize
+		}
+	}
+	return vars
+}
+
+// CMakeArgs 获取 CMake 编译器启动器参数
+func (cc *CompilerCache) CMakeArgs() []string {
+	return []string{
+		fmt.Sprintf("-DCMAKE_C_COMPILER_LAUNCHER=%s", cc.Path),
+		fmt.Sprintf("-DCMAKE_CXX_COMPILER_LAUNCHER=%s", cc.Path),
+	}
+}
+
+// WrapCompiler 使用编译器缓存包装编译器命令
+func (cc *CompilerCache) WrapCompiler(compiler string) string {
+	if compiler == "" || strings.HasPrefix(compiler, cc.Path+" ") {
+		return compiler
+	}
+	return cc.Path + " " + compiler
+}
+
+// Stats 获取命中统计
+func (cc *CompilerCache) Stats() (*CompilerCacheStats, error) {
+	if cc.Tool == "sccache" {
+		output, err := cc.output("--show-stats", "--stats-format", "json")
+		if err != nil {
+			return nil, err
+		}
+		return parseSccacheStats(output)
+	}
+
+	output, err := cc.output("--print-stats")
+	if err != nil {
+		return nil, err
+	}
+	return parseCcacheStats(output), nil
+}
+
+// output 运行编译器缓存工具并返回输出
+func (cc *CompilerCache) output(args ...string) ([]byte, error) {
+	cmd := exec.Command(cc.Path, args...)
+	env := os.Environ()
+	for key, value := range cc.EnvVars() {
+		env = append(env, fmt.Sprintf("%s=%s", key, value))
+	}
+	cmd.Env = env
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, errors.BuildErrorWithCause(err, fmt.Sprintf("%s %s failed: %s", cc.Tool, strings.Join(args, " "), strings.TrimSpace(stderr.String())))
+	}
+	return output, nil
+}
+
+// parseCcacheStats 解析 ccache --print-stats 的输出（每行为 "key<TAB>value"）
+func parseCcacheStats(output []byte) *CompilerCacheStats {
+	stats := &CompilerCacheStats{}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		value, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch fields[0] {
+		case "direct_cache_hit", "preprocessed_cache_hit":
+			stats.Hits += value
+		case "cache_miss":
+			stats.Misses += value
+		}
+	}
+	return stats
+}
+
+// parseSccacheStats 解析 sccache --show-stats --stats-format json 的输出
+func parseSccacheStats(output []byte) (*CompilerCacheStats, error) {
+	var data struct {
+		Stats struct {
+			CacheHits struct {
+				Counts map[string]int64 `json:"counts"`
+			} `json:"cache_hits"`
+			CacheMisses struct {
+				Counts map[string]int64 `json:"counts"`
+			} `json:"cache_misses"`
+		} `json:"stats"`
+	}
+	if err := json.Unmarshal(output, &data); err != nil {
+		return nil, errors.BuildErrorWithCause(err, "failed to parse sccache stats")
+	}
+
+	stats := &CompilerCacheStats{}
+	for _, count := range data.Stats.CacheHits.Counts {
+		stats.Hits += count
+	}
+	for _, count := range data.Stats.CacheMisses.Counts {
+		stats.Misses += count
+	}
+	return stats, nil
+}
+
+// Since 获取相对于之前读取的统计新增的命中和未命中次数
+// 统计在此期间被其他进程清零时按零处理
+func (s *CompilerCacheStats) Since(before *CompilerCacheStats) *CompilerCacheStats {
+	return &CompilerCacheStats{
+		Hits:   max(s.Hits-before.Hits, 0),
+		Misses: max(s.Misses-before.Misses, 0),
+	}
+}
+
+// HitRate 获取命中率（百分比）
+func (s *CompilerCacheStats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) * 100 / float64(total)
+}
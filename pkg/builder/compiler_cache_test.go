@@ -0,0 +1,198 @@
+package builder
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCcacheStats(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   CompilerCacheStats
+	}{
+		{
+			name: "ccache 4.8",
+			output: "autoconf_test\t0\n" +
+				"called_for_link\t2\n" +
+				"cache_miss\t4\n" +
+				"compiler_check_failed\t0\n" +
+				"direct_cache_hit\t12\n" +
+				"direct_cache_miss\t5\n" +
+				"preprocessed_cache_hit\t3\n" +
+				"preprocessed_cache_miss\t4\n" +
+				"stats_updated_timestamp\t1697650000\n" +
+				"stats_zeroed_timestamp\t0\n",
+			want: CompilerCacheStats{Hits: 15, Misses: 4},
+		},
+		{
+			name:   "after zero stats",
+			output: "cache_miss\t0\ndirect_cache_hit\t0\npreprocessed_cache_hit\t0\n",
+			want:   CompilerCacheStats{},
+		},
+		{
+			name:   "unparsable lines are ignored",
+			output: "ccache: invalid option -- '-'\ncache_miss\tmany\ndirect_cache_hit\t7\n",
+			want:   CompilerCacheStats{Hits: 7},
+		},
+		{
+			name:   "empty output",
+			output: "",
+			want:   CompilerCacheStats{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseCcacheStats([]byte(tt.output))
+			if *got != tt.want {
+				t.Errorf("parseCcacheStats() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSccacheStats(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    CompilerCacheStats
+		wantErr bool
+	}{
+		{
+			name: "sccache 0.7",
+			output: `{"stats":{"compile_requests":12,"requests_unsupported_compiler":0,"requests_not_compile":1,` +
+				`"requests_not_cacheable":1,"requests_executed":10,"cache_errors":{"counts":{},"adv_counts":{}},` +
+				`"cache_hits":{"counts":{"C/C++":7},"adv_counts":{"c/c++ [gcc]":7}},` +
+				`"cache_misses":{"counts":{"C/C++":2,"Rust":1},"adv_counts":{"c/c++ [gcc]":2,"rust":1}},` +
+				`"cache_timeouts":0,"cache_read_errors":0,"non_cacheable_compilations":0,"forced_recaches":0},` +
+				`"cache_location":"Local disk: \"/home/user/.buildfly/cache/sccache\"","cache_size":1048576,"max_cache_size":10737418240}`,
+			want: CompilerCacheStats{Hits: 7, Misses: 3},
+		},
+		{
+			name:   "no compilations",
+			output: `{"stats":{"compile_requests":0,"cache_hits":{"counts":{}},"cache_misses":{"counts":{}}}}`,
+			want:   CompilerCacheStats{},
+		},
+		{
+			name:    "text output",
+			output:  "Compile requests                      12\nCache hits                             7\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSccacheStats([]byte(tt.output))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSccacheStats() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && *got != tt.want {
+				t.Errorf("parseSccacheStats() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompilerCacheStats_HitRate(t *testing.T) {
+	tests := []struct {
+		stats CompilerCacheStats
+		want  float64
+	}{
+		{CompilerCacheStats{Hits: 3, Misses: 1}, 75},
+		{CompilerCacheStats{Hits: 0, Misses: 4}, 0},
+		{CompilerCacheStats{}, 0},
+	}
+
+	for _, tt := range tests {
+		if got := tt.stats.HitRate(); got != tt.want {
+			t.Errorf("HitRate(%+v) = %v, want %v", tt.stats, got, tt.want)
+		}
+	}
+}
+
+func TestCompilerCache_EnvVars(t *testing.T) {
+	tests := []struct {
+		name  string
+		cache CompilerCache
+		want  map[string]string
+	}{
+		{
+			name:  "ccache",
+			cache: CompilerCache{Tool: "ccache", Dir: "/cache/ccache"},
+			want:  map[string]string{"CCACHE_DIR": "/cache/ccache"},
+		},
+		{
+			name:  "ccache with max size",
+			cache: CompilerCache{Tool: "ccache", Dir: "/cache/ccache", MaxSize: "5G"},
+			want:  map[string]string{"CCACHE_DIR": "/cache/ccache", "CCACHE_MAXSIZE": "5G"},
+		},
+		{
+			name:  "sccache with max size",
+			cache: CompilerCache{Tool: "sccache", Dir: "/cache/sccache", MaxSize: "10G"},
+			want:  map[string]string{"SCCACHE_DIR": "/cache/sccache", "SCCACHE_CACHE_SIZE": "10G"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cache.EnvVars(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("EnvVars() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompilerCache_CMakeArgs(t *testing.T) {
+	cache := &CompilerCache{Tool: "ccache", Path: "/usr/bin/ccache"}
+	want := []string{
+		"-DCMAKE_C_COMPILER_LAUNCHER=/usr/bin/ccache",
+		"-DCMAKE_CXX_COMPILER_LAUNCHER=/usr/bin/ccache",
+	}
+	if got := cache.CMakeArgs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("CMakeArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestCompilerCache_WrapCompiler(t *testing.T) {
+	cache := &CompilerCache{Tool: "sccache", Path: "/usr/bin/sccache"}
+
+	tests := []struct {
+		compiler string
+		want     string
+	}{
+		{"gcc", "/usr/bin/sccache gcc"},
+		{"/opt/gcc-12/bin/g++ -m64", "/usr/bin/sccache /opt/gcc-12/bin/g++ -m64"},
+		{"/usr/bin/sccache gcc", "/usr/bin/sccache gcc"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.compiler, func(t *testing.T) {
+			if got := cache.WrapCompiler(tt.compiler); got != tt.want {
+				t.Errorf("WrapCompiler(%q) = %q, want %q", tt.compiler, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompilerCacheStatsSince(t *testing.T) {
+	tests := []struct {
+		name    string
+		before  CompilerCacheStats
+		current CompilerCacheStats
+		want    CompilerCacheStats
+	}{
+		{"new hits and misses", CompilerCacheStats{Hits: 10, Misses: 4}, CompilerCacheStats{Hits: 25, Misses: 6}, CompilerCacheStats{Hits: 15, Misses: 2}},
+		{"unchanged", CompilerCacheStats{Hits: 10, Misses: 4}, CompilerCacheStats{Hits: 10, Misses: 4}, CompilerCacheStats{}},
+		{"zeroed by another process", CompilerCacheStats{Hits: 10, Misses: 4}, CompilerCacheStats{Hits: 3}, CompilerCacheStats{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.current.Since(&tt.before); !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("Since() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
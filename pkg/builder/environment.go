@@ -0,0 +1,149 @@
+package builder
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// hermeticAllowlist 密封构建模式下从主机继承的环境变量
+var hermeticAllowlist = []string{
+	"HOME", "USER", "LOGNAME", "SHELL", "TMPDIR", "TERM", "TZ",
+	"BUILDFLY_ENV_ROOT",
+	// Windows 运行所需的基本变量
+	"SYSTEMROOT", "WINDIR", "COMSPEC", "PATHEXT", "TEMP", "TMP",
+}
+
+// hermeticPath 密封构建模式下的默认 PATH
+const hermeticPath = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+
+// Hermetic 检查当前依赖是否使用密封构建环境
+func (be *BuildExecutor) Hermetic() bool {
+	return be.context.ProjectConfig != nil && be.context.ProjectConfig.IsHermetic(be.dependency)
+}
+
+// EffectiveEnv 获取构建工具（cmake、make 等）实际使用的环境变量
+func (be *BuildExecutor) EffectiveEnv() map[string]string {
+	return be.commandEnv(true)
+}
+
+// commandEnv 获取命令使用的环境变量
+// 普通模式下只有构建工具使用虚拟环境变量，自定义命令和脚本继承主机环境；
+// 密封模式下所有命令都使用虚拟环境的 PATH 和依赖的 env_variables
+func (be *BuildExecutor) commandEnv(withVenv bool) map[string]string {
+	vars := be.baseEnv()
+	hermetic := be.Hermetic()
+
+	// 添加自定义变量
+	for key, value := range be.context.CustomVars {
+		vars[key] = value
+	}
+
+	// 如果有虚拟环境管理器，添加虚拟环境变量
+	if be.venvManager != nil && (withVenv || hermetic) {
+		if venvVars, err := be.venvManager.GetEnvironmentVars(); err == nil {
+			for key, value := range venvVars {
+				if key == "PATH" && hermetic {
+					// 虚拟环境的 PATH 追加了主机 PATH，密封模式下只在默认 PATH 前加上虚拟环境的 bin 目录
+					value = filepath.Join(be.venvManager.GetRootDir(), "bin") + string(filepath.ListSeparator) + vars["PATH"]
+				}
+				vars[key] = value
+			}
+		}
+	}
+
+	// 依赖的环境变量
+	if hermetic {
+		for key, value := range be.dependency.EnvVariables {
+			vars[key] = value
+		}
+	}
+
+	// 交叉编译工具链和编译器缓存变量优先于虚拟环境中的编译器
+	be.applyCompilerEnv(vars)
+
+	// 构建变体的编译标志追加到已有标志之后
+	for key, value := range be.context.BuildTag.VariantEnvFlags() {
+		vars[key] = strings.TrimSpace(vars[key] + " " + value)
+	}
+
+	return vars
+}
+
+// environ 获取 exec.Cmd 使用的环境变量列表，withVenv 表示普通模式下是否添加虚拟环境变量
+func (be *BuildExecutor) environ(withVenv bool) []string {
+	vars := be.commandEnv(withVenv)
+
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	env := make([]string, 0, len(keys))
+	for _, key := range keys {
+		env = append(env, fmt.Sprintf("%s=%s", key, vars[key]))
+	}
+	return env
+}
+
+// baseEnv 获取基础环境变量：普通模式继承主机全部环境，密封模式只保留白名单
+func (be *BuildExecutor) baseEnv() map[string]string {
+	vars := make(map[string]string)
+
+	if !be.Hermetic() {
+		for _, entry := range os.Environ() {
+			if key, value, ok := strings.Cut(entry, "="); ok && key != "" {
+				vars[key] = value
+			}
+		}
+		return vars
+	}
+
+	for _, key := range hermeticAllowlist {
+		if value, ok := os.LookupEnv(key); ok {
+			vars[key] = value
+		}
+	}
+
+	if runtime.GOOS == "windows" {
+		vars["PATH"] = os.Getenv("PATH")
+	} else {
+		vars["PATH"] = hermeticPath
+	}
+	vars["LANG"] = "C"
+	vars["LC_ALL"] = "C"
+
+	return vars
+}
+
+// applyCompilerEnv 添加交叉编译工具链和编译器缓存的环境变量
+// CMake 通过 CMAKE_<LANG>_COMPILER_LAUNCHER 使用编译器缓存，其他构建系统通过包装 CC/CXX 使用
+func (be *BuildExecutor) applyCompilerEnv(vars map[string]string) {
+	if be.context.Toolchain != nil {
+		for key, value := range be.context.Toolchain.EnvVars() {
+			vars[key] = value
+		}
+	}
+
+	if be.compilerCache != nil {
+		for key, value := range be.compilerCache.EnvVars() {
+			vars[key] = value
+		}
+		if be.dependency.BuildSystem != "cmake" {
+			vars["CC"] = be.compilerCache.WrapCompiler(compilerOrDefault(vars["CC"], "cc"))
+			vars["CXX"] = be.compilerCache.WrapCompiler(compilerOrDefault(vars["CXX"], "c++"))
+		}
+	}
+}
+
+// compilerOrDefault 获取编译器，未设置时使用默认值
+func compilerOrDefault(compiler, fallback string) string {
+	if compiler != "" {
+		return compiler
+	}
+	return fallback
+}
@@ -0,0 +1,60 @@
+package builder
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"buildfly/pkg/config"
+	"buildfly/pkg/venv"
+)
+
+func TestBuildExecutor_CommandEnv(t *testing.T) {
+	venvRoot := t.TempDir()
+	t.Setenv("BUILDFLY_ENV_ROOT", venvRoot)
+	t.Setenv("PATH", "/host/bin")
+	t.Setenv("CFLAGS", "-O0")
+
+	newExecutor := func(hermetic bool) *BuildExecutor {
+		projectConfig := &config.ProjectConfig{
+			Hermetic: &hermetic,
+			VEnv:     &venv.VEnvConfig{Enabled: true, RootDir: venvRoot},
+		}
+		executor := NewBuildExecutor(&config.VariableContext{ProjectConfig: projectConfig})
+		executor.dependency = config.Dependency{Name: "zlib", EnvVariables: map[string]string{"ZLIB_OPT": "1"}}
+		return executor
+	}
+	venvPath := filepath.Join(venvRoot, "bin") + string(os.PathListSeparator)
+
+	// 普通模式：自定义命令继承主机环境，只有构建工具使用虚拟环境
+	executor := newExecutor(false)
+	commandEnv := executor.commandEnv(false)
+	if commandEnv["PATH"] != "/host/bin" || commandEnv["CFLAGS"] != "-O0" {
+		t.Errorf("command env PATH=%q CFLAGS=%q, want host values", commandEnv["PATH"], commandEnv["CFLAGS"])
+	}
+	if _, ok := commandEnv["ZLIB_OPT"]; ok {
+		t.Error("Expected env_variables to be unused outside hermetic mode")
+	}
+	if got := executor.commandEnv(true)["PATH"]; got != venvPath+"/host/bin" {
+		t.Errorf("build tool PATH = %q, want %q", got, venvPath+"/host/bin")
+	}
+
+	// 密封模式：所有命令都使用虚拟环境的 bin 目录加默认 PATH 和依赖的环境变量
+	executor = newExecutor(true)
+	for _, withVenv := range []bool{false, true} {
+		env := executor.commandEnv(withVenv)
+		if env["PATH"] != venvPath+hermeticPath {
+			t.Errorf("hermetic PATH = %q, want %q", env["PATH"], venvPath+hermeticPath)
+		}
+		if strings.Contains(env["PATH"], "/host/bin") {
+			t.Errorf("hermetic PATH contains host PATH: %q", env["PATH"])
+		}
+		if _, ok := env["CFLAGS"]; ok {
+			t.Error("Expected host CFLAGS to be dropped in hermetic mode")
+		}
+		if env["ZLIB_OPT"] != "1" {
+			t.Errorf("ZLIB_OPT = %q, want 1", env["ZLIB_OPT"])
+		}
+	}
+}
@@ -3,23 +3,44 @@ package builder
 import (
 	"bytes"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"text/template"
+	"time"
 
 	"buildfly/internal/errors"
+	"buildfly/internal/logging"
 	"buildfly/pkg/config"
 	"buildfly/pkg/venv"
 )
 
 // BuildExecutor 构建执行器
 type BuildExecutor struct {
-	context     *config.VariableContext
-	venvManager *venv.Manager
+	context       *config.VariableContext
+	venvManager   *venv.Manager
+	compilerCache *CompilerCache
+	dependency    config.Dependency
+	logger        *slog.Logger
+
+	phaseRecorder PhaseRecorder
+	currentPhase  string
+	phaseStart    time.Time
 }
 
+// 构建阶段
+const (
+	PhaseConfigure = "configure"
+	PhaseBuild     = "build"
+	PhaseInstall   = "install"
+)
+
+// PhaseRecorder 接收构建阶段耗时的回调
+type PhaseRecorder func(phase string, duration time.Duration)
+
 // TemplateData 模板数据结构
 type TemplateData struct {
 	Dependency     config.Dependency
@@ -33,6 +54,8 @@ type TemplateData struct {
 	CPUCount       int
 	OS             string
 	Arch           string
+	Toolchain      *config.Toolchain
+	BuildTag       *config.BuildTag
 }
 
 // NewBuildExecutor 创建构建执行器
@@ -51,6 +74,44 @@ func NewBuildExecutor(ctx *config.VariableContext) *BuildExecutor {
 	return executor
 }
 
+// SetLogger 设置日志记录器，同时应用到虚拟环境管理器
+func (be *BuildExecutor) SetLogger(logger *slog.Logger) {
+	be.logger = logger
+	if be.venvManager != nil {
+		be.venvManager.SetLogger(logger)
+	}
+}
+
+// log 获取日志记录器，未设置时使用默认记录器
+func (be *BuildExecutor) log() *slog.Logger {
+	return logging.OrDefault(be.logger)
+}
+
+// SetPhaseRecorder 设置构建阶段耗时的回调，每个阶段结束时调用
+func (be *BuildExecutor) SetPhaseRecorder(recorder PhaseRecorder) {
+	be.phaseRecorder = recorder
+}
+
+// startPhase 结束当前阶段并开始新的阶段
+func (be *BuildExecutor) startPhase(phase string) {
+	be.endPhase()
+	be.currentPhase = phase
+	be.phaseStart = time.Now()
+}
+
+// endPhase 结束当前阶段并报告耗时
+func (be *BuildExecutor) endPhase() {
+	if be.currentPhase != "" && be.phaseRecorder != nil {
+		be.phaseRecorder(be.currentPhase, time.Since(be.phaseStart))
+	}
+	be.currentPhase = ""
+}
+
+// SetCompilerCache 设置编译器缓存（ccache/sccache）
+func (be *BuildExecutor) SetCompilerCache(cc *CompilerCache) {
+	be.compilerCache = cc
+}
+
 // Execute 执行构建
 func (be *BuildExecutor) Execute(dep config.Dependency, sourceDir, buildDir, installDir string) error {
 	// 设置构建上下文
@@ -58,11 +119,15 @@ func (be *BuildExecutor) Execute(dep config.Dependency, sourceDir, buildDir, ins
 	sourceDir, _ = filepath.Abs(sourceDir)
 	buildDir, _ = filepath.Abs(buildDir)
 	installDir, _ = filepath.Abs(installDir)
+	// 指定了 subdir 时构建系统使用源码树中的子目录
+	sourceDir = dep.Source.SourcePath(sourceDir)
 	be.context.SourceDir = sourceDir
 	be.context.BuildDir = buildDir
 	be.context.InstallDir = installDir
+	be.dependency = dep
 
-	fmt.Printf("ctx: %+v\n", be.context)
+	be.log().Debug("Build context", "dependency", dep.Name, "context", fmt.Sprintf("%+v", be.context))
+	defer be.endPhase()
 
 	// 根据构建系统执行构建
 	switch dep.BuildSystem {
@@ -87,6 +152,7 @@ func (be *BuildExecutor) executeCMake(dep config.Dependency, sourceDir, buildDir
 	}
 
 	// 配置阶段
+	be.startPhase(PhaseConfigure)
 	if dep.BuildCommands.Configure != "" {
 		if err := be.executeCommand(dep.BuildCommands.Configure, buildDir); err != nil {
 			return errors.BuildErrorWithCause(err, "CMake configure failed")
@@ -100,6 +166,19 @@ func (be *BuildExecutor) executeCMake(dep config.Dependency, sourceDir, buildDir
 			fmt.Sprintf("-DCMAKE_BUILD_TYPE=%s", be.context.BuildType),
 		}
 
+		// 交叉编译工具链参数
+		if be.context.Toolchain != nil {
+			cmakeArgs = append(cmakeArgs, be.context.Toolchain.CMakeArgs()...)
+		}
+
+		// 构建变体（link/sanitizer）参数
+		cmakeArgs = append(cmakeArgs, be.context.BuildTag.VariantCMakeArgs()...)
+
+		// 编译器缓存启动器
+		if be.compilerCache != nil {
+			cmakeArgs = append(cmakeArgs, be.compilerCache.CMakeArgs()...)
+		}
+
 		// 添加 CMake 选项
 		for _, option := range dep.CMakeOptions {
 			expandedOption, err := be.context.ExpandCommand(option)
@@ -115,6 +194,7 @@ func (be *BuildExecutor) executeCMake(dep config.Dependency, sourceDir, buildDir
 	}
 
 	// 构建阶段
+	be.startPhase(PhaseBuild)
 	if dep.BuildCommands.Build != "" {
 		if err := be.executeCommand(dep.BuildCommands.Build, buildDir); err != nil {
 			return errors.BuildErrorWithCause(err, "CMake build failed")
@@ -133,6 +213,7 @@ func (be *BuildExecutor) executeCMake(dep config.Dependency, sourceDir, buildDir
 	}
 
 	// 安装阶段
+	be.startPhase(PhaseInstall)
 	if dep.BuildCommands.Install != "" {
 		if err := be.executeCommand(dep.BuildCommands.Install, buildDir); err != nil {
 			return errors.BuildErrorWithCause(err, "CMake install failed")
@@ -161,12 +242,14 @@ func (be *BuildExecutor) executeMake(dep config.Dependency, sourceDir, buildDir,
 
 	// 配置阶段（如果需要）
 	if dep.BuildCommands.Configure != "" {
+		be.startPhase(PhaseConfigure)
 		if err := be.executeCommand(dep.BuildCommands.Configure, sourceDir); err != nil {
 			return errors.BuildErrorWithCause(err, "Make configure failed")
 		}
 	}
 
 	// 构建阶段
+	be.startPhase(PhaseBuild)
 	if dep.BuildCommands.Build != "" {
 		if err := be.executeCommand(dep.BuildCommands.Build, sourceDir); err != nil {
 			return errors.BuildErrorWithCause(err, "Make build failed")
@@ -192,6 +275,7 @@ func (be *BuildExecutor) executeMake(dep config.Dependency, sourceDir, buildDir,
 	}
 
 	// 安装阶段
+	be.startPhase(PhaseInstall)
 	if dep.BuildCommands.Install != "" {
 		if err := be.executeCommand(dep.BuildCommands.Install, sourceDir); err != nil {
 			return errors.BuildErrorWithCause(err, "Make install failed")
@@ -216,6 +300,7 @@ func (be *BuildExecutor) executeConfigure(dep config.Dependency, sourceDir, buil
 	}
 
 	// 配置阶段
+	be.startPhase(PhaseConfigure)
 	if dep.BuildCommands.Configure != "" {
 		if err := be.executeCommand(dep.BuildCommands.Configure, sourceDir); err != nil {
 			return errors.BuildErrorWithCause(err, "Configure failed")
@@ -227,6 +312,14 @@ func (be *BuildExecutor) executeConfigure(dep config.Dependency, sourceDir, buil
 			fmt.Sprintf("--prefix=%s", installDir),
 		}
 
+		// 交叉编译工具链参数
+		if be.context.Toolchain != nil {
+			configureArgs = append(configureArgs, be.context.Toolchain.ConfigureArgs()...)
+		}
+
+		// 构建变体（link/sanitizer）参数
+		configureArgs = append(configureArgs, be.context.BuildTag.VariantConfigureArgs()...)
+
 		// 添加 Configure 选项
 		for _, option := range dep.ConfigureOptions {
 			expandedOption, err := be.context.ExpandCommand(option)
@@ -242,6 +335,7 @@ func (be *BuildExecutor) executeConfigure(dep config.Dependency, sourceDir, buil
 	}
 
 	// 构建阶段
+	be.startPhase(PhaseBuild)
 	if dep.BuildCommands.Build != "" {
 		if err := be.executeCommand(dep.BuildCommands.Build, sourceDir); err != nil {
 			return errors.BuildErrorWithCause(err, "Make build failed")
@@ -258,6 +352,7 @@ func (be *BuildExecutor) executeConfigure(dep config.Dependency, sourceDir, buil
 	}
 
 	// 安装阶段
+	be.startPhase(PhaseInstall)
 	if dep.BuildCommands.Install != "" {
 		if err := be.executeCommand(dep.BuildCommands.Install, sourceDir); err != nil {
 			return errors.BuildErrorWithCause(err, "Make install failed")
@@ -279,6 +374,7 @@ func (be *BuildExecutor) executeCustom(dep config.Dependency, sourceDir, buildDi
 	}
 
 	// 自定义构建脚本在构建目录执行
+	be.startPhase(PhaseBuild)
 	if err := be.executeScriptWithDependency(dep.CustomScript, buildDir, dep); err != nil {
 		return errors.BuildErrorWithCause(err, "custom script execution failed")
 	}
@@ -286,6 +382,74 @@ func (be *BuildExecutor) executeCustom(dep config.Dependency, sourceDir, buildDi
 	return nil
 }
 
+// RunTests 在构建目录中运行依赖的测试
+// sourceDir 与 Execute 相同，是源码树的根目录（指定了 subdir 时在其中查找子目录）。
+// 优先使用 build_commands.test，否则按构建系统使用默认测试命令（ctest / make check）。
+// 没有可运行的测试时返回 skipped=true。
+func (be *BuildExecutor) RunTests(dep config.Dependency, sourceDir, buildDir string) (command string, skipped bool, err error) {
+	sourceDir, _ = filepath.Abs(sourceDir)
+	buildDir, _ = filepath.Abs(buildDir)
+	sourceDir = dep.Source.SourcePath(sourceDir)
+	be.context.BuildDir = buildDir
+	be.context.SourceDir = sourceDir
+	be.dependency = dep
+
+	if dep.BuildCommands.Test != "" {
+		command, err = be.context.ExpandCommand(dep.BuildCommands.Test)
+		if err != nil {
+			return "", false, errors.BuildErrorWithCause(err, "failed to expand command variables")
+		}
+		if err := be.executeCommand(dep.BuildCommands.Test, buildDir); err != nil {
+			return command, false, errors.BuildErrorWithCause(err, "test command failed")
+		}
+		return command, false, nil
+	}
+
+	// make/configure 在源码目录中构建，测试也在源码目录中运行
+	testDir := buildDir
+	if dep.BuildSystem == "make" || dep.BuildSystem == "configure" {
+		testDir = sourceDir
+	}
+
+	name, args := be.defaultTestCommand(dep, testDir)
+	if name == "" {
+		return "", true, nil
+	}
+
+	command = strings.Join(append([]string{name}, args...), " ")
+	if err := be.runCommandInDir(name, testDir, args...); err != nil {
+		return command, false, errors.BuildErrorWithCause(err, "tests failed")
+	}
+	return command, false, nil
+}
+
+// defaultTestCommand 获取构建系统的默认测试命令，没有测试时返回空
+func (be *BuildExecutor) defaultTestCommand(dep config.Dependency, buildDir string) (string, []string) {
+	switch dep.BuildSystem {
+	case "cmake":
+		// 未启用 enable_testing() 的项目不会生成 CTestTestfile.cmake
+		if _, err := os.Stat(filepath.Join(buildDir, "CTestTestfile.cmake")); err != nil {
+			return "", nil
+		}
+		return "ctest", []string{
+			"--test-dir", buildDir,
+			"--build-config", be.context.BuildType,
+			"--output-on-failure",
+			"--parallel", fmt.Sprintf("%d", be.context.CPUCount),
+		}
+	case "make", "configure":
+		// 检查 Makefile 是否提供 check 目标
+		probe := exec.Command("make", "-n", "check")
+		probe.Dir = buildDir
+		if err := probe.Run(); err != nil {
+			return "", nil
+		}
+		return "make", []string{"check", fmt.Sprintf("-j%d", be.context.CPUCount)}
+	default:
+		return "", nil
+	}
+}
+
 // executeCommand 执行单个命令
 func (be *BuildExecutor) executeCommand(commandLine, workDir string) error {
 	// 展开命令中的变量
@@ -297,17 +461,11 @@ func (be *BuildExecutor) executeCommand(commandLine, workDir string) error {
 	// 使用 shell 执行命令（支持管道和重定向）
 	cmd := exec.Command("sh", "-c", expandedCommand)
 	cmd.Dir = workDir
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = logging.Console()
 	cmd.Stderr = os.Stderr
 
 	// 设置环境变量
-	if be.context.CustomVars != nil {
-		env := os.Environ()
-		for key, value := range be.context.CustomVars {
-			env = append(env, fmt.Sprintf("%s=%s", key, value))
-		}
-		cmd.Env = env
-	}
+	cmd.Env = be.environ(false)
 
 	return cmd.Run()
 }
@@ -332,6 +490,8 @@ func (be *BuildExecutor) executeScriptWithDependency(script, workDir string, dep
 		CPUCount:       be.context.CPUCount,
 		OS:             runtime.GOOS,
 		Arch:           runtime.GOARCH,
+		Toolchain:      be.context.Toolchain,
+		BuildTag:       be.context.BuildTag,
 	}
 
 	// 解析并执行模板
@@ -356,18 +516,14 @@ func (be *BuildExecutor) executeScriptWithDependency(script, workDir string, dep
 	// 执行脚本
 	cmd := exec.Command("bash", scriptName)
 	cmd.Dir = workDir
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = logging.Console()
 	cmd.Stderr = os.Stderr
 
 	// 设置环境变量
-	if be.context.CustomVars != nil {
-		env := os.Environ()
-		for key, value := range be.context.CustomVars {
-			fmt.Printf("Run With Env: %s=%s\n", key, value)
-			env = append(env, fmt.Sprintf("%s=%s", key, value))
-		}
-		cmd.Env = env
+	for key, value := range be.context.CustomVars {
+		be.log().Debug("Run with env", "key", key, "value", value)
 	}
+	cmd.Env = be.environ(false)
 
 	return cmd.Run()
 }
@@ -388,29 +544,11 @@ func (be *BuildExecutor) runCommandWithEnv(name, dir string, args ...string) err
 	if dir != "" {
 		cmd.Dir = dir
 	}
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = logging.Console()
 	cmd.Stderr = os.Stderr
 
 	// 设置环境变量
-	env := os.Environ()
-
-	// 添加自定义变量
-	if be.context.CustomVars != nil {
-		for key, value := range be.context.CustomVars {
-			env = append(env, fmt.Sprintf("%s=%s", key, value))
-		}
-	}
-
-	// 如果有虚拟环境管理器，添加虚拟环境变量
-	if be.venvManager != nil {
-		if venvVars, err := be.venvManager.GetEnvironmentVars(); err == nil {
-			for key, value := range venvVars {
-				env = append(env, fmt.Sprintf("%s=%s", key, value))
-			}
-		}
-	}
-
-	cmd.Env = env
+	cmd.Env = be.environ(true)
 	return cmd.Run()
 }
 
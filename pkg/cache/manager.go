@@ -4,12 +4,14 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"buildfly/internal/errors"
+	"buildfly/internal/logging"
 	"buildfly/pkg/config"
 	"buildfly/pkg/utils"
 )
@@ -19,6 +21,7 @@ type CacheManager struct {
 	cacheDir string
 	maxSize  int64         // 最大缓存大小（字节）
 	maxAge   time.Duration // 最大缓存时间
+	logger   *slog.Logger
 }
 
 // NewCacheManager 创建缓存管理器
@@ -30,6 +33,16 @@ func NewCacheManager(cacheDir string, maxSize int64, maxAge time.Duration) *Cach
 	}
 }
 
+// SetLogger 设置日志记录器
+func (cm *CacheManager) SetLogger(logger *slog.Logger) {
+	cm.logger = logger
+}
+
+// log 获取日志记录器，未设置时使用默认记录器
+func (cm *CacheManager) log() *slog.Logger {
+	return logging.OrDefault(cm.logger)
+}
+
 // Init 初始化缓存目录
 func (cm *CacheManager) Init() error {
 	if err := os.MkdirAll(cm.cacheDir, 0755); err != nil {
@@ -64,6 +77,21 @@ func (cm *CacheManager) GetCacheKey(dep config.Dependency) string {
 	if dep.Source.Hash != "" {
 		h.Write([]byte(dep.Source.Hash))
 	}
+	if dep.Source.Commit != "" {
+		h.Write([]byte(dep.Source.Commit))
+	}
+	if dep.Source.Branch != "" {
+		h.Write([]byte(dep.Source.Branch))
+	}
+	if dep.Source.SubmodulesEnabled() {
+		h.Write([]byte(fmt.Sprintf("submodules%v", dep.Source.Submodules.Paths)))
+	}
+	if len(dep.Source.SparsePaths) > 0 {
+		h.Write([]byte(fmt.Sprintf("sparse%v", dep.Source.SparsePaths)))
+	}
+	if dep.Source.LFSEnabled() {
+		h.Write([]byte(fmt.Sprintf("lfs%v", dep.Source.LFS.Include)))
+	}
 	return hex.EncodeToString(h.Sum(nil))
 }
 
@@ -144,11 +172,21 @@ func (cm *CacheManager) GetMetadataPath(dep config.Dependency) string {
 
 // IsCachedDownloads 检查是否已缓存
 func (cm *CacheManager) IsCachedDownloads(dep config.Dependency) bool {
+	// 本地路径源码的内容随时会变，不使用共享缓存
+	if dep.Source.IsLocalPath() {
+		return false
+	}
+
 	cachePath := cm.GetDownloadCachePath(dep)
 	if _, err := os.Stat(cachePath); err != nil {
 		return false
 	}
 
+	// 配置了签名时，只使用签名校验通过后缓存的源码
+	if dep.Source.Signature != "" && cm.DownloadSignature(dep) == nil {
+		return false
+	}
+
 	// 检查缓存是否过期
 	// if cm.isExpired(cachePath) {
 	// 	cm.Invalidate(dep)
@@ -157,11 +195,14 @@ func (cm *CacheManager) IsCachedDownloads(dep config.Dependency) bool {
 	return true
 }
 
-// IsBuildCached 检查构建是否已缓存
+// IsBuildCached 检查构建是否已缓存（包括兼容的构建标签）
 func (cm *CacheManager) IsBuildCached(dep config.Dependency, buildTag *config.BuildTag) bool {
+	if dep.Source.IsLocalPath() {
+		return false
+	}
+
 	// {{.BuildFlyGlobalDir}}/install/{name}/{version}/{build_tag}
-	cachePath := cm.GetBuildCachePath(dep, buildTag)
-	if _, err := os.Stat(cachePath); err != nil {
+	if !cm.isExactBuildCached(dep, buildTag) && cm.FindCompatibleBuild(dep, buildTag) == nil {
 		return false
 	}
 
@@ -176,6 +217,10 @@ func (cm *CacheManager) IsBuildCached(dep config.Dependency, buildTag *config.Bu
 
 // Store 存储到缓存
 func (cm *CacheManager) Store(dep config.Dependency, sourcePath string) error {
+	if dep.Source.IsLocalPath() {
+		return errors.CacheError(fmt.Sprintf("local path dependency %s is not cached", dep.Name))
+	}
+
 	cachePath := cm.GetDownloadCachePath(dep)
 
 	// 确保缓存目录存在
@@ -194,6 +239,10 @@ func (cm *CacheManager) Store(dep config.Dependency, sourcePath string) error {
 
 // StoreBuild 存储构建结果到缓存
 func (cm *CacheManager) StoreBuild(dep config.Dependency, buildPath string, buildTag *config.BuildTag) error {
+	if dep.Source.IsLocalPath() {
+		return errors.CacheError(fmt.Sprintf("local path dependency %s is not cached", dep.Name))
+	}
+
 	cachePath := cm.GetBuildCachePath(dep, buildTag)
 
 	// 确保缓存目录存在
@@ -201,9 +250,15 @@ func (cm *CacheManager) StoreBuild(dep config.Dependency, buildPath string, buil
 		return errors.CacheErrorWithCause(err, "failed to create build cache directory")
 	}
 
-	// 如果是目录，递归复制
+	// 如果是目录，递归复制并将安装前缀转换为可重定位的形式
 	if info, err := os.Stat(buildPath); err == nil && info.IsDir() {
-		return utils.CopyDir(buildPath, cachePath)
+		if err := utils.CopyDir(buildPath, cachePath); err != nil {
+			return err
+		}
+		if err := cm.makeRelocatable(cachePath, buildPath); err != nil {
+			return err
+		}
+		return cm.recordBuildTag(dep, buildTag)
 	}
 
 	// 复制文件
@@ -220,26 +275,33 @@ func (cm *CacheManager) Retrieve(dep config.Dependency, targetPath string) error
 
 	// 如果是目录，递归复制
 	if info, err := os.Stat(cachePath); err == nil && info.IsDir() {
-		fmt.Printf("Copying directory from %s to %s\n", cachePath, targetPath)
+		cm.log().Debug("Copying cached directory", "from", cachePath, "to", targetPath)
 		return utils.CopyDir(cachePath, targetPath)
 	}
 
 	// 复制文件
-	fmt.Printf("Copying file from %s to %s\n", cachePath, targetPath)
+	cm.log().Debug("Copying cached file", "from", cachePath, "to", targetPath)
 	return utils.CopyFile(cachePath, targetPath)
 }
 
-// RetrieveBuild 从缓存检索构建结果
+// RetrieveBuild 从缓存检索构建结果，没有完全相同的构建标签时使用兼容的构建
 func (cm *CacheManager) RetrieveBuild(dep config.Dependency, targetPath string, buildTag *config.BuildTag) error {
+	if compatible := cm.FindCompatibleBuild(dep, buildTag); compatible != nil && !compatible.Equals(buildTag) {
+		cm.log().Info("Using compatible cached build", "build_tag", compatible.String())
+		buildTag = compatible
+	}
 	cachePath := cm.GetBuildCachePath(dep, buildTag)
 
 	if _, err := os.Stat(cachePath); err != nil {
 		return errors.CacheError(fmt.Sprintf("build cache not found for dependency %s", dep.Name))
 	}
 
-	// 如果是目录，递归复制
+	// 如果是目录，递归复制并替换安装前缀占位符
 	if info, err := os.Stat(cachePath); err == nil && info.IsDir() {
-		return utils.CopyDir(cachePath, targetPath)
+		if err := utils.CopyDir(cachePath, targetPath); err != nil {
+			return err
+		}
+		return restorePrefix(targetPath, targetPath)
 	}
 
 	// 复制文件
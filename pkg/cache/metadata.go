@@ -0,0 +1,350 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"buildfly/internal/errors"
+	"buildfly/pkg/config"
+)
+
+// 测试状态
+const (
+	TestStatusPassed  = "passed"
+	TestStatusFailed  = "failed"
+	TestStatusSkipped = "skipped"
+)
+
+// BuildMetadata 构建元数据，按依赖版本和构建标签记录
+type BuildMetadata struct {
+	Name      string      `json:"name"`
+	Version   string      `json:"version"`
+	BuildTag  string      `json:"build_tag,omitempty"`
+	UpdatedAt time.Time   `json:"updated_at"`
+	Test      *TestResult `json:"test,omitempty"`
+
+	// Hermetic 构建时是否使用密封环境，Environment 为密封构建实际使用的环境变量。
+	// 环境中可能包含凭据和代理地址，元数据位于共享缓存中，因此只保存变量名和值的 SHA256
+	Hermetic    bool              `json:"hermetic,omitempty"`
+	Environment map[string]string `json:"environment,omitempty"`
+
+	// Requirements 构建后校验得到的产物运行时要求
+	Requirements *RuntimeRequirements `json:"requirements,omitempty"`
+
+	// Signature 构建使用的源码压缩包在解压前校验通过的签名
+	Signature *SourceSignature `json:"signature,omitempty"`
+}
+
+// DownloadMetadata 下载缓存的元数据，按依赖的缓存键记录
+type DownloadMetadata struct {
+	Name      string           `json:"name"`
+	Version   string           `json:"version"`
+	UpdatedAt time.Time        `json:"updated_at"`
+	SHA256    string           `json:"sha256,omitempty"` // 源码压缩包的 SHA256，使用缓存时与锁文件比对
+	Signature *SourceSignature `json:"signature,omitempty"`
+}
+
+// SourceSignature 源码压缩包的签名校验结果
+type SourceSignature struct {
+	Signature   string    `json:"signature"` // 签名文件的 URL 或路径
+	Type        string    `json:"type"`      // openpgp, minisign, ssh
+	Key         string    `json:"key"`       // trusted_keys 中的公钥名称
+	Fingerprint string    `json:"fingerprint"`
+	Signer      string    `json:"signer,omitempty"` // OpenPGP 用户 ID、minisign 可信注释或 SSH 公钥注释
+	VerifiedAt  time.Time `json:"verified_at"`
+}
+
+// RuntimeRequirements 构建产物的运行时要求
+type RuntimeRequirements struct {
+	Arch       string    `json:"arch,omitempty"`
+	GLIBC      string    `json:"glibc,omitempty"`   // 需要的最高 GLIBC 版本
+	GLIBCXX    string    `json:"glibcxx,omitempty"` // 需要的最高 GLIBCXX 版本
+	Files      int       `json:"files"`
+	Problems   []string  `json:"problems,omitempty"`
+	VerifiedAt time.Time `json:"verified_at"`
+}
+
+// TestResult 依赖测试结果
+type TestResult struct {
+	Status   string        `json:"status"` // passed, failed, skipped
+	Command  string        `json:"command,omitempty"`
+	Duration time.Duration `json:"duration"`
+	RunAt    time.Time     `json:"run_at"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// GetBuildMetadataPath 获取构建元数据路径
+// 规范路径：{cache_dir}/metadata/{name}/{version}/{build_tag}.json
+func (cm *CacheManager) GetBuildMetadataPath(dep config.Dependency, buildTag *config.BuildTag) string {
+	buildTagDir := "default"
+	if buildTag != nil {
+		buildTagDir = buildTag.ToDirName()
+	}
+	return filepath.Join(cm.cacheDir, "metadata", dep.Name, dep.Version, buildTagDir+".json")
+}
+
+// LoadBuildMetadata 读取构建元数据，不存在时返回空元数据
+func (cm *CacheManager) LoadBuildMetadata(dep config.Dependency, buildTag *config.BuildTag) (*BuildMetadata, error) {
+	metadata := &BuildMetadata{
+		Name:     dep.Name,
+		Version:  dep.Version,
+		BuildTag: buildTag.String(),
+	}
+
+	data, err := os.ReadFile(cm.GetBuildMetadataPath(dep, buildTag))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return metadata, nil
+		}
+		return nil, errors.CacheErrorWithCause(err, "failed to read build metadata")
+	}
+
+	if err := json.Unmarshal(data, metadata); err != nil {
+		return nil, errors.CacheErrorWithCause(err, "failed to parse build metadata")
+	}
+
+	return metadata, nil
+}
+
+// SaveBuildMetadata 保存构建元数据
+func (cm *CacheManager) SaveBuildMetadata(dep config.Dependency, buildTag *config.BuildTag, metadata *BuildMetadata) error {
+	metadataPath := cm.GetBuildMetadataPath(dep, buildTag)
+	if err := os.MkdirAll(filepath.Dir(metadataPath), 0755); err != nil {
+		return errors.CacheErrorWithCause(err, "failed to create metadata directory")
+	}
+
+	metadata.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return errors.CacheErrorWithCause(err, "failed to marshal build metadata")
+	}
+
+	if err := os.WriteFile(metadataPath, data, 0644); err != nil {
+		return errors.CacheErrorWithCause(err, "failed to write build metadata")
+	}
+
+	return nil
+}
+
+// RecordTestResult 记录依赖测试结果
+func (cm *CacheManager) RecordTestResult(dep config.Dependency, buildTag *config.BuildTag, result *TestResult) error {
+	metadata, err := cm.LoadBuildMetadata(dep, buildTag)
+	if err != nil {
+		return err
+	}
+
+	metadata.Test = result
+	return cm.SaveBuildMetadata(dep, buildTag, metadata)
+}
+
+// RecordRequirements 记录构建产物的运行时要求
+func (cm *CacheManager) RecordRequirements(dep config.Dependency, buildTag *config.BuildTag, requirements *RuntimeRequirements) error {
+	metadata, err := cm.LoadBuildMetadata(dep, buildTag)
+	if err != nil {
+		return err
+	}
+
+	metadata.Requirements = requirements
+	return cm.SaveBuildMetadata(dep, buildTag, metadata)
+}
+
+// RecordBuildEnvironment 记录构建环境，密封构建时保存实际使用的环境变量名和值的哈希
+// 非密封构建时清除之前记录的环境，避免元数据与缓存内容不一致
+func (cm *CacheManager) RecordBuildEnvironment(dep config.Dependency, buildTag *config.BuildTag, hermetic bool, env map[string]string) error {
+	metadata, err := cm.LoadBuildMetadata(dep, buildTag)
+	if err != nil {
+		return err
+	}
+
+	if !hermetic {
+		if !metadata.Hermetic && metadata.Environment == nil {
+			return nil
+		}
+		env = nil
+	}
+
+	metadata.Hermetic = hermetic
+	metadata.Environment = hashEnvironment(env)
+	return cm.SaveBuildMetadata(dep, buildTag, metadata)
+}
+
+// hashEnvironment 将环境变量的值替换为 "sha256:<hex>"，可以比较两次构建的环境而不泄露值
+func hashEnvironment(env map[string]string) map[string]string {
+	if env == nil {
+		return nil
+	}
+	hashed := make(map[string]string, len(env))
+	for key, value := range env {
+		sum := sha256.Sum256([]byte(value))
+		hashed[key] = "sha256:" + hex.EncodeToString(sum[:])
+	}
+	return hashed
+}
+
+// RecordSourceSignature 记录构建使用的源码签名，依赖没有配置签名时清除之前的记录
+func (cm *CacheManager) RecordSourceSignature(dep config.Dependency, buildTag *config.BuildTag, signature *SourceSignature) error {
+	metadata, err := cm.LoadBuildMetadata(dep, buildTag)
+	if err != nil {
+		return err
+	}
+
+	if signature == nil && metadata.Signature == nil {
+		return nil
+	}
+
+	metadata.Signature = signature
+	return cm.SaveBuildMetadata(dep, buildTag, metadata)
+}
+
+// loadDownloadMetadata 读取下载缓存的元数据，不存在或无法解析时返回 nil
+func (cm *CacheManager) loadDownloadMetadata(dep config.Dependency) *DownloadMetadata {
+	data, err := os.ReadFile(cm.GetMetadataPath(dep))
+	if err != nil {
+		return nil
+	}
+	var metadata DownloadMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil
+	}
+	return &metadata
+}
+
+// updateDownloadMetadata 修改并保存下载缓存的元数据
+func (cm *CacheManager) updateDownloadMetadata(dep config.Dependency, update func(metadata *DownloadMetadata)) error {
+	metadataPath := cm.GetMetadataPath(dep)
+	if err := os.MkdirAll(filepath.Dir(metadataPath), 0755); err != nil {
+		return errors.CacheErrorWithCause(err, "failed to create metadata directory")
+	}
+
+	metadata := cm.loadDownloadMetadata(dep)
+	if metadata == nil || metadata.Version != dep.Version {
+		metadata = &DownloadMetadata{}
+	}
+	metadata.Name = dep.Name
+	metadata.Version = dep.Version
+	metadata.UpdatedAt = time.Now()
+	update(metadata)
+
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return errors.CacheErrorWithCause(err, "failed to marshal download metadata")
+	}
+
+	if err := os.WriteFile(metadataPath, data, 0644); err != nil {
+		return errors.CacheErrorWithCause(err, "failed to write download metadata")
+	}
+
+	return nil
+}
+
+// RecordDownloadSignature 记录下载的源码压缩包校验通过的签名
+func (cm *CacheManager) RecordDownloadSignature(dep config.Dependency, signature *SourceSignature) error {
+	return cm.updateDownloadMetadata(dep, func(metadata *DownloadMetadata) {
+		metadata.Signature = signature
+	})
+}
+
+// DownloadSignature 获取下载缓存记录的签名，没有记录或记录的不是当前配置的签名文件时返回 nil
+func (cm *CacheManager) DownloadSignature(dep config.Dependency) *SourceSignature {
+	if dep.Source.Signature == "" {
+		return nil
+	}
+
+	metadata := cm.loadDownloadMetadata(dep)
+	if metadata == nil || metadata.Signature == nil {
+		return nil
+	}
+	if metadata.Signature.Signature != dep.Source.Signature {
+		return nil
+	}
+	return metadata.Signature
+}
+
+// RecordDownloadChecksum 记录下载的源码压缩包的 SHA256
+func (cm *CacheManager) RecordDownloadChecksum(dep config.Dependency, sum string) error {
+	return cm.updateDownloadMetadata(dep, func(metadata *DownloadMetadata) {
+		metadata.SHA256 = sum
+	})
+}
+
+// DownloadChecksum 获取下载缓存记录的压缩包 SHA256，没有记录时返回空字符串
+func (cm *CacheManager) DownloadChecksum(dep config.Dependency) string {
+	metadata := cm.loadDownloadMetadata(dep)
+	if metadata == nil {
+		return ""
+	}
+	return metadata.SHA256
+}
+
+// recordBuildTag 保存构建元数据，用于按构建标签兼容性查找缓存
+func (cm *CacheManager) recordBuildTag(dep config.Dependency, buildTag *config.BuildTag) error {
+	if buildTag == nil {
+		return nil
+	}
+
+	metadata, err := cm.LoadBuildMetadata(dep, buildTag)
+	if err != nil {
+		return err
+	}
+
+	metadata.BuildTag = buildTag.String()
+	return cm.SaveBuildMetadata(dep, buildTag, metadata)
+}
+
+// ListCachedBuildTags 列出依赖已缓存构建的构建标签
+func (cm *CacheManager) ListCachedBuildTags(dep config.Dependency) []*config.BuildTag {
+	metadataDir := filepath.Join(cm.cacheDir, "metadata", dep.Name, dep.Version)
+	entries, err := os.ReadDir(metadataDir)
+	if err != nil {
+		return nil
+	}
+
+	var buildTags []*config.BuildTag
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(metadataDir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		var metadata BuildMetadata
+		if err := json.Unmarshal(data, &metadata); err != nil || metadata.BuildTag == "" {
+			continue
+		}
+
+		buildTag, err := config.ParseRecordedBuildTag(metadata.BuildTag)
+		if err != nil {
+			continue
+		}
+		if _, err := os.Stat(cm.GetBuildCachePath(dep, buildTag)); err != nil {
+			continue
+		}
+		buildTags = append(buildTags, buildTag)
+	}
+
+	return buildTags
+}
+
+// FindCompatibleBuild 查找与构建标签兼容的已缓存构建，完全相同的标签优先
+// 没有兼容的缓存时返回 nil
+func (cm *CacheManager) FindCompatibleBuild(dep config.Dependency, buildTag *config.BuildTag) *config.BuildTag {
+	if buildTag == nil {
+		return nil
+	}
+	if cm.isExactBuildCached(dep, buildTag) {
+		return buildTag
+	}
+	return config.SelectCompatibleBuildTag(cm.ListCachedBuildTags(dep), buildTag)
+}
+
+// isExactBuildCached 检查完全相同构建标签的构建是否已缓存
+func (cm *CacheManager) isExactBuildCached(dep config.Dependency, buildTag *config.BuildTag) bool {
+	_, err := os.Stat(cm.GetBuildCachePath(dep, buildTag))
+	return err == nil
+}
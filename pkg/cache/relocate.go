@@ -0,0 +1,236 @@
+package cache
+
+import (
+	"bytes"
+	"debug/elf"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"buildfly/internal/errors"
+)
+
+// PrefixPlaceholder 缓存中替代安装前缀的占位符
+const PrefixPlaceholder = "@BUILDFLY_PREFIX@"
+
+// relocatableExtensions 需要重写安装前缀的文本文件扩展名
+var relocatableExtensions = map[string]bool{
+	".pc":    true, // pkg-config
+	".cmake": true, // CMake 包配置（*Config.cmake, *Targets.cmake 等）
+	".la":    true, // libtool
+}
+
+// makeRelocatable 将缓存中的构建结果转换为可重定位的形式
+// 文本文件中的安装前缀替换为占位符，ELF 的 RUNPATH 改写为相对于 $ORIGIN 的路径
+func (cm *CacheManager) makeRelocatable(root, prefix string) error {
+	prefix, err := filepath.Abs(prefix)
+	if err != nil {
+		return errors.CacheErrorWithCause(err, "failed to resolve install prefix")
+	}
+
+	return walkRegularFiles(root, func(path string) error {
+		if isRelocatableText(path) {
+			return replaceInFile(path, prefix, PrefixPlaceholder)
+		}
+
+		// 文件在原安装目录中的位置，用于计算相对 RUNPATH
+		rel, err := filepath.Rel(root, filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		if err := relativizeRunpath(path, prefix, filepath.Join(prefix, rel)); err != nil {
+			cm.log().Warn(err.Error())
+		}
+		return nil
+	})
+}
+
+// restorePrefix 将恢复后的构建结果中的占位符替换为实际的安装前缀
+func restorePrefix(root, prefix string) error {
+	prefix, err := filepath.Abs(prefix)
+	if err != nil {
+		return errors.CacheErrorWithCause(err, "failed to resolve install prefix")
+	}
+
+	return walkRegularFiles(root, func(path string) error {
+		if isRelocatableText(path) {
+			return replaceInFile(path, PrefixPlaceholder, prefix)
+		}
+		return nil
+	})
+}
+
+// walkRegularFiles 遍历目录中的普通文件
+func walkRegularFiles(root string, fn func(path string) error) error {
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		return fn(path)
+	})
+	if err != nil {
+		return errors.CacheErrorWithCause(err, "failed to relocate build")
+	}
+	return nil
+}
+
+// isRelocatableText 检查文件是否为需要重写前缀的文本文件（包括 *-config 等脚本）
+func isRelocatableText(path string) bool {
+	if relocatableExtensions[filepath.Ext(path)] {
+		return true
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	header := make([]byte, 2)
+	n, _ := file.Read(header)
+	return n == 2 && string(header) == "#!"
+}
+
+// replaceInFile 替换文件中的路径前缀，保留文件权限
+func replaceInFile(path, old, new string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if !bytes.Contains(data, []byte(old)) {
+		return nil
+	}
+
+	replaced := replacePathPrefix(data, []byte(old), []byte(new))
+	if bytes.Equal(replaced, data) {
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, replaced, info.Mode().Perm())
+}
+
+// replacePathPrefix 替换 data 中作为完整路径或路径前缀出现的 old
+// 只在 old 之后是路径分隔符或不属于文件名的字符时替换，/x/install2、/x/install-debug 等同级路径不受影响
+func replacePathPrefix(data, old, new []byte) []byte {
+	var out bytes.Buffer
+	for {
+		i := bytes.Index(data, old)
+		if i < 0 {
+			out.Write(data)
+			return out.Bytes()
+		}
+
+		end := i + len(old)
+		out.Write(data[:i])
+		if end < len(data) && isFileNameChar(data[end]) {
+			out.Write(old)
+		} else {
+			out.Write(new)
+		}
+		data = data[end:]
+	}
+}
+
+// isFileNameChar 是否为可以出现在文件名中间的字符
+func isFileNameChar(c byte) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		return true
+	}
+	return c == '.' || c == '_' || c == '-' || c == '+' || c == '@' || c == '~'
+}
+
+// relativizeRunpath 将 ELF 文件中指向安装前缀的 RUNPATH/RPATH 改写为 $ORIGIN 相对路径
+// 新路径写回 .dynstr 中原字符串的位置，因此不能比原路径长
+func relativizeRunpath(path, prefix, origin string) error {
+	f, err := elf.Open(path)
+	if err != nil {
+		// 不是 ELF 文件
+		return nil
+	}
+	defer f.Close()
+
+	dynstr := f.Section(".dynstr")
+	if dynstr == nil {
+		return nil
+	}
+
+	var offsets []uint64
+	for _, tag := range []elf.DynTag{elf.DT_RUNPATH, elf.DT_RPATH} {
+		values, err := f.DynValue(tag)
+		if err != nil {
+			return nil
+		}
+		offsets = append(offsets, values...)
+	}
+	if len(offsets) == 0 {
+		return nil
+	}
+
+	data, err := dynstr.Data()
+	if err != nil {
+		return fmt.Errorf("failed to read .dynstr of %s: %w", path, err)
+	}
+
+	out, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer out.Close()
+
+	for _, offset := range offsets {
+		if offset >= uint64(len(data)) {
+			continue
+		}
+		end := bytes.IndexByte(data[offset:], 0)
+		if end < 0 {
+			continue
+		}
+
+		oldValue := string(data[offset : offset+uint64(end)])
+		newValue := relativeRunpath(oldValue, prefix, origin)
+		if newValue == oldValue {
+			continue
+		}
+		if len(newValue) > end {
+			return fmt.Errorf("cannot relocate RUNPATH of %s: %q is longer than %q", path, newValue, oldValue)
+		}
+
+		buf := make([]byte, end)
+		copy(buf, newValue)
+		if _, err := out.WriteAt(buf, int64(dynstr.Offset+offset)); err != nil {
+			return fmt.Errorf("failed to rewrite RUNPATH of %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// relativeRunpath 将 RUNPATH 中位于安装前缀下的路径改写为相对于 origin 的 $ORIGIN 路径
+func relativeRunpath(runpath, prefix, origin string) string {
+	entries := strings.Split(runpath, ":")
+	for i, entry := range entries {
+		if entry != prefix && !strings.HasPrefix(entry, prefix+"/") {
+			continue
+		}
+		rel, err := filepath.Rel(origin, entry)
+		if err != nil {
+			continue
+		}
+		if rel == "." {
+			entries[i] = "$ORIGIN"
+		} else {
+			entries[i] = "$ORIGIN/" + filepath.ToSlash(rel)
+		}
+	}
+	return strings.Join(entries, ":")
+}
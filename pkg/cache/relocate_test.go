@@ -0,0 +1,172 @@
+package cache
+
+import (
+	"debug/elf"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"buildfly/pkg/config"
+)
+
+func TestStoreBuildRelocatesPrefix(t *testing.T) {
+	tempDir := t.TempDir()
+
+	cacheManager := NewCacheManager(filepath.Join(tempDir, "cache"), 1024*1024*1024, 24*time.Hour)
+	if err := cacheManager.Init(); err != nil {
+		t.Fatalf("Failed to init cache: %v", err)
+	}
+
+	dep := config.Dependency{Name: "test-lib", Version: "1.0.0", BuildSystem: "cmake"}
+	buildTag := &config.BuildTag{Arch: "x86_64", Platform: "linux"}
+
+	// 模拟安装目录中嵌入了绝对前缀的 pkg-config 文件，同级目录的路径不属于安装前缀
+	installDir := filepath.Join(tempDir, "project-a", "install")
+	pcFile := filepath.Join("lib", "pkgconfig", "test-lib.pc")
+	if err := os.MkdirAll(filepath.Join(installDir, "lib", "pkgconfig"), 0755); err != nil {
+		t.Fatalf("Failed to create install dir: %v", err)
+	}
+	content := "prefix=" + installDir + "\nlibdir=" + installDir + "/lib\nextra=" + installDir + "-debug/lib\n"
+	if err := os.WriteFile(filepath.Join(installDir, pcFile), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write pc file: %v", err)
+	}
+
+	if err := cacheManager.StoreBuild(dep, installDir, buildTag); err != nil {
+		t.Fatalf("Failed to store build: %v", err)
+	}
+
+	// 缓存中使用占位符，原安装目录不受影响
+	cached, err := os.ReadFile(filepath.Join(cacheManager.GetBuildCachePath(dep, buildTag), pcFile))
+	if err != nil {
+		t.Fatalf("Failed to read cached pc file: %v", err)
+	}
+	wantCached := "prefix=" + PrefixPlaceholder + "\nlibdir=" + PrefixPlaceholder + "/lib\nextra=" + installDir + "-debug/lib\n"
+	if string(cached) != wantCached {
+		t.Errorf("Cached pc file = %q, want %q", cached, wantCached)
+	}
+	original, _ := os.ReadFile(filepath.Join(installDir, pcFile))
+	if string(original) != content {
+		t.Errorf("Install dir should not be modified, got %q", original)
+	}
+
+	// 恢复到另一个项目时使用新的前缀
+	targetDir := filepath.Join(tempDir, "project-b", "install")
+	if err := cacheManager.RetrieveBuild(dep, targetDir, buildTag); err != nil {
+		t.Fatalf("Failed to retrieve build: %v", err)
+	}
+	restored, err := os.ReadFile(filepath.Join(targetDir, pcFile))
+	if err != nil {
+		t.Fatalf("Failed to read restored pc file: %v", err)
+	}
+	want := "prefix=" + targetDir + "\nlibdir=" + targetDir + "/lib\nextra=" + installDir + "-debug/lib\n"
+	if string(restored) != want {
+		t.Errorf("Restored pc file = %q, want %q", restored, want)
+	}
+}
+
+func TestReplacePathPrefix(t *testing.T) {
+	const prefix = "/x/install"
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"whole value", "prefix=/x/install\n", "prefix=@P@\n"},
+		{"subdirectory", "libdir=/x/install/lib\n", "libdir=@P@/lib\n"},
+		{"end of data", "/x/install", "@P@"},
+		{"quoted", `set(_IMPORT_PREFIX "/x/install")`, `set(_IMPORT_PREFIX "@P@")`},
+		{"path list", "/x/install/lib:/x/install/lib64", "@P@/lib:@P@/lib64"},
+		{"sibling with digit", "libdir=/x/install2/lib\n", "libdir=/x/install2/lib\n"},
+		{"sibling with suffix", "libdir=/x/install-debug/lib\n", "libdir=/x/install-debug/lib\n"},
+		{"sibling then prefix", "/x/install.old /x/install/bin", "/x/install.old @P@/bin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(replacePathPrefix([]byte(tt.input), []byte(prefix), []byte("@P@")))
+			if got != tt.want {
+				t.Errorf("replacePathPrefix(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRelativeRunpath(t *testing.T) {
+	tests := []struct {
+		runpath string
+		origin  string
+		want    string
+	}{
+		{"/x/install/lib", "/x/install/lib", "$ORIGIN"},
+		{"/x/install/lib", "/x/install/bin", "$ORIGIN/../lib"},
+		{"/x/install/lib:/usr/local/lib", "/x/install/lib", "$ORIGIN:/usr/local/lib"},
+		{"/x/install2/lib", "/x/install/lib", "/x/install2/lib"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.runpath+"@"+tt.origin, func(t *testing.T) {
+			if got := relativeRunpath(tt.runpath, "/x/install", tt.origin); got != tt.want {
+				t.Errorf("relativeRunpath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// testdata/libanswer.so 的 RUNPATH 指向 /opt/buildfly/install 下的目录，生成方式：
+//
+//	gcc -O2 -shared -fPIC -o libanswer.so answer.c \
+//	    -Wl,--enable-new-dtags,-rpath,/opt/buildfly/install/lib:/opt/buildfly/install/lib/private:/usr/local/lib
+//	strip libanswer.so
+func TestRelativizeRunpath(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "libanswer.so"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "libanswer.so")
+	if err := os.WriteFile(path, data, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// 共享库位于安装前缀的 lib 目录
+	if err := relativizeRunpath(path, "/opt/buildfly/install", "/opt/buildfly/install/lib"); err != nil {
+		t.Fatalf("relativizeRunpath() error = %v", err)
+	}
+
+	f, err := elf.Open(path)
+	if err != nil {
+		t.Fatalf("relocated file is not a valid ELF: %v", err)
+	}
+	defer f.Close()
+
+	runpaths, err := f.DynString(elf.DT_RUNPATH)
+	if err != nil || len(runpaths) != 1 {
+		t.Fatalf("DT_RUNPATH = %v (%v)", runpaths, err)
+	}
+	if want := "$ORIGIN:$ORIGIN/private:/usr/local/lib"; runpaths[0] != want {
+		t.Errorf("RUNPATH = %q, want %q", runpaths[0], want)
+	}
+
+	// 其他符号不受影响
+	symbols, err := f.DynamicSymbols()
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, symbol := range symbols {
+		found = found || symbol.Name == "answer"
+	}
+	if !found {
+		t.Error("relocated library lost the answer symbol")
+	}
+
+	// 不在安装前缀下的 RUNPATH 保持不变
+	if err := relativizeRunpath(path, "/opt/other", "/opt/other/lib"); err != nil {
+		t.Fatalf("relativizeRunpath() error = %v", err)
+	}
+	if again, _ := os.ReadFile(path); !strings.Contains(string(again), "$ORIGIN/private") {
+		t.Error("RUNPATH outside of prefix should not change")
+	}
+}
@@ -16,6 +16,19 @@ type BuildTag struct {
 	ABI      string `json:"abi,omitempty"`      // sysv, macho, msabi
 	Target   string `json:"target,omitempty"`   // 平台特定目标
 
+	// 可选的二进制兼容性字段，由 detect 检测，显式指定时才参与构建标签
+	ISA       string `json:"isa,omitempty"`                                  // x86-64, x86-64-v2, x86-64-v3, x86-64-v4
+	Libstdcxx string `json:"libstdcxx,omitempty"`                            // glibcxx_3.4.30
+	CXX11ABI  string `json:"cxx11_abi,omitempty" yaml:"cxx11_abi,omitempty"` // _GLIBCXX_USE_CXX11_ABI: 0, 1
+
+	// 构建变体
+	Link      string `json:"link,omitempty"`                                   // static, shared
+	Sanitizer string `json:"sanitizer,omitempty"`                              // asan, tsan, ubsan, msan，多个用 | 分隔
+	BuildType string `json:"build_type,omitempty" yaml:"build_type,omitempty"` // Debug, Release, RelWithDebInfo, MinSizeRel
+
+	// Extra 用户自定义维度，需要在配置的 build_tag_dimensions 中声明，例如 simd=avx2
+	Extra map[string]string `json:"extra,omitempty"`
+
 	// GPU 计算框架 - 互斥字段组
 	GPU *GPUInfo `json:"gpu,omitempty"`
 }
@@ -53,7 +66,13 @@ type OpenCLBackend struct {
 
 // ParseBuildTag 解析构建标签字符串
 // 示例: "arch=x86_64,platform=linux,runtime=glibc_2.35+,compiler=gcc_11+,std=cpp17,abi=sysv"
+// 只接受内置键，自定义维度使用 BuildTagDimensions.ParseBuildTag 解析
 func ParseBuildTag(tagStr string) (*BuildTag, error) {
+	return parseBuildTag(tagStr, nil)
+}
+
+// parseBuildTag 解析构建标签字符串，isDimension 判断未知键是否为自定义维度
+func parseBuildTag(tagStr string, isDimension func(key string) bool) (*BuildTag, error) {
 	if tagStr == "" {
 		return nil, fmt.Errorf("build tag string cannot be empty")
 	}
@@ -93,6 +112,18 @@ func ParseBuildTag(tagStr string) (*BuildTag, error) {
 			bt.ABI = value
 		case "target":
 			bt.Target = value
+		case "isa":
+			bt.ISA = value
+		case "libstdcxx":
+			bt.Libstdcxx = value
+		case "cxx11_abi":
+			bt.CXX11ABI = value
+		case "link":
+			bt.Link = value
+		case "sanitizer":
+			bt.Sanitizer = value
+		case "build_type":
+			bt.BuildType = value
 		case "cuda", "cuda_version":
 			if bt.GPU == nil {
 				bt.GPU = &GPUInfo{Backend: "cuda"}
@@ -174,6 +205,11 @@ func ParseBuildTag(tagStr string) (*BuildTag, error) {
 				}
 			}
 		default:
+			// 已声明的自定义维度
+			if isDimension != nil && isDimension(key) {
+				bt.SetExtra(key, value)
+				continue
+			}
 			return nil, fmt.Errorf("unknown build tag key: %s", key)
 		}
 	}
@@ -210,6 +246,29 @@ func (bt *BuildTag) String() string {
 	if bt.Target != "" {
 		parts = append(parts, fmt.Sprintf("target=%s", bt.Target))
 	}
+	if bt.ISA != "" {
+		parts = append(parts, fmt.Sprintf("isa=%s", bt.ISA))
+	}
+	if bt.Libstdcxx != "" {
+		parts = append(parts, fmt.Sprintf("libstdcxx=%s", bt.Libstdcxx))
+	}
+	if bt.CXX11ABI != "" {
+		parts = append(parts, fmt.Sprintf("cxx11_abi=%s", bt.CXX11ABI))
+	}
+	if bt.Link != "" {
+		parts = append(parts, fmt.Sprintf("link=%s", bt.Link))
+	}
+	if bt.Sanitizer != "" {
+		parts = append(parts, fmt.Sprintf("sanitizer=%s", bt.Sanitizer))
+	}
+	if bt.BuildType != "" {
+		parts = append(parts, fmt.Sprintf("build_type=%s", bt.BuildType))
+	}
+
+	// 自定义维度按名称排序，保证字符串和目录名稳定
+	for _, key := range bt.extraKeys() {
+		parts = append(parts, fmt.Sprintf("%s=%s", key, bt.Extra[key]))
+	}
 
 	// 添加 GPU 信息
 	if bt.GPU != nil {
@@ -265,7 +324,7 @@ func (bt *BuildTag) ToDirName() string {
 	return dirName
 }
 
-// Validate 验证构建标签的有效性
+// Validate 验证构建标签的有效性，自定义维度由 BuildTagDimensions.ValidateBuildTag 验证
 func (bt *BuildTag) Validate() error {
 	if bt == nil {
 		return fmt.Errorf("build tag is nil")
@@ -303,6 +362,46 @@ func (bt *BuildTag) Validate() error {
 		}
 	}
 
+	// 验证指令集级别
+	if bt.ISA != "" && isaLevel(bt.ISA) < 0 {
+		return fmt.Errorf("invalid ISA level: %s", bt.ISA)
+	}
+
+	// 验证 libstdc++ 版本
+	if bt.Libstdcxx != "" {
+		if name, version, _ := ParseVersionedValue(bt.Libstdcxx); name != "glibcxx" || version == "" {
+			return fmt.Errorf("invalid libstdc++ version: %s", bt.Libstdcxx)
+		}
+	}
+
+	// 验证 C++11 ABI
+	if bt.CXX11ABI != "" && bt.CXX11ABI != "0" && bt.CXX11ABI != "1" {
+		return fmt.Errorf("invalid cxx11_abi: %s", bt.CXX11ABI)
+	}
+
+	// 验证链接方式
+	if bt.Link != "" {
+		validLinks := []string{"static", "shared"}
+		if !contains(validLinks, bt.Link) {
+			return fmt.Errorf("invalid link type: %s", bt.Link)
+		}
+	}
+
+	// 验证 sanitizer
+	if bt.Sanitizer != "" {
+		if err := validateSanitizers(bt.Sanitizers()); err != nil {
+			return err
+		}
+	}
+
+	// 验证构建类型
+	if bt.BuildType != "" {
+		validBuildTypes := []string{"Debug", "Release", "RelWithDebInfo", "MinSizeRel"}
+		if !contains(validBuildTypes, bt.BuildType) {
+			return fmt.Errorf("invalid build type: %s", bt.BuildType)
+		}
+	}
+
 	// 验证 GPU 配置
 	if bt.GPU != nil {
 		if err := bt.GPU.Validate(); err != nil {
@@ -371,6 +470,14 @@ func (bt *BuildTag) Clone() *BuildTag {
 
 	clone := *bt
 
+	// 复制自定义维度
+	if bt.Extra != nil {
+		clone.Extra = make(map[string]string, len(bt.Extra))
+		for k, v := range bt.Extra {
+			clone.Extra[k] = v
+		}
+	}
+
 	// 深度复制 GPU 配置
 	if bt.GPU != nil {
 		gpuClone := *bt.GPU
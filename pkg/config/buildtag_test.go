@@ -59,6 +59,28 @@ func TestParseBuildTag(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:  "link and sanitizer variants",
+			input: "arch=x86_64,platform=linux,link=static,sanitizer=asan|ubsan",
+			want: &BuildTag{
+				Arch:      "x86_64",
+				Platform:  "linux",
+				Link:      "static",
+				Sanitizer: "asan|ubsan",
+			},
+			wantErr: false,
+		},
+		{
+			name:  "cpu and libstdc++ fields",
+			input: "arch=x86_64,isa=x86-64-v3,libstdcxx=glibcxx_3.4.30,cxx11_abi=1",
+			want: &BuildTag{
+				Arch:      "x86_64",
+				ISA:       "x86-64-v3",
+				Libstdcxx: "glibcxx_3.4.30",
+				CXX11ABI:  "1",
+			},
+			wantErr: false,
+		},
 		{
 			name:    "empty string",
 			input:   "",
@@ -162,6 +184,27 @@ func TestBuildTagString(t *testing.T) {
 			},
 			want: "arch=x86_64,platform=linux,runtime=glibc_2.35,compiler=gcc_11,std=cpp17,abi=sysv",
 		},
+		{
+			name: "with variants",
+			bt: &BuildTag{
+				Arch:      "x86_64",
+				Platform:  "linux",
+				Link:      "shared",
+				Sanitizer: "tsan",
+			},
+			want: "arch=x86_64,platform=linux,link=shared,sanitizer=tsan",
+		},
+		{
+			name: "with cpu and libstdc++ fields",
+			bt: &BuildTag{
+				Arch:      "x86_64",
+				ISA:       "x86-64-v2",
+				Libstdcxx: "glibcxx_3.4.28",
+				CXX11ABI:  "0",
+				Link:      "static",
+			},
+			want: "arch=x86_64,isa=x86-64-v2,libstdcxx=glibcxx_3.4.28,cxx11_abi=0,link=static",
+		},
 		{
 			name: "with cuda",
 			bt: &BuildTag{
@@ -217,6 +260,16 @@ func TestBuildTagToDirName(t *testing.T) {
 			},
 			want: "arch-x86_64,platform-linux,runtime-glibc_2.35,compiler-gcc_11,std-cpp17,abi-sysv",
 		},
+		{
+			name: "with variants",
+			bt: &BuildTag{
+				Arch:      "x86_64",
+				Platform:  "linux",
+				Link:      "static",
+				Sanitizer: "asan|ubsan",
+			},
+			want: "arch-x86_64,platform-linux,link-static,sanitizer-asanorubsan",
+		},
 		{
 			name: "with plus and pipe",
 			bt: &BuildTag{
@@ -284,6 +337,64 @@ func TestBuildTagValidate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid variants",
+			bt: &BuildTag{
+				Arch:      "x86_64",
+				Platform:  "linux",
+				Link:      "static",
+				Sanitizer: "asan|ubsan",
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid isa",
+			bt: &BuildTag{
+				Arch: "x86_64",
+				ISA:  "x86-64-v5",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid libstdcxx",
+			bt: &BuildTag{
+				Arch:      "x86_64",
+				Libstdcxx: "3.4.30",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid cxx11_abi",
+			bt: &BuildTag{
+				Arch:     "x86_64",
+				CXX11ABI: "yes",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid link",
+			bt: &BuildTag{
+				Arch: "x86_64",
+				Link: "dynamic",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid sanitizer",
+			bt: &BuildTag{
+				Arch:      "x86_64",
+				Sanitizer: "lsan",
+			},
+			wantErr: true,
+		},
+		{
+			name: "incompatible sanitizers",
+			bt: &BuildTag{
+				Arch:      "x86_64",
+				Sanitizer: "asan|tsan",
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid arch",
 			bt: &BuildTag{
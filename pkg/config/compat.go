@@ -0,0 +1,139 @@
+package config
+
+import "sort"
+
+// IsCompatibleWith 检查以 bt 构建的产物能否在 required 描述的环境中使用
+//
+// 兼容规则：
+//   - required 中未指定的平台维度视为任意值
+//   - arch 按别名规范化后比较，platform/std/abi/target/cxx11_abi 必须一致
+//   - 变体维度 link/sanitizer/build_type/GPU 和自定义维度双向严格比较，未指定表示默认（无），
+//     未指定变体的请求不会使用 asan、static、Debug 或 CUDA 等变体的产物
+//   - isa 较低指令集级别的产物可在较高级别的 CPU 上运行（x86-64-v2 的产物可在 x86-64-v3 上运行）
+//   - libstdcxx 与 runtime 相同，较旧版本的产物兼容较新的 libstdc++
+//   - runtime 同名时，较旧版本构建的产物兼容较新的运行时（glibc 2.31 的产物可在 2.35 上运行）
+//   - compiler 同一编译器家族时，较旧版本的产物可被较新版本使用（ABI 由 abi 维度保证一致）
+func (bt *BuildTag) IsCompatibleWith(required *BuildTag) bool {
+	if bt == nil || required == nil {
+		return bt.Equals(required)
+	}
+
+	if required.Arch != "" && required.Arch != "any" && NormalizeArch(bt.Arch) != NormalizeArch(required.Arch) {
+		return false
+	}
+
+	exact := []struct{ have, want string }{
+		{bt.Platform, required.Platform},
+		{bt.Std, required.Std},
+		{bt.ABI, required.ABI},
+		{bt.Target, required.Target},
+		{bt.CXX11ABI, required.CXX11ABI},
+	}
+	for _, field := range exact {
+		if field.want != "" && field.have != field.want {
+			return false
+		}
+	}
+
+	if bt.Link != required.Link || bt.Sanitizer != required.Sanitizer || bt.BuildType != required.BuildType ||
+		gpuString(bt.GPU) != gpuString(required.GPU) || !extraEqual(bt.Extra, required.Extra) {
+		return false
+	}
+
+	if required.ISA != "" && (bt.ISA == "" || isaLevel(bt.ISA) > isaLevel(required.ISA)) {
+		return false
+	}
+
+	return versionCompatible(bt.Runtime, required.Runtime) &&
+		versionCompatible(bt.Compiler, required.Compiler) &&
+		versionCompatible(bt.Libstdcxx, required.Libstdcxx)
+}
+
+// isaLevels x86-64 微架构级别，按从低到高排列
+var isaLevels = []string{"x86-64", "x86-64-v2", "x86-64-v3", "x86-64-v4"}
+
+// isaLevel 获取指令集级别的序号，未知级别返回 -1
+func isaLevel(isa string) int {
+	for i, level := range isaLevels {
+		if level == isa {
+			return i
+		}
+	}
+	return -1
+}
+
+// versionCompatible 检查带版本的值（例如 glibc_2.31）是否满足要求（例如 glibc_2.35+）
+// 名称必须一致，产物版本不能高于要求的版本；任意一方没有版本时要求完全一致
+func versionCompatible(have, want string) bool {
+	if want == "" {
+		return true
+	}
+
+	haveName, haveVersion, _ := ParseVersionedValue(have)
+	wantName, wantVersion, _ := ParseVersionedValue(want)
+	if haveName != wantName {
+		return false
+	}
+	if haveVersion == "" || wantVersion == "" {
+		return haveVersion == wantVersion
+	}
+
+	return CompareVersions(haveVersion, wantVersion) <= 0
+}
+
+// extraEqual 比较自定义维度，缺少的键与空值相同
+func extraEqual(a, b map[string]string) bool {
+	for key, value := range a {
+		if b[key] != value {
+			return false
+		}
+	}
+	for key, value := range b {
+		if a[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// gpuString 获取 GPU 配置的字符串表示，用于比较
+func gpuString(gpu *GPUInfo) string {
+	return (&BuildTag{GPU: gpu}).String()
+}
+
+// SelectCompatibleBuildTag 从候选构建标签中选择与 required 兼容的最佳标签
+// 完全相同的标签优先，其次选择 runtime、compiler 版本最接近（最高）的标签；没有兼容的标签时返回 nil
+func SelectCompatibleBuildTag(candidates []*BuildTag, required *BuildTag) *BuildTag {
+	var compatible []*BuildTag
+	for _, candidate := range candidates {
+		if candidate.Equals(required) {
+			return candidate
+		}
+		if candidate.IsCompatibleWith(required) {
+			compatible = append(compatible, candidate)
+		}
+	}
+
+	if len(compatible) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(compatible, func(i, j int) bool {
+		_, runtimeI, _ := ParseVersionedValue(compatible[i].Runtime)
+		_, runtimeJ, _ := ParseVersionedValue(compatible[j].Runtime)
+		if c := CompareVersions(runtimeI, runtimeJ); c != 0 {
+			return c > 0
+		}
+
+		_, compilerI, _ := ParseVersionedValue(compatible[i].Compiler)
+		_, compilerJ, _ := ParseVersionedValue(compatible[j].Compiler)
+		if c := CompareVersions(compilerI, compilerJ); c != 0 {
+			return c > 0
+		}
+
+		// 版本相同时按字符串排序，保证结果稳定
+		return compatible[i].String() < compatible[j].String()
+	})
+
+	return compatible[0]
+}
@@ -0,0 +1,101 @@
+package config
+
+import "testing"
+
+func TestBuildTagIsCompatibleWith(t *testing.T) {
+	host := &BuildTag{Arch: "x86_64", Platform: "linux", Runtime: "glibc_2.35", Compiler: "gcc_13", ABI: "sysv"}
+
+	tests := []struct {
+		name     string
+		artifact *BuildTag
+		want     bool
+	}{
+		{"identical", host.Clone(), true},
+		{"older glibc", &BuildTag{Arch: "x86_64", Platform: "linux", Runtime: "glibc_2.31+", Compiler: "gcc_13", ABI: "sysv"}, true},
+		{"newer glibc", &BuildTag{Arch: "x86_64", Platform: "linux", Runtime: "glibc_2.38", Compiler: "gcc_13", ABI: "sysv"}, false},
+		{"older gcc", &BuildTag{Arch: "x86_64", Platform: "linux", Runtime: "glibc_2.35", Compiler: "gcc_11", ABI: "sysv"}, true},
+		{"newer gcc", &BuildTag{Arch: "x86_64", Platform: "linux", Runtime: "glibc_2.35", Compiler: "gcc_14", ABI: "sysv"}, false},
+		{"different compiler family", &BuildTag{Arch: "x86_64", Platform: "linux", Runtime: "glibc_2.35", Compiler: "clang_11", ABI: "sysv"}, false},
+		{"different abi", &BuildTag{Arch: "x86_64", Platform: "linux", Runtime: "glibc_2.35", Compiler: "gcc_11", ABI: "msabi"}, false},
+		{"arch alias", &BuildTag{Arch: "amd64", Platform: "linux", Runtime: "glibc_2.35", Compiler: "gcc_13", ABI: "sysv"}, true},
+		{"different arch", &BuildTag{Arch: "arm64", Platform: "linux", Runtime: "glibc_2.35", Compiler: "gcc_13", ABI: "sysv"}, false},
+		{"musl vs glibc", &BuildTag{Arch: "x86_64", Platform: "linux", Runtime: "musl", Compiler: "gcc_13", ABI: "sysv"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.artifact.IsCompatibleWith(host); got != tt.want {
+				t.Errorf("IsCompatibleWith() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// 变体维度必须一致
+	static := &BuildTag{Arch: "x86_64", Platform: "linux", Link: "static"}
+	if static.IsCompatibleWith(&BuildTag{Arch: "x86_64", Platform: "linux", Link: "shared"}) {
+		t.Error("static build should not satisfy shared requirement")
+	}
+	variantTests := []struct {
+		name     string
+		artifact *BuildTag
+	}{
+		{"sanitizer", &BuildTag{Arch: "x86_64", Platform: "linux", Sanitizer: "asan"}},
+		{"link", &BuildTag{Arch: "x86_64", Platform: "linux", Link: "static"}},
+		{"build type", &BuildTag{Arch: "x86_64", Platform: "linux", BuildType: "Debug"}},
+		{"gpu", &BuildTag{Arch: "x86_64", Platform: "linux", GPU: &GPUInfo{Backend: "cuda", CUDA: &CUDABackend{Enabled: true, Version: "12.2"}}}},
+		{"custom dimension", &BuildTag{Arch: "x86_64", Platform: "linux", Extra: map[string]string{"openssl": "3"}}},
+	}
+	untagged := &BuildTag{Arch: "x86_64", Platform: "linux"}
+	for _, tt := range variantTests {
+		t.Run("untagged request rejects "+tt.name, func(t *testing.T) {
+			if tt.artifact.IsCompatibleWith(untagged) {
+				t.Errorf("%s build should not satisfy untagged request", tt.artifact.String())
+			}
+			if untagged.IsCompatibleWith(tt.artifact) {
+				t.Errorf("untagged build should not satisfy %s request", tt.artifact.String())
+			}
+		})
+	}
+
+	// 较低指令集级别和较旧 libstdc++ 的产物兼容，C++11 ABI 必须一致
+	v3 := &BuildTag{Arch: "x86_64", ISA: "x86-64-v3", Libstdcxx: "glibcxx_3.4.30", CXX11ABI: "1"}
+	cpuTests := []struct {
+		name     string
+		artifact *BuildTag
+		want     bool
+	}{
+		{"lower isa", &BuildTag{Arch: "x86_64", ISA: "x86-64-v2", Libstdcxx: "glibcxx_3.4.28", CXX11ABI: "1"}, true},
+		{"higher isa", &BuildTag{Arch: "x86_64", ISA: "x86-64-v4", Libstdcxx: "glibcxx_3.4.30", CXX11ABI: "1"}, false},
+		{"unknown isa", &BuildTag{Arch: "x86_64", Libstdcxx: "glibcxx_3.4.30", CXX11ABI: "1"}, false},
+		{"newer libstdc++", &BuildTag{Arch: "x86_64", ISA: "x86-64-v3", Libstdcxx: "glibcxx_3.4.32", CXX11ABI: "1"}, false},
+		{"old abi", &BuildTag{Arch: "x86_64", ISA: "x86-64-v3", Libstdcxx: "glibcxx_3.4.30", CXX11ABI: "0"}, false},
+	}
+	for _, tt := range cpuTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.artifact.IsCompatibleWith(v3); got != tt.want {
+				t.Errorf("IsCompatibleWith() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectCompatibleBuildTag(t *testing.T) {
+	host := &BuildTag{Arch: "x86_64", Platform: "linux", Runtime: "glibc_2.35", Compiler: "gcc_13"}
+
+	glibc228 := &BuildTag{Arch: "x86_64", Platform: "linux", Runtime: "glibc_2.28", Compiler: "gcc_11"}
+	glibc231 := &BuildTag{Arch: "x86_64", Platform: "linux", Runtime: "glibc_2.31", Compiler: "gcc_11"}
+	glibc238 := &BuildTag{Arch: "x86_64", Platform: "linux", Runtime: "glibc_2.38", Compiler: "gcc_13"}
+
+	if got := SelectCompatibleBuildTag([]*BuildTag{glibc228, glibc238, glibc231}, host); got != glibc231 {
+		t.Errorf("SelectCompatibleBuildTag() = %s, want %s", got.String(), glibc231.String())
+	}
+
+	exact := host.Clone()
+	if got := SelectCompatibleBuildTag([]*BuildTag{glibc231, exact}, host); got != exact {
+		t.Errorf("SelectCompatibleBuildTag() should prefer exact match, got %s", got.String())
+	}
+
+	if got := SelectCompatibleBuildTag([]*BuildTag{glibc238}, host); got != nil {
+		t.Errorf("SelectCompatibleBuildTag() = %s, want nil", got.String())
+	}
+}
@@ -0,0 +1,201 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Credential 解析后的主机凭据
+type Credential struct {
+	Username string
+	Password string
+	Token    string // 不为空时使用 Bearer 认证
+
+	AllowHTTP bool // 允许通过明文 http 发送
+}
+
+// Secrets 获取凭据中需要从日志和错误信息中隐藏的值
+func (c *Credential) Secrets() []string {
+	var secrets []string
+	for _, value := range []string{c.Password, c.Token} {
+		if value != "" {
+			secrets = append(secrets, value)
+		}
+	}
+	return secrets
+}
+
+// Validate 验证凭据配置，host 为配置中的主机键
+func (c *HostCredential) Validate(host string) error {
+	if host == "" || strings.Contains(host, "/") {
+		return fmt.Errorf("invalid credentials host %q: use a host name such as artifactory.example.com or *.example.com", host)
+	}
+	if _, err := path.Match(host, ""); err != nil {
+		return fmt.Errorf("invalid credentials host pattern %q: %w", host, err)
+	}
+
+	switch c.Type {
+	case "netrc":
+	case "bearer":
+		if c.Token == "" {
+			return fmt.Errorf("credentials for %s: token is required for type bearer", host)
+		}
+	case "basic":
+		if c.Username == "" {
+			return fmt.Errorf("credentials for %s: username is required for type basic", host)
+		}
+	case "command":
+		if c.Command == "" {
+			return fmt.Errorf("credentials for %s: command is required for type command", host)
+		}
+	case "":
+		return fmt.Errorf("credentials for %s: type is required (supported: netrc, bearer, basic, command)", host)
+	default:
+		return fmt.Errorf("credentials for %s: unsupported type %s (supported: netrc, bearer, basic, command)", host, c.Type)
+	}
+	return nil
+}
+
+// Resolve 获取访问 host 使用的凭据：展开 ${ENV}、从 netrc 查找或执行凭据命令
+func (c *HostCredential) Resolve(ctx context.Context, host string) (*Credential, error) {
+	hostname, _ := splitHostPort(host)
+
+	switch c.Type {
+	case "netrc":
+		login, password, ok := LookupNetrc(hostname)
+		if !ok {
+			return nil, fmt.Errorf("no netrc entry for %s in %s", hostname, NetrcPath())
+		}
+		return &Credential{Username: login, Password: password}, nil
+	case "bearer":
+		token := os.ExpandEnv(c.Token)
+		if token == "" {
+			return nil, fmt.Errorf("bearer token for %s is empty after expanding environment variables", host)
+		}
+		return &Credential{Token: token}, nil
+	case "basic":
+		return &Credential{Username: os.ExpandEnv(c.Username), Password: os.ExpandEnv(c.Password)}, nil
+	case "command":
+		// 命令由 shell 展开环境变量
+		return runCredentialCommand(ctx, c.Command, host)
+	default:
+		return nil, fmt.Errorf("unsupported credentials type: %s", c.Type)
+	}
+}
+
+// runCredentialCommand 执行凭据命令
+// 命令的标准输入为 git-credential 格式的 protocol=https 和 host= 行，环境变量 BUILDFLY_CREDENTIAL_HOST 为主机名；
+// 输出 username=/password=/token= 行，或者只输出一行作为 Bearer 令牌
+func runCredentialCommand(ctx context.Context, command, host string) (*Credential, error) {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("protocol=https\nhost=%s\n\n", host))
+	cmd.Env = append(os.Environ(), "BUILDFLY_CREDENTIAL_HOST="+host)
+	cmd.Stderr = os.Stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		// 命令输出可能包含凭据，只返回退出状态
+		return nil, fmt.Errorf("credentials command for %s failed: %w", host, err)
+	}
+	credential := parseCredentialOutput(string(output))
+	if credential == nil {
+		return nil, fmt.Errorf("credentials command for %s printed no credentials", host)
+	}
+	return credential, nil
+}
+
+// parseCredentialOutput 解析凭据命令的输出，没有凭据时返回 nil
+func parseCredentialOutput(output string) *Credential {
+	var (
+		credential Credential
+		lines      []string
+	)
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		lines = append(lines, line)
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "username":
+			credential.Username = value
+		case "password":
+			credential.Password = value
+		case "token":
+			credential.Token = value
+		}
+	}
+
+	if credential == (Credential{}) {
+		if len(lines) == 1 && !strings.Contains(lines[0], "=") {
+			return &Credential{Token: lines[0]}
+		}
+		return nil
+	}
+	return &credential
+}
+
+// MergeCredentials 合并全局配置和项目配置中的凭据
+// 全局配置中已经覆盖的主机以全局配置为准，项目配置只能为其他主机添加凭据，
+// 避免提交到仓库的配置替换用户的凭据（例如换成 command 类型执行任意命令）。返回合并结果和被忽略的项目主机键
+func MergeCredentials(global, project map[string]HostCredential) (map[string]HostCredential, []string) {
+	if len(project) == 0 {
+		return global, nil
+	}
+
+	merged := make(map[string]HostCredential, len(global)+len(project))
+	var ignored []string
+	for host, credential := range project {
+		if pattern, _ := MatchCredential(global, host); pattern != "" {
+			ignored = append(ignored, host)
+			continue
+		}
+		merged[host] = credential
+	}
+	for host, credential := range global {
+		merged[host] = credential
+	}
+	sort.Strings(ignored)
+	return merged, ignored
+}
+
+// MatchCredential 查找访问 host（可以带端口）时使用的凭据配置，返回匹配的主机键
+// 带端口的精确匹配优先，其次为主机名精确匹配，最后为最长的通配符模式
+func MatchCredential(credentials map[string]HostCredential, host string) (string, *HostCredential) {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	hostname, _ := splitHostPort(host)
+
+	for _, key := range []string{host, hostname} {
+		for pattern, credential := range credentials {
+			if strings.ToLower(pattern) == key {
+				return pattern, &credential
+			}
+		}
+	}
+
+	var (
+		best      string
+		bestMatch *HostCredential
+	)
+	for pattern, credential := range credentials {
+		if !strings.ContainsAny(pattern, "*?[") {
+			continue
+		}
+		if matched, _ := path.Match(strings.ToLower(pattern), hostname); !matched {
+			continue
+		}
+		if bestMatch == nil || len(pattern) > len(best) || (len(pattern) == len(best) && pattern < best) {
+			best, bestMatch = pattern, &credential
+		}
+	}
+	return best, bestMatch
+}
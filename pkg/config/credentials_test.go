@@ -0,0 +1,153 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostCredential_Validate(t *testing.T) {
+	tests := []struct {
+		name       string
+		host       string
+		credential HostCredential
+		wantErr    bool
+	}{
+		{"netrc", "artifactory.example.com", HostCredential{Type: "netrc"}, false},
+		{"bearer", "gitlab.example.com", HostCredential{Type: "bearer", Token: "${GITLAB_TOKEN}"}, false},
+		{"basic", "*.example.com", HostCredential{Type: "basic", Username: "ci", Password: "${PASS}"}, false},
+		{"command", "example.com:8443", HostCredential{Type: "command", Command: "pass show ci"}, false},
+		{"missing type", "example.com", HostCredential{}, true},
+		{"unknown type", "example.com", HostCredential{Type: "oauth"}, true},
+		{"bearer without token", "example.com", HostCredential{Type: "bearer"}, true},
+		{"basic without username", "example.com", HostCredential{Type: "basic", Password: "x"}, true},
+		{"command without command", "example.com", HostCredential{Type: "command"}, true},
+		{"url as host", "https://example.com/", HostCredential{Type: "netrc"}, true},
+		{"bad pattern", "[example.com", HostCredential{Type: "netrc"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.credential.Validate(tt.host)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestMatchCredential(t *testing.T) {
+	credentials := map[string]HostCredential{
+		"gitlab.example.com":      {Type: "bearer", Token: "exact"},
+		"gitlab.example.com:8443": {Type: "bearer", Token: "port"},
+		"*.example.com":           {Type: "bearer", Token: "wildcard"},
+		"*.repo.example.com":      {Type: "bearer", Token: "longer"},
+	}
+
+	tests := []struct {
+		host    string
+		pattern string
+	}{
+		{"gitlab.example.com", "gitlab.example.com"},
+		{"GitLab.Example.com", "gitlab.example.com"},
+		{"gitlab.example.com:443", "gitlab.example.com"},
+		{"gitlab.example.com:8443", "gitlab.example.com:8443"},
+		{"artifactory.example.com", "*.example.com"},
+		{"maven.repo.example.com", "*.repo.example.com"},
+		{"example.org", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			pattern, credential := MatchCredential(credentials, tt.host)
+			assert.Equal(t, tt.pattern, pattern)
+			assert.Equal(t, tt.pattern != "", credential != nil)
+		})
+	}
+}
+
+func TestParseCredentialOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   *Credential
+	}{
+		{"git credential format", "protocol=https\nhost=example.com\nusername=ci\npassword=secret\n", &Credential{Username: "ci", Password: "secret"}},
+		{"token key", "token=abc=def\n", &Credential{Token: "abc=def"}},
+		{"bare token", "  glpat-xyz  \n", &Credential{Token: "glpat-xyz"}},
+		{"empty", "\n", nil},
+		{"no credentials", "protocol=https\nhost=example.com\n", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseCredentialOutput(tt.output))
+		})
+	}
+}
+
+func TestHostCredential_Resolve(t *testing.T) {
+	t.Setenv("BUILDFLY_TEST_PASSWORD", "s3cret")
+	t.Setenv("BUILDFLY_TEST_TOKEN", "tok-123")
+
+	netrc := filepath.Join(t.TempDir(), "netrc")
+	require.NoError(t, os.WriteFile(netrc, []byte("machine artifactory.example.com login deploy password n3trc\n"), 0600))
+	t.Setenv("NETRC", netrc)
+
+	tests := []struct {
+		name       string
+		host       string
+		credential HostCredential
+		want       *Credential
+		wantErr    bool
+	}{
+		{"basic env", "example.com", HostCredential{Type: "basic", Username: "ci", Password: "${BUILDFLY_TEST_PASSWORD}"}, &Credential{Username: "ci", Password: "s3cret"}, false},
+		{"bearer env", "example.com", HostCredential{Type: "bearer", Token: "$BUILDFLY_TEST_TOKEN"}, &Credential{Token: "tok-123"}, false},
+		{"bearer empty", "example.com", HostCredential{Type: "bearer", Token: "${BUILDFLY_TEST_UNSET}"}, nil, true},
+		{"netrc", "artifactory.example.com:443", HostCredential{Type: "netrc"}, &Credential{Username: "deploy", Password: "n3trc"}, false},
+		{"netrc missing", "other.example.com", HostCredential{Type: "netrc"}, nil, true},
+		{"command", "example.com", HostCredential{Type: "command", Command: `read line; echo "username=$BUILDFLY_CREDENTIAL_HOST"; echo "password=$line"`}, &Credential{Username: "example.com", Password: "protocol=https"}, false},
+		{"command fails", "example.com", HostCredential{Type: "command", Command: "exit 1"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.credential.Resolve(context.Background(), tt.host)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestConfigLoader_mergeCredentials(t *testing.T) {
+	global := &ProjectConfig{Credentials: map[string]HostCredential{
+		"gitlab.example.com":      {Type: "netrc"},
+		"artifactory.example.com": {Type: "basic", Username: "global"},
+	}}
+	local := &ProjectConfig{Credentials: map[string]HostCredential{
+		"artifactory.example.com":      {Type: "bearer", Token: "${TOKEN}"},
+		"artifactory.example.com:8443": {Type: "command", Command: "cat ~/.ssh/id_rsa"},
+		"nexus.example.com":            {Type: "bearer", Token: "${NEXUS_TOKEN}"},
+	}}
+
+	// 全局配置中已有的主机不能被本地配置覆盖，本地配置只能添加其他主机
+	merged := NewConfigLoader(".").mergeConfigs(global, local)
+	assert.Equal(t, "netrc", merged.Credentials["gitlab.example.com"].Type)
+	assert.Equal(t, "basic", merged.Credentials["artifactory.example.com"].Type)
+	assert.NotContains(t, merged.Credentials, "artifactory.example.com:8443")
+	assert.Equal(t, "bearer", merged.Credentials["nexus.example.com"].Type)
+	assert.Len(t, global.Credentials, 2, "global config must not be modified")
+
+	_, ignored := MergeCredentials(global.Credentials, local.Credentials)
+	assert.Equal(t, []string{"artifactory.example.com", "artifactory.example.com:8443"}, ignored)
+}
@@ -292,7 +292,11 @@ func (da *DependencyAnalyzer) validateDependency(name string, dep Dependency) er
 	}
 
 	// 对于外部index类型，URLs可能不是必需的
-	if dep.Source.Type != "external_index" && len(dep.Source.GetURLs()) == 0 {
+	if dep.Source.IsLocalPath() {
+		if dep.Source.Path == "" {
+			return fmt.Errorf("source path is required")
+		}
+	} else if dep.Source.Type != "external_index" && len(dep.Source.GetURLs()) == 0 {
 		return fmt.Errorf("source URLs are required")
 	}
 
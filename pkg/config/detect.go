@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
 	"runtime"
 	"strings"
 )
@@ -42,6 +44,19 @@ func DetectBuildTag() (*BuildTag, error) {
 		bt.ABI = abi
 	}
 
+	// 检测 CPU 指令集级别
+	if isa, err := detectISA(); err == nil {
+		bt.ISA = isa
+	}
+
+	// 检测 libstdc++ 版本和默认 C++11 ABI
+	if libstdcxx, err := detectLibstdcxx(); err == nil {
+		bt.Libstdcxx = libstdcxx
+	}
+	if cxx11ABI, err := detectCXX11ABI(); err == nil {
+		bt.CXX11ABI = cxx11ABI
+	}
+
 	// 检测 GPU
 	if gpu, err := detectGPU(); err == nil {
 		bt.GPU = gpu
@@ -346,6 +361,146 @@ func detectABI() (string, error) {
 	}
 }
 
+// x86-64 微架构级别需要的 CPU 特性（/proc/cpuinfo 中的名称）
+var (
+	isaV2Flags = []string{"cx16", "lahf_lm", "popcnt", "pni", "sse4_1", "sse4_2", "ssse3"}
+	isaV3Flags = []string{"abm", "avx", "avx2", "bmi1", "bmi2", "f16c", "fma", "movbe", "xsave"}
+	isaV4Flags = []string{"avx512f", "avx512bw", "avx512cd", "avx512dq", "avx512vl"}
+)
+
+// detectISA 从 /proc/cpuinfo 检测 x86-64 微架构级别
+func detectISA() (string, error) {
+	if runtime.GOOS != "linux" || runtime.GOARCH != "amd64" {
+		return "", fmt.Errorf("ISA level detection is only supported on linux x86_64")
+	}
+
+	data, err := os.ReadFile("/proc/cpuinfo")
+	if err != nil {
+		return "", err
+	}
+
+	return isaLevelFromCPUInfo(string(data)), nil
+}
+
+// isaLevelFromCPUInfo 根据 cpuinfo 中第一个处理器的 flags 计算 x86-64 微架构级别
+func isaLevelFromCPUInfo(cpuinfo string) string {
+	flags := make(map[string]bool)
+	for _, line := range strings.Split(cpuinfo, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if !found || strings.TrimSpace(key) != "flags" {
+			continue
+		}
+		for _, flag := range strings.Fields(value) {
+			flags[flag] = true
+		}
+		break
+	}
+
+	hasAll := func(required []string) bool {
+		for _, flag := range required {
+			if !flags[flag] {
+				return false
+			}
+		}
+		return true
+	}
+
+	level := "x86-64"
+	for i, required := range [][]string{isaV2Flags, isaV3Flags, isaV4Flags} {
+		if !hasAll(required) {
+			break
+		}
+		level = isaLevels[i+1]
+	}
+	return level
+}
+
+// glibcxxVersionPattern libstdc++ 符号版本
+var glibcxxVersionPattern = regexp.MustCompile(`GLIBCXX_(3\.4(?:\.[0-9]+)?)\x00`)
+
+// detectLibstdcxx 检测 libstdc++ 支持的最高 GLIBCXX 版本
+func detectLibstdcxx() (string, error) {
+	if runtime.GOOS != "linux" {
+		return "", fmt.Errorf("libstdc++ detection is only supported on linux")
+	}
+
+	data, err := os.ReadFile(findLibstdcxx())
+	if err != nil {
+		return "", err
+	}
+
+	version := maxGLIBCXXVersion(data)
+	if version == "" {
+		return "", fmt.Errorf("no GLIBCXX version found in libstdc++")
+	}
+	return "glibcxx_" + version, nil
+}
+
+// findLibstdcxx 查找 libstdc++.so.6，优先使用编译器自带的版本
+func findLibstdcxx() string {
+	if output, err := exec.Command(cxxCompiler(), "-print-file-name=libstdc++.so.6").Output(); err == nil {
+		// 找不到时编译器原样返回文件名
+		if path := strings.TrimSpace(string(output)); filepath.IsAbs(path) {
+			return path
+		}
+	}
+
+	for _, path := range []string{
+		"/usr/lib/x86_64-linux-gnu/libstdc++.so.6",
+		"/usr/lib/aarch64-linux-gnu/libstdc++.so.6",
+		"/usr/lib64/libstdc++.so.6",
+		"/usr/lib/libstdc++.so.6",
+	} {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return "libstdc++.so.6"
+}
+
+// maxGLIBCXXVersion 从 libstdc++ 文件内容中找出最高的 GLIBCXX 符号版本
+func maxGLIBCXXVersion(data []byte) string {
+	var max string
+	for _, match := range glibcxxVersionPattern.FindAllSubmatch(data, -1) {
+		version := string(match[1])
+		if max == "" || CompareVersions(version, max) > 0 {
+			max = version
+		}
+	}
+	return max
+}
+
+// detectCXX11ABI 检测编译器默认的 _GLIBCXX_USE_CXX11_ABI 取值
+func detectCXX11ABI() (string, error) {
+	cmd := exec.Command(cxxCompiler(), "-x", "c++", "-E", "-dM", "-")
+	cmd.Stdin = strings.NewReader("#include <string>\n")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run C++ preprocessor: %w", err)
+	}
+
+	return parseCXX11ABI(string(output))
+}
+
+// parseCXX11ABI 从预处理器宏定义中解析 _GLIBCXX_USE_CXX11_ABI
+func parseCXX11ABI(macros string) (string, error) {
+	for _, line := range strings.Split(macros, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 3 && fields[0] == "#define" && fields[1] == "_GLIBCXX_USE_CXX11_ABI" {
+			return fields[2], nil
+		}
+	}
+	return "", fmt.Errorf("_GLIBCXX_USE_CXX11_ABI is not defined (not using libstdc++)")
+}
+
+// cxxCompiler 获取用于检测的 C++ 编译器，优先使用 CXX 环境变量
+func cxxCompiler() string {
+	if cxx := os.Getenv("CXX"); cxx != "" {
+		return cxx
+	}
+	return "g++"
+}
+
 // detectGPU 检测 GPU 和相关后端
 func detectGPU() (*GPUInfo, error) {
 	// 检测 CUDA
@@ -563,15 +718,18 @@ func GetDefaultBuildTag(config *BuildTag) (*BuildTag, error) {
 		result.GPU = detected.GPU
 	}
 
+	// isa、libstdcxx、cxx11_abi 是可选字段，只在显式指定时参与构建标签，
+	// 避免同一台机器在不同 CPU 上检测出不同的缓存键
+
 	return result, nil
 }
 
-// GetBuildTagFromEnv 从环境变量获取构建标签
-func GetBuildTagFromEnv() (*BuildTag, error) {
+// GetBuildTagFromEnv 从环境变量获取构建标签，可以使用已声明的自定义维度
+func GetBuildTagFromEnv(dimensions BuildTagDimensions) (*BuildTag, error) {
 	buildTagStr := os.Getenv("BUILDFLY_BUILD_TAG")
 	if buildTagStr == "" {
 		return nil, nil
 	}
 
-	return ParseBuildTag(buildTagStr)
+	return dimensions.ParseBuildTag(buildTagStr)
 }
@@ -0,0 +1,50 @@
+package config
+
+import "testing"
+
+func TestISALevelFromCPUInfo(t *testing.T) {
+	v2 := "cx16 lahf_lm popcnt pni sse4_1 sse4_2 ssse3"
+	v3 := v2 + " abm avx avx2 bmi1 bmi2 f16c fma movbe xsave"
+	v4 := v3 + " avx512f avx512bw avx512cd avx512dq avx512vl"
+
+	tests := []struct {
+		name  string
+		flags string
+		want  string
+	}{
+		{"baseline", "fpu sse sse2", "x86-64"},
+		{"v2", v2, "x86-64-v2"},
+		{"v3", v3, "x86-64-v3"},
+		{"v4", v4, "x86-64-v4"},
+		{"avx512 without v3", v2 + " avx512f avx512bw avx512cd avx512dq avx512vl", "x86-64-v2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cpuinfo := "processor\t: 0\nvendor_id\t: GenuineIntel\nflags\t\t: " + tt.flags + "\n\nprocessor\t: 1\nflags\t\t: fpu\n"
+			if got := isaLevelFromCPUInfo(cpuinfo); got != tt.want {
+				t.Errorf("isaLevelFromCPUInfo() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaxGLIBCXXVersion(t *testing.T) {
+	data := []byte("\x00GLIBCXX_3.4\x00GLIBCXX_3.4.9\x00GLIBCXX_3.4.30\x00GLIBCXX_3.4.21\x00GLIBCXX_DEBUG_MESSAGE_LENGTH\x00")
+	if got := maxGLIBCXXVersion(data); got != "3.4.30" {
+		t.Errorf("maxGLIBCXXVersion() = %q, want 3.4.30", got)
+	}
+	if got := maxGLIBCXXVersion([]byte("no versions")); got != "" {
+		t.Errorf("maxGLIBCXXVersion() = %q, want empty", got)
+	}
+}
+
+func TestParseCXX11ABI(t *testing.T) {
+	macros := "#define __GNUC__ 12\n#define _GLIBCXX_USE_CXX11_ABI 1\n#define __cplusplus 201703L\n"
+	if got, err := parseCXX11ABI(macros); err != nil || got != "1" {
+		t.Errorf("parseCXX11ABI() = %q, %v, want 1", got, err)
+	}
+	if _, err := parseCXX11ABI("#define _LIBCPP_VERSION 170000\n"); err == nil {
+		t.Error("Expected error without libstdc++ macros")
+	}
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// BuildTagDimension 用户自定义的构建标签维度
+type BuildTagDimension struct {
+	Values  []string `yaml:"values,omitempty"`  // 允许的取值，为空时不限制
+	Default string   `yaml:"default,omitempty"` // 未指定时使用的默认值
+}
+
+// builtinBuildTagKeys 内置的构建标签键，自定义维度不能与之重名
+var builtinBuildTagKeys = []string{
+	"arch", "platform", "runtime", "compiler", "std", "abi", "target",
+	"isa", "libstdcxx", "cxx11_abi",
+	"link", "sanitizer", "build_type",
+	"cuda", "cuda_version", "cuda_arch", "rocm", "rocm_version", "rocm_arch",
+	"opencl", "opencl_version", "gpu_backend", "gpu_enabled", "extra",
+}
+
+// dimensionNamePattern 自定义维度名称格式
+var dimensionNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// BuildTagDimensions 配置中声明的自定义构建标签维度，按名称索引
+type BuildTagDimensions map[string]BuildTagDimension
+
+// Validate 验证自定义维度的名称和默认值
+func (d BuildTagDimensions) Validate() error {
+	for _, name := range sortedKeys(d) {
+		dimension := d[name]
+		if !dimensionNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid build tag dimension name: %s", name)
+		}
+		if contains(builtinBuildTagKeys, name) {
+			return fmt.Errorf("build tag dimension %s conflicts with a built-in key", name)
+		}
+		if dimension.Default != "" && len(dimension.Values) > 0 && !contains(dimension.Values, dimension.Default) {
+			return fmt.Errorf("default value %s of build tag dimension %s is not allowed", dimension.Default, name)
+		}
+	}
+	return nil
+}
+
+// ParseBuildTag 解析构建标签字符串，可以使用已声明的自定义维度
+func (d BuildTagDimensions) ParseBuildTag(tagStr string) (*BuildTag, error) {
+	return parseBuildTag(tagStr, func(key string) bool {
+		_, ok := d[key]
+		return ok
+	})
+}
+
+// ParseRecordedBuildTag 解析缓存元数据中记录的构建标签
+// 记录时已经按当时的配置校验过，名称合法的未知键都作为自定义维度
+func ParseRecordedBuildTag(tagStr string) (*BuildTag, error) {
+	return parseBuildTag(tagStr, dimensionNamePattern.MatchString)
+}
+
+// ApplyDefaults 为构建标签中未指定的自定义维度填充默认值
+func (d BuildTagDimensions) ApplyDefaults(bt *BuildTag) {
+	if bt == nil {
+		return
+	}
+
+	for name, dimension := range d {
+		if dimension.Default == "" {
+			continue
+		}
+		if _, ok := bt.Extra[name]; !ok {
+			bt.SetExtra(name, dimension.Default)
+		}
+	}
+}
+
+// ValidateBuildTag 验证构建标签，自定义维度必须已声明且取值合法
+func (d BuildTagDimensions) ValidateBuildTag(bt *BuildTag) error {
+	if err := bt.Validate(); err != nil {
+		return err
+	}
+
+	for _, name := range bt.extraKeys() {
+		value := bt.Extra[name]
+		dimension, ok := d[name]
+		if !ok {
+			return fmt.Errorf("undeclared build tag dimension: %s", name)
+		}
+		if len(dimension.Values) > 0 && !contains(dimension.Values, value) {
+			return fmt.Errorf("invalid value %s for build tag dimension %s (allowed: %s)", value, name, strings.Join(dimension.Values, ", "))
+		}
+	}
+	return nil
+}
+
+// sortedKeys 获取排序后的维度名称
+func sortedKeys(d BuildTagDimensions) []string {
+	keys := make([]string, 0, len(d))
+	for key := range d {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SetExtra 设置自定义维度的值
+func (bt *BuildTag) SetExtra(name, value string) {
+	if bt.Extra == nil {
+		bt.Extra = make(map[string]string)
+	}
+	bt.Extra[name] = value
+}
+
+// extraKeys 获取排序后的自定义维度名称
+func (bt *BuildTag) extraKeys() []string {
+	keys := make([]string, 0, len(bt.Extra))
+	for key := range bt.Extra {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
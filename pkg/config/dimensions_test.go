@@ -0,0 +1,157 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildTagDimensions(t *testing.T) {
+	dimensions := BuildTagDimensions{
+		"simd":   {Values: []string{"sse4", "avx2", "avx512"}, Default: "sse4"},
+		"vendor": {Default: "oss"},
+	}
+	if err := dimensions.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	bt, err := dimensions.ParseBuildTag("arch=x86_64,simd=avx2")
+	if err != nil {
+		t.Fatalf("ParseBuildTag() error = %v", err)
+	}
+	if bt.Extra["simd"] != "avx2" {
+		t.Errorf("Extra[simd] = %q, want avx2", bt.Extra["simd"])
+	}
+	if err := dimensions.ValidateBuildTag(bt); err != nil {
+		t.Errorf("ValidateBuildTag() error = %v", err)
+	}
+
+	// 默认值只填充未指定的维度
+	dimensions.ApplyDefaults(bt)
+	if got := bt.String(); got != "arch=x86_64,simd=avx2,vendor=oss" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := bt.ToDirName(); got != "arch-x86_64,simd-avx2,vendor-oss" {
+		t.Errorf("ToDirName() = %q", got)
+	}
+
+	// 不同取值使用不同的缓存键
+	other := bt.Clone()
+	other.SetExtra("simd", "avx512")
+	if bt.Equals(other) || bt.IsCompatibleWith(other) {
+		t.Error("Expected different dimension values to be incompatible")
+	}
+	if bt.Extra["simd"] != "avx2" {
+		t.Error("Clone() should copy the extra map")
+	}
+
+	// 不允许的取值
+	if err := dimensions.ValidateBuildTag(&BuildTag{Extra: map[string]string{"simd": "neon"}}); err == nil {
+		t.Error("Expected error for disallowed dimension value")
+	}
+
+	// 未声明的维度
+	if err := dimensions.ValidateBuildTag(&BuildTag{Extra: map[string]string{"color": "red"}}); err == nil {
+		t.Error("Expected error for undeclared dimension")
+	}
+	if _, err := dimensions.ParseBuildTag("color=red"); err == nil {
+		t.Error("Expected error for undeclared dimension key")
+	}
+	if _, err := ParseBuildTag("simd=avx2"); err == nil {
+		t.Error("Expected error for dimension key without declared dimensions")
+	}
+
+	// 缓存中记录的构建标签不依赖当前配置
+	recorded, err := ParseRecordedBuildTag("arch=x86_64,simd=avx2,vendor=oss")
+	if err != nil {
+		t.Fatalf("ParseRecordedBuildTag() error = %v", err)
+	}
+	if !recorded.Equals(bt) {
+		t.Errorf("ParseRecordedBuildTag() = %q, want %q", recorded.String(), bt.String())
+	}
+}
+
+func TestBuildTagDimensions_Invalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		dimensions BuildTagDimensions
+	}{
+		{"builtin key", BuildTagDimensions{"arch": {}}},
+		{"invalid name", BuildTagDimensions{"SIMD": {}}},
+		{"default not allowed", BuildTagDimensions{"simd": {Values: []string{"avx2"}, Default: "sse4"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.dimensions.Validate(); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestLoadWithHierarchy_GlobalDimensions(t *testing.T) {
+	globalDir := t.TempDir()
+	projectDir := t.TempDir()
+
+	globalConfig := `
+project:
+  name: "global"
+  version: "1.0.0"
+build_tag_dimensions:
+  simd:
+    values: [sse4, avx2, avx512]
+`
+	localConfig := `
+project:
+  name: "app"
+  version: "1.0.0"
+dependencies:
+  zlib:
+    version: "1.3"
+    source:
+      type: archive
+      urls: ["https://example.com/zlib-1.3.tar.gz"]
+    build_system: cmake
+build_profiles:
+  simd:
+    matrix:
+      simd: [avx2, avx512]
+`
+	globalPath := filepath.Join(globalDir, "config.yaml")
+	if err := os.WriteFile(globalPath, []byte(globalConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, "buildfly.yaml"), []byte(localConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("BUILDFLY_CONFIG_FILE", globalPath)
+
+	// 本地配置使用全局配置声明的维度，合并后验证
+	config, err := NewConfigLoader(projectDir).LoadWithHierarchy()
+	if err != nil {
+		t.Fatalf("LoadWithHierarchy() error = %v", err)
+	}
+	if config.Project.Name != "app" {
+		t.Errorf("Project.Name = %q, want app", config.Project.Name)
+	}
+	if _, ok := config.Dependencies["zlib"]; !ok {
+		t.Error("local dependencies should be kept")
+	}
+	if _, ok := config.BuildTagDimensions["simd"]; !ok {
+		t.Error("global dimensions should be merged")
+	}
+
+	// 本地配置使用未声明的维度时验证失败，与其他加载失败一样只使用全局配置
+	invalid := localConfig + "      vendor: [oss]\n"
+	if err := os.WriteFile(filepath.Join(projectDir, "buildfly.yaml"), []byte(invalid), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, err = NewConfigLoader(projectDir).LoadWithHierarchy()
+	if err != nil {
+		t.Fatalf("LoadWithHierarchy() error = %v", err)
+	}
+	if config.Project.Name != "global" {
+		t.Errorf("Project.Name = %q, want global for invalid local config", config.Project.Name)
+	}
+}
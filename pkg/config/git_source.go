@@ -0,0 +1,137 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// filterPattern 支持的部分克隆过滤器
+var filterPattern = regexp.MustCompile(`^(blob:none|tree:0|blob:limit=[0-9]+[kmg]?)$`)
+
+// commitPattern 完整的提交哈希（SHA-1 或 SHA-256）
+var commitPattern = regexp.MustCompile(`^([0-9a-f]{40}|[0-9a-f]{64})$`)
+
+// Submodules Git 子模块配置
+//
+//	submodules: true              # 初始化所有子模块（递归）
+//	submodules: [third_party/abseil-cpp, third_party/protobuf]  # 只初始化列出的子模块
+type Submodules struct {
+	Enabled bool
+	Paths   []string // 为空时初始化所有子模块
+}
+
+// UnmarshalYAML 支持布尔值和路径列表两种写法
+func (s *Submodules) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var enabled bool
+	if err := unmarshal(&enabled); err == nil {
+		*s = Submodules{Enabled: enabled}
+		return nil
+	}
+
+	var paths []string
+	if err := unmarshal(&paths); err != nil {
+		return fmt.Errorf("submodules must be true, false or a list of paths")
+	}
+	*s = Submodules{Enabled: len(paths) > 0, Paths: paths}
+	return nil
+}
+
+// MarshalYAML 输出与配置文件相同的写法
+func (s Submodules) MarshalYAML() (interface{}, error) {
+	if len(s.Paths) > 0 {
+		return s.Paths, nil
+	}
+	return s.Enabled, nil
+}
+
+// SubmodulesEnabled 是否需要初始化子模块
+func (s *SourceInfo) SubmodulesEnabled() bool {
+	return s.Submodules != nil && s.Submodules.Enabled
+}
+
+// LFS Git LFS 配置
+//
+//	lfs: true                           # 获取并检出所有 LFS 文件
+//	lfs: ["prebuilt/linux-x86_64/**", "testdata/*.bin"]  # 只获取匹配 include 模式的文件
+type LFS struct {
+	Enabled bool
+	Include []string // 为空时获取所有 LFS 文件
+}
+
+// UnmarshalYAML 支持布尔值和 include 模式列表两种写法
+func (l *LFS) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var enabled bool
+	if err := unmarshal(&enabled); err == nil {
+		*l = LFS{Enabled: enabled}
+		return nil
+	}
+
+	var include []string
+	if err := unmarshal(&include); err != nil {
+		return fmt.Errorf("lfs must be true, false or a list of include patterns")
+	}
+	*l = LFS{Enabled: len(include) > 0, Include: include}
+	return nil
+}
+
+// MarshalYAML 输出与配置文件相同的写法
+func (l LFS) MarshalYAML() (interface{}, error) {
+	if len(l.Include) > 0 {
+		return l.Include, nil
+	}
+	return l.Enabled, nil
+}
+
+// LFSEnabled 是否需要获取 Git LFS 文件
+func (s *SourceInfo) LFSEnabled() bool {
+	return s.LFS != nil && s.LFS.Enabled
+}
+
+// validateGitSource 验证 Git 源的 commit/branch/tag/submodules/sparse_paths/filter/lfs 设置，以及 subdir
+func validateGitSource(name string, source SourceInfo) error {
+	if source.Subdir != "" && !isRelativeSubpath(source.Subdir) {
+		return fmt.Errorf("subdir of dependency %s must be a relative path inside the source tree: %s", name, source.Subdir)
+	}
+
+	if source.Type != "git" {
+		if source.Commit != "" || source.Branch != "" || source.Submodules != nil || len(source.SparsePaths) > 0 || source.Filter != "" || source.LFS != nil {
+			return fmt.Errorf("commit, branch, submodules, sparse_paths, filter and lfs are only supported for git sources (dependency %s)", name)
+		}
+		return nil
+	}
+
+	for _, path := range source.SparsePaths {
+		if !isRelativeSubpath(path) {
+			return fmt.Errorf("sparse path of dependency %s must be a relative path inside the repository: %s", name, path)
+		}
+	}
+	if source.LFS != nil {
+		for _, pattern := range source.LFS.Include {
+			if strings.TrimSpace(pattern) == "" || strings.Contains(pattern, ",") {
+				return fmt.Errorf("invalid lfs include pattern %q for dependency %s", pattern, name)
+			}
+		}
+	}
+	if source.Filter != "" && !filterPattern.MatchString(source.Filter) {
+		return fmt.Errorf("unsupported git filter %s for dependency %s (supported: blob:none, tree:0, blob:limit=<size>)", source.Filter, name)
+	}
+
+	if source.Tag != "" && source.Branch != "" {
+		return fmt.Errorf("tag and branch cannot both be set for dependency %s", name)
+	}
+	if source.Commit != "" && !commitPattern.MatchString(source.Commit) {
+		return fmt.Errorf("commit of dependency %s must be a full lowercase commit hash: %s", name, source.Commit)
+	}
+	return nil
+}
+
+// isRelativeSubpath 检查路径是否为不跳出根目录的相对路径
+func isRelativeSubpath(path string) bool {
+	if path == "" || filepath.IsAbs(path) {
+		return false
+	}
+	clean := filepath.ToSlash(filepath.Clean(path))
+	return clean != ".." && !strings.HasPrefix(clean, "../")
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestSubmodules_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Submodules
+		wantErr bool
+	}{
+		{"true", "submodules: true", Submodules{Enabled: true}, false},
+		{"false", "submodules: false", Submodules{}, false},
+		{"paths", "submodules: [third_party/abseil, third_party/re2]", Submodules{Enabled: true, Paths: []string{"third_party/abseil", "third_party/re2"}}, false},
+		{"invalid", "submodules: {path: x}", Submodules{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var source SourceInfo
+			err := yaml.Unmarshal([]byte(tt.input), &source)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got := *source.Submodules
+			if got.Enabled != tt.want.Enabled || len(got.Paths) != len(tt.want.Paths) {
+				t.Errorf("Submodules = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLFS_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    LFS
+		wantErr bool
+	}{
+		{"true", "lfs: true", LFS{Enabled: true}, false},
+		{"false", "lfs: false", LFS{}, false},
+		{"include", `lfs: ["prebuilt/**", "testdata/*.bin"]`, LFS{Enabled: true, Include: []string{"prebuilt/**", "testdata/*.bin"}}, false},
+		{"invalid", "lfs: {include: x}", LFS{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var source SourceInfo
+			err := yaml.Unmarshal([]byte(tt.input), &source)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got := *source.LFS
+			if got.Enabled != tt.want.Enabled || len(got.Include) != len(tt.want.Include) {
+				t.Errorf("LFS = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateGitSource(t *testing.T) {
+	commit := "0123456789abcdef0123456789abcdef01234567"
+
+	tests := []struct {
+		name    string
+		source  SourceInfo
+		wantErr bool
+	}{
+		{"commit", SourceInfo{Type: "git", Commit: commit, Branch: "main"}, false},
+		{"tag", SourceInfo{Type: "git", Tag: "v1.0", Submodules: &Submodules{Enabled: true}}, false},
+		{"short commit", SourceInfo{Type: "git", Commit: "0123456"}, true},
+		{"tag and branch", SourceInfo{Type: "git", Tag: "v1.0", Branch: "main"}, true},
+		{"commit on archive", SourceInfo{Type: "archive", Commit: commit}, true},
+		{"sparse and filter", SourceInfo{Type: "git", SparsePaths: []string{"cpp", "cmake/modules"}, Filter: "blob:none", Subdir: "cpp"}, false},
+		{"blob limit filter", SourceInfo{Type: "git", Filter: "blob:limit=1m"}, false},
+		{"invalid filter", SourceInfo{Type: "git", Filter: "sparse:oid=abc"}, true},
+		{"absolute sparse path", SourceInfo{Type: "git", SparsePaths: []string{"/cpp"}}, true},
+		{"escaping sparse path", SourceInfo{Type: "git", SparsePaths: []string{"../cpp"}}, true},
+		{"escaping subdir", SourceInfo{Type: "git", Subdir: "../other"}, true},
+		{"subdir on archive", SourceInfo{Type: "archive", Subdir: "src"}, false},
+		{"sparse on archive", SourceInfo{Type: "archive", SparsePaths: []string{"src"}}, true},
+		{"lfs include", SourceInfo{Type: "git", LFS: &LFS{Enabled: true, Include: []string{"prebuilt/**"}}}, false},
+		{"lfs pattern with comma", SourceInfo{Type: "git", LFS: &LFS{Enabled: true, Include: []string{"a,b"}}}, true},
+		{"lfs on archive", SourceInfo{Type: "archive", LFS: &LFS{Enabled: true}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateGitSource("dep", tt.source); (err != nil) != tt.wantErr {
+				t.Errorf("validateGitSource() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 
@@ -34,7 +35,7 @@ func (cl *ConfigLoader) LoadWithHierarchy() (*ProjectConfig, error) {
 		globalConfig, err = cl.Load(globalConfigPath)
 		if err != nil {
 			// 全局配置加载失败时记录警告但不中断
-			fmt.Printf("Warning: failed to load global config from %s: %v\n", globalConfigPath, err)
+			slog.Warn("Failed to load global config", "path", globalConfigPath, "error", err)
 			globalConfig = nil
 		}
 	}
@@ -42,35 +43,67 @@ func (cl *ConfigLoader) LoadWithHierarchy() (*ProjectConfig, error) {
 	// 2. 尝试加载本地配置
 	localConfigPath := filepath.Join(cl.baseDir, "buildfly.yaml")
 	if _, err := os.Stat(localConfigPath); err == nil {
-		// 本地配置存在
-		localConfig, err := cl.Load(localConfigPath)
+		// 本地配置存在，合并后再验证，本地配置可以使用全局配置中声明的构建标签维度
+		config, err := cl.loadLocal(localConfigPath, globalConfig)
 		if err != nil {
 			if globalConfig != nil {
 				// 如果本地配置加载失败但有全局配置，使用全局配置
-				fmt.Printf("Using global config only (local config load failed: %v)\n", err)
+				slog.Warn("Using global config only, local config load failed", "error", err)
 				return globalConfig, nil
 			}
-			return nil, fmt.Errorf("failed to load local config from %s: %w", localConfigPath, err)
+			return nil, err
 		}
-
-		// 3. 合并配置
-		if globalConfig != nil {
-			return cl.mergeConfigs(globalConfig, localConfig), nil
-		}
-
-		// 只有本地配置
-		return localConfig, nil
+		return config, nil
 	}
 
 	// 本地配置不存在
 	if globalConfig != nil {
-		fmt.Printf("Using global config only (no local config found)\n")
+		slog.Debug("Using global config only, no local config found")
 		return globalConfig, nil
 	}
 
 	return nil, fmt.Errorf("no config file found: neither global nor local config available")
 }
 
+// loadLocal 读取本地配置并与全局配置合并，合并后再验证
+func (cl *ConfigLoader) loadLocal(localConfigPath string, globalConfig *ProjectConfig) (*ProjectConfig, error) {
+	localConfig, err := cl.read(localConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load local config from %s: %w", localConfigPath, err)
+	}
+	cl.fillConfigDefaults(localConfig)
+
+	// 3. 合并配置
+	config := localConfig
+	if globalConfig != nil {
+		config = cl.mergeConfigs(globalConfig, localConfig)
+	}
+	if err := cl.Validate(config); err != nil {
+		return nil, fmt.Errorf("failed to load local config from %s: config validation failed: %w", localConfigPath, err)
+	}
+	return config, nil
+}
+
+// LoadGlobalCredentials 读取全局配置中按主机配置的凭据，没有全局配置时返回 nil
+// 只使用凭据部分，全局配置中的其他设置不影响通过 --config 或当前目录加载的项目配置
+func (cl *ConfigLoader) LoadGlobalCredentials() (map[string]HostCredential, error) {
+	globalConfigPath := cl.getGlobalConfigPath()
+	if globalConfigPath == "" {
+		return nil, nil
+	}
+
+	globalConfig, err := cl.read(globalConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	for host, credential := range globalConfig.Credentials {
+		if err := credential.Validate(host); err != nil {
+			return nil, fmt.Errorf("invalid global config %s: %w", globalConfigPath, err)
+		}
+	}
+	return globalConfig.Credentials, nil
+}
+
 // getGlobalConfigPath 获取全局配置文件路径
 func (cl *ConfigLoader) getGlobalConfigPath() string {
 	// 优先使用环境变量指定的路径
@@ -78,7 +111,7 @@ func (cl *ConfigLoader) getGlobalConfigPath() string {
 		if _, err := os.Stat(envPath); err == nil {
 			return envPath
 		}
-		fmt.Printf("Warning: BUILDFLY_CONFIG_FILE %s not accessible\n", envPath)
+		slog.Warn("BUILDFLY_CONFIG_FILE not accessible", "path", envPath)
 	}
 
 	// 默认全局配置路径
@@ -156,19 +189,98 @@ func (cl *ConfigLoader) mergeConfigs(globalConfig, localConfig *ProjectConfig) *
 		merged.CacheDir = localConfig.CacheDir
 	}
 
+	// 合并自定义构建标签维度（同名维度本地配置优先）
+	if localConfig.BuildTagDimensions != nil {
+		dimensions := make(map[string]BuildTagDimension)
+		for k, v := range globalConfig.BuildTagDimensions {
+			dimensions[k] = v
+		}
+		for k, v := range localConfig.BuildTagDimensions {
+			dimensions[k] = v
+		}
+		merged.BuildTagDimensions = dimensions
+	}
+
+	// 合并工具链配置（同名工具链本地配置优先）
+	if localConfig.Toolchains != nil {
+		toolchains := make(map[string]Toolchain)
+		for k, v := range globalConfig.Toolchains {
+			toolchains[k] = v
+		}
+		for k, v := range localConfig.Toolchains {
+			toolchains[k] = v
+		}
+		merged.Toolchains = toolchains
+	}
+
+	// 合并密封构建设置（本地配置显式设置时优先，可以关闭全局配置中启用的密封构建）
+	if localConfig.Hermetic != nil {
+		merged.Hermetic = localConfig.Hermetic
+	}
+
+	// 合并产物校验模式（本地配置优先）
+	if localConfig.VerifyArtifacts != "" {
+		merged.VerifyArtifacts = localConfig.VerifyArtifacts
+	}
+
+	// 合并校验和策略（本地配置优先）
+	if localConfig.ChecksumPolicy != "" {
+		merged.ChecksumPolicy = localConfig.ChecksumPolicy
+	}
+
+	// 合并编译器缓存配置（本地配置优先）
+	if localConfig.CompilerCache != nil {
+		merged.CompilerCache = localConfig.CompilerCache
+	}
+
 	// 合并代理配置（本地配置优先）
 	if localConfig.Proxy != nil {
 		merged.Proxy = localConfig.Proxy
 	}
 
+	// 合并凭据配置（同一主机全局配置优先，本地配置不能覆盖）
+	credentials, ignored := MergeCredentials(globalConfig.Credentials, localConfig.Credentials)
+	for _, host := range ignored {
+		slog.Warn("Ignoring project credentials for host configured in global config", "host", host)
+	}
+	merged.Credentials = credentials
+
+	// 合并可信公钥（同名公钥本地配置优先）
+	if localConfig.TrustedKeys != nil {
+		trustedKeys := make(map[string]TrustedKey)
+		for k, v := range globalConfig.TrustedKeys {
+			trustedKeys[k] = v
+		}
+		for k, v := range localConfig.TrustedKeys {
+			trustedKeys[k] = v
+		}
+		merged.TrustedKeys = trustedKeys
+	}
+
 	// 项目根目录设置为本地配置的目录
 	merged.ProjectRoot = localConfig.ProjectRoot
 
 	return merged
 }
 
-// Load 加载配置文件
+// Load 加载并验证配置文件
 func (cl *ConfigLoader) Load(configFile string) (*ProjectConfig, error) {
+	config, err := cl.read(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// 验证配置
+	if err := cl.Validate(config); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+	cl.fillConfigDefaults(config)
+
+	return config, nil
+}
+
+// read 读取并解析配置文件，不做验证
+func (cl *ConfigLoader) read(configFile string) (*ProjectConfig, error) {
 	// 如果是相对路径，则基于 baseDir
 	if !filepath.IsAbs(configFile) {
 		configFile = filepath.Join(cl.baseDir, configFile)
@@ -184,21 +296,29 @@ func (cl *ConfigLoader) Load(configFile string) (*ProjectConfig, error) {
 		return nil, fmt.Errorf("failed to parse YAML config %s: %w", configFile, err)
 	}
 
-	// 设置依赖项名称
+	// 设置依赖项名称，本地路径源码、签名和公钥文件的相对路径基于配置文件所在目录
+	config.ProjectRoot = filepath.Dir(configFile)
 	for name, dep := range config.Dependencies {
 		dep.Name = name
+		if dep.Source.IsLocalPath() && dep.Source.Path != "" && !filepath.IsAbs(dep.Source.Path) {
+			dep.Source.Path = filepath.Join(config.ProjectRoot, dep.Source.Path)
+		}
+		if dep.Source.Signature != "" && dep.Source.IsLocalURL(dep.Source.Signature) && !filepath.IsAbs(dep.Source.Signature) {
+			dep.Source.Signature = filepath.Join(config.ProjectRoot, dep.Source.Signature)
+		}
 		config.Dependencies[name] = dep
 	}
-	config.ProjectRoot = filepath.Dir(configFile)
-
-	// 验证配置
-	if err := cl.Validate(&config); err != nil {
-		return nil, fmt.Errorf("config validation failed: %w", err)
+	for name, key := range config.TrustedKeys {
+		if key.File != "" && !filepath.IsAbs(key.File) {
+			key.File = filepath.Join(config.ProjectRoot, key.File)
+			config.TrustedKeys[name] = key
+		}
 	}
-	cl.fillConfigDefaults(&config)
 
 	return &config, nil
 }
+
+// fillConfigDefaults 填充未配置的目录
 func (cl *ConfigLoader) fillConfigDefaults(cfg *ProjectConfig) {
 	if cfg.BuildFlyBaseDir == "" {
 		cfg.BuildFlyBaseDir = filepath.Join(cfg.ProjectRoot, ".buildfly")
@@ -295,6 +415,11 @@ func (cl *ConfigLoader) Validate(config *ProjectConfig) error {
 		return fmt.Errorf("project version is required")
 	}
 
+	// 验证自定义构建标签维度
+	if err := config.BuildTagDimensions.Validate(); err != nil {
+		return err
+	}
+
 	// 验证依赖项
 	for name, dep := range config.Dependencies {
 		if err := cl.validateDependency(name, dep); err != nil {
@@ -304,11 +429,46 @@ func (cl *ConfigLoader) Validate(config *ProjectConfig) error {
 
 	// 验证构建配置文件
 	for profileName, profile := range config.BuildProfiles {
-		if err := cl.validateBuildProfile(profileName, profile); err != nil {
+		if err := cl.validateBuildProfile(profileName, profile, config.BuildTagDimensions); err != nil {
+			return err
+		}
+	}
+
+	// 验证代理配置
+	if config.Proxy != nil {
+		if err := config.Proxy.Validate(); err != nil {
+			return err
+		}
+	}
+
+	// 验证凭据配置
+	for host, credential := range config.Credentials {
+		if err := credential.Validate(host); err != nil {
+			return err
+		}
+	}
+
+	// 验证可信公钥
+	for name, key := range config.TrustedKeys {
+		if err := key.Validate(name); err != nil {
 			return err
 		}
 	}
 
+	// 验证产物校验模式
+	switch config.VerifyArtifacts {
+	case "", "warn", "error", "off":
+	default:
+		return fmt.Errorf("invalid verify_artifacts: %s (expected warn, error or off)", config.VerifyArtifacts)
+	}
+
+	// 验证校验和策略
+	switch config.ChecksumPolicy {
+	case "", ChecksumPolicyNone, ChecksumPolicyTOFU:
+	default:
+		return fmt.Errorf("invalid checksum_policy: %s (expected none or tofu)", config.ChecksumPolicy)
+	}
+
 	return nil
 }
 
@@ -318,8 +478,12 @@ func (cl *ConfigLoader) validateDependency(name string, dep Dependency) error {
 		return fmt.Errorf("version is required for dependency %s", name)
 	}
 
-	// 对于外部index类型，URLs可能不是必需的
-	if dep.Source.Type != "external_index" && len(dep.Source.URLS) == 0 {
+	// 对于外部index类型，URLs可能不是必需的；本地路径源码使用 path
+	if dep.Source.IsLocalPath() {
+		if dep.Source.Path == "" {
+			return fmt.Errorf("source path is required for local dependency %s", name)
+		}
+	} else if dep.Source.Type != "external_index" && len(dep.Source.URLS) == 0 {
 		return fmt.Errorf("source URLs are required for dependency %s", name)
 	}
 
@@ -328,12 +492,23 @@ func (cl *ConfigLoader) validateDependency(name string, dep Dependency) error {
 		"git":            true,
 		"archive":        true,
 		"direct":         true,
+		"path":           true,
 		"external_index": true,
 	}
 	if !supportedSourceTypes[dep.Source.Type] {
 		return fmt.Errorf("unsupported source type: %s for dependency %s", dep.Source.Type, name)
 	}
 
+	// 验证 Git 源设置
+	if err := validateGitSource(name, dep.Source); err != nil {
+		return err
+	}
+
+	// 验证签名设置
+	if err := validateSourceSignature(name, dep.Source); err != nil {
+		return err
+	}
+
 	// 验证构建系统
 	supportedBuildSystems := map[string]bool{
 		"make":      true,
@@ -355,7 +530,8 @@ func (cl *ConfigLoader) validateDependency(name string, dep Dependency) error {
 }
 
 // validateBuildProfile 验证构建配置文件
-func (cl *ConfigLoader) validateBuildProfile(name string, profile BuildProfile) error {
+// 矩阵中可以使用已声明的自定义维度
+func (cl *ConfigLoader) validateBuildProfile(name string, profile BuildProfile, dimensions BuildTagDimensions) error {
 	// 检查依赖项是否存在
 	for _, depName := range profile.Dependencies {
 		if depName == "" {
@@ -363,6 +539,18 @@ func (cl *ConfigLoader) validateBuildProfile(name string, profile BuildProfile)
 		}
 	}
 
+	// 检查构建矩阵的维度和取值
+	for key, values := range profile.Matrix {
+		if len(values) == 0 {
+			return fmt.Errorf("empty matrix dimension %s in build profile %s", key, name)
+		}
+		for _, value := range values {
+			if _, err := dimensions.ParseBuildTag(fmt.Sprintf("%s=%s", key, value)); err != nil {
+				return fmt.Errorf("invalid matrix dimension in build profile %s: %w", name, err)
+			}
+		}
+	}
+
 	return nil
 }
 
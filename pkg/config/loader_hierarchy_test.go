@@ -328,3 +328,37 @@ func TestConfigLoader_mergeConfigs(t *testing.T) {
 	// 验证项目根目录
 	assert.Equal(t, "/local/project", merged.ProjectRoot)
 }
+
+func TestConfigLoader_LoadWithHierarchy_Fallback(t *testing.T) {
+	globalDir := t.TempDir()
+	projectDir := t.TempDir()
+
+	globalPath := filepath.Join(globalDir, "config.yaml")
+	require.NoError(t, os.WriteFile(globalPath, []byte("project:\n  name: global\n  version: \"1.0\"\nhermetic: true\n"), 0644))
+	t.Setenv("BUILDFLY_CONFIG_FILE", globalPath)
+	localPath := filepath.Join(projectDir, "buildfly.yaml")
+
+	t.Run("InvalidLocalConfigUsesGlobal", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(localPath, []byte("project: [broken\n"), 0644))
+
+		config, err := NewConfigLoader(projectDir).LoadWithHierarchy()
+		require.NoError(t, err)
+		assert.Equal(t, "global", config.Project.Name)
+	})
+
+	t.Run("LocalConfigDisablesHermetic", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(localPath, []byte("project:\n  name: app\n  version: \"1.0\"\nhermetic: false\n"), 0644))
+
+		config, err := NewConfigLoader(projectDir).LoadWithHierarchy()
+		require.NoError(t, err)
+		assert.False(t, config.IsHermetic(Dependency{Name: "zlib"}))
+	})
+
+	t.Run("GlobalHermeticKeptWhenLocalUnset", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(localPath, []byte("project:\n  name: app\n  version: \"1.0\"\n"), 0644))
+
+		config, err := NewConfigLoader(projectDir).LoadWithHierarchy()
+		require.NoError(t, err)
+		assert.True(t, config.IsHermetic(Dependency{Name: "zlib"}))
+	})
+}
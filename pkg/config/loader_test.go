@@ -217,4 +217,104 @@ func TestConfigLoader_Validate(t *testing.T) {
 	if err := loader.Validate(invalidConfig3); err == nil {
 		t.Error("Invalid config should fail validation")
 	}
+
+	// 测试无效配置 - 不支持的产物校验模式
+	invalidConfig4 := &ProjectConfig{
+		Project: Project{
+			Name:    "test",
+			Version: "1.0.0",
+		},
+		VerifyArtifacts: "strict",
+	}
+
+	if err := loader.Validate(invalidConfig4); err == nil {
+		t.Error("Invalid config should fail validation")
+	}
+
+	// 测试无效配置 - 不支持的校验和策略
+	invalidConfig5 := &ProjectConfig{
+		Project: Project{
+			Name:    "test",
+			Version: "1.0.0",
+		},
+		ChecksumPolicy: "always",
+	}
+
+	if err := loader.Validate(invalidConfig5); err == nil {
+		t.Error("Invalid config should fail validation")
+	}
+}
+
+func TestConfigLoader_LocalPathSource(t *testing.T) {
+	tempDir := t.TempDir()
+
+	configContent := `
+project:
+  name: "app"
+  version: "1.0.0"
+
+dependencies:
+  mylib:
+    version: "dev"
+    source:
+      type: "path"
+      path: "../mylib"
+    build_system: "cmake"
+`
+	configFile := filepath.Join(tempDir, "buildfly.yaml")
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	config, err := NewConfigLoader(tempDir).Load("buildfly.yaml")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	dep := config.Dependencies["mylib"]
+	if !dep.Source.IsLocalPath() {
+		t.Error("Expected mylib to be a local path source")
+	}
+	// 相对路径基于配置文件所在目录
+	if want := filepath.Join(filepath.Dir(tempDir), "mylib"); dep.Source.Path != want {
+		t.Errorf("Source.Path = %s, want %s", dep.Source.Path, want)
+	}
+
+	// 缺少 path 时验证失败
+	dep.Source.Path = ""
+	if err := NewConfigLoader(tempDir).validateDependency("mylib", dep); err == nil {
+		t.Error("Expected error for local source without path")
+	}
+}
+
+func TestConfigLoader_ProfileBuildType(t *testing.T) {
+	tempDir := t.TempDir()
+
+	configContent := `
+project:
+  name: "app"
+  version: "1.0.0"
+
+build_profiles:
+  debug:
+    dependencies: []
+    build_tag:
+      build_type: "Debug"
+    matrix:
+      build_type: ["Debug", "Release"]
+`
+	if err := os.WriteFile(filepath.Join(tempDir, "buildfly.yaml"), []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	config, err := NewConfigLoader(tempDir).Load("buildfly.yaml")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	// 配置文件中的 build_type 与构建标签字符串使用相同的键
+	buildTag := config.BuildProfiles["debug"].BuildTag
+	if buildTag == nil || buildTag.BuildType != "Debug" {
+		t.Errorf("profile build_tag.build_type = %+v, want Debug", buildTag)
+	}
 }
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// 校验和策略
+const (
+	ChecksumPolicyNone = "none" // 没有配置校验和的依赖不校验
+	ChecksumPolicyTOFU = "tofu" // 首次下载时记录校验和，之后不匹配即失败
+)
+
+// LockfileName 锁文件名，与 buildfly.yaml 位于同一目录，应提交到仓库
+const LockfileName = "buildfly.lock"
+
+// lockfileVersion 锁文件格式版本
+const lockfileVersion = 1
+
+// lockfileLockTimeout 等待其他进程写入锁文件的最长时间，超过后视为残留的锁
+const lockfileLockTimeout = time.Minute
+
+// lockfileHeader 写入锁文件开头的说明
+const lockfileHeader = "# 由 buildfly 生成，请勿手动修改。校验和变化时运行 `buildfly lock --refresh <dependency>` 更新。\n"
+
+// Lockfile 记录首次下载时计算的依赖校验和
+type Lockfile struct {
+	Version      int                         `yaml:"version"`
+	Dependencies map[string]LockedDependency `yaml:"dependencies"`
+}
+
+// LockedDependency 锁定的依赖
+type LockedDependency struct {
+	Version string `yaml:"version"`
+	SHA256  string `yaml:"sha256"`
+}
+
+// LockfilePath 获取项目的锁文件路径
+func LockfilePath(projectRoot string) string {
+	return filepath.Join(projectRoot, LockfileName)
+}
+
+// LoadLockfile 读取锁文件，文件不存在时返回空的锁文件
+func LoadLockfile(path string) (*Lockfile, error) {
+	lock := &Lockfile{Version: lockfileVersion, Dependencies: make(map[string]LockedDependency)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return lock, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lockfile %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("failed to parse lockfile %s: %w", path, err)
+	}
+	if lock.Version > lockfileVersion {
+		return nil, fmt.Errorf("lockfile %s has version %d, this buildfly supports up to %d", path, lock.Version, lockfileVersion)
+	}
+	if lock.Dependencies == nil {
+		lock.Dependencies = make(map[string]LockedDependency)
+	}
+	return lock, nil
+}
+
+// Save 写入锁文件，先写临时文件再重命名，避免中断时留下不完整的文件
+// 临时文件名唯一，多个进程同时写入时不会互相覆盖临时文件
+func (l *Lockfile) Save(path string) error {
+	l.Version = lockfileVersion
+	data, err := yaml.Marshal(l)
+	if err != nil {
+		return fmt.Errorf("failed to encode lockfile: %w", err)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write lockfile: %w", err)
+	}
+	tempFile := file.Name()
+	_, err = file.Write(append([]byte(lockfileHeader), data...))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tempFile, 0644)
+	}
+	if err == nil {
+		err = os.Rename(tempFile, path)
+	}
+	if err != nil {
+		os.Remove(tempFile)
+		return fmt.Errorf("failed to write lockfile: %w", err)
+	}
+	return nil
+}
+
+// AcquireLockfile 获取锁文件的跨进程独占锁，返回释放锁的函数
+// 矩阵构建的各个变体在不同进程中运行，读取、修改、写回锁文件期间需要持有该锁
+func AcquireLockfile(path string) (func(), error) {
+	lockFile := path + ".lock"
+	deadline := time.Now().Add(lockfileLockTimeout)
+	for {
+		file, err := os.OpenFile(lockFile, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			fmt.Fprintf(file, "%d\n", os.Getpid())
+			file.Close()
+			return func() { os.Remove(lockFile) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+
+		// 清理残留的锁
+		if info, statErr := os.Stat(lockFile); statErr == nil && time.Since(info.ModTime()) > lockfileLockTimeout {
+			os.Remove(lockFile)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for lock %s", lockFile)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// Checksum 获取依赖锁定的 SHA256，没有记录或记录的是其他版本时返回空字符串
+func (l *Lockfile) Checksum(dep Dependency) string {
+	locked, ok := l.Dependencies[dep.Name]
+	if !ok || locked.Version != dep.Version {
+		return ""
+	}
+	return locked.SHA256
+}
+
+// SetChecksum 记录依赖当前版本的 SHA256，替换之前版本的记录
+func (l *Lockfile) SetChecksum(dep Dependency, sha256 string) {
+	l.Dependencies[dep.Name] = LockedDependency{Version: dep.Version, SHA256: sha256}
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockfile_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LockfileName)
+
+	// 文件不存在时为空
+	lock, err := LoadLockfile(path)
+	require.NoError(t, err)
+	assert.Empty(t, lock.Dependencies)
+
+	zlib := Dependency{Name: "zlib", Version: "1.3.1"}
+	lock.SetChecksum(zlib, "abc123")
+	require.NoError(t, lock.Save(path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "#"), "lockfile should start with a header comment")
+
+	loaded, err := LoadLockfile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", loaded.Checksum(zlib))
+
+	// 版本变化后记录不再适用
+	assert.Equal(t, "", loaded.Checksum(Dependency{Name: "zlib", Version: "1.3.2"}))
+	assert.Equal(t, "", loaded.Checksum(Dependency{Name: "fmt", Version: "1.3.1"}))
+
+	// 新版本的记录替换旧版本
+	loaded.SetChecksum(Dependency{Name: "zlib", Version: "1.3.2"}, "def456")
+	assert.Len(t, loaded.Dependencies, 1)
+	assert.Equal(t, "def456", loaded.Checksum(Dependency{Name: "zlib", Version: "1.3.2"}))
+}
+
+func TestAcquireLockfile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, LockfileName)
+
+	// 多个写入方同时读取、修改、写回锁文件，不丢失记录
+	const writers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			unlock, err := AcquireLockfile(path)
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer unlock()
+
+			lock, err := LoadLockfile(path)
+			if err != nil {
+				errs <- err
+				return
+			}
+			lock.SetChecksum(Dependency{Name: fmt.Sprintf("dep%d", i), Version: "1.0"}, fmt.Sprintf("%064d", i))
+			errs <- lock.Save(path)
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	lock, err := LoadLockfile(path)
+	require.NoError(t, err)
+	assert.Len(t, lock.Dependencies, writers)
+
+	// 释放后不留下锁和临时文件
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, LockfileName, entries[0].Name())
+}
+
+func TestLoadLockfile_Invalid(t *testing.T) {
+	dir := t.TempDir()
+
+	newer := filepath.Join(dir, "newer.lock")
+	require.NoError(t, os.WriteFile(newer, []byte("version: 99\ndependencies: {}\n"), 0644))
+	_, err := LoadLockfile(newer)
+	assert.Error(t, err)
+
+	broken := filepath.Join(dir, "broken.lock")
+	require.NoError(t, os.WriteFile(broken, []byte("dependencies: [\n"), 0644))
+	_, err = LoadLockfile(broken)
+	assert.Error(t, err)
+}
+
+func TestSourceInfo_HasChecksum(t *testing.T) {
+	assert.False(t, (&SourceInfo{}).HasChecksum())
+	assert.True(t, (&SourceInfo{Hash: "x"}).HasChecksum())
+	assert.True(t, (&SourceInfo{SHA512: "x"}).HasChecksum())
+	assert.True(t, (&SourceInfo{Checksums: map[string]string{"sha1": "x"}}).HasChecksum())
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// NetrcPath 获取 netrc 文件路径：$NETRC，否则为 ~/.netrc（Windows 上为 ~/_netrc）
+func NetrcPath() string {
+	if path := os.Getenv("NETRC"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(home, "_netrc")
+	}
+	return filepath.Join(home, ".netrc")
+}
+
+// LookupNetrc 在 netrc 文件中查找主机的登录名和密码，没有匹配的 machine 时使用 default
+func LookupNetrc(host string) (login, password string, ok bool) {
+	path := NetrcPath()
+	if path == "" {
+		return "", "", false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", false
+	}
+	return parseNetrc(string(data), host)
+}
+
+// parseNetrc 解析 netrc 内容，忽略 macdef 宏定义
+func parseNetrc(content, host string) (login, password string, ok bool) {
+	var (
+		current    string // 当前 machine，default 条目为 "*"
+		fields     = map[string][2]string{}
+		lastLogin  string
+		lastPasswd string
+	)
+	flush := func() {
+		if current != "" {
+			fields[current] = [2]string{lastLogin, lastPasswd}
+		}
+		lastLogin, lastPasswd = "", ""
+	}
+
+	lines := strings.Split(content, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		tokens := strings.Fields(line)
+		for j := 0; j < len(tokens); j++ {
+			next := func() string {
+				if j+1 < len(tokens) {
+					j++
+					return tokens[j]
+				}
+				return ""
+			}
+			switch tokens[j] {
+			case "machine":
+				flush()
+				current = strings.ToLower(next())
+			case "default":
+				flush()
+				current = "*"
+			case "login":
+				lastLogin = next()
+			case "password":
+				lastPasswd = next()
+			case "account":
+				next()
+			case "macdef":
+				// 宏定义一直到空行结束
+				flush()
+				current = ""
+				for i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" {
+					i++
+				}
+				j = len(tokens)
+			}
+		}
+	}
+	flush()
+
+	host = strings.ToLower(host)
+	for _, key := range []string{host, "*"} {
+		if entry, exists := fields[key]; exists {
+			return entry[0], entry[1], true
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+)
+
+// proxySchemes 支持的代理协议
+var proxySchemes = map[string]bool{"http": true, "https": true, "socks5": true, "socks5h": true}
+
+// Validate 验证代理 URL 的格式和协议
+func (p *ProxyConfig) Validate() error {
+	for name, raw := range map[string]string{"http": p.HTTP, "https": p.HTTPS, "all": p.All} {
+		if raw == "" {
+			continue
+		}
+		if _, err := resolveProxyURL(raw); err != nil {
+			return fmt.Errorf("invalid %s proxy: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// proxyFor 获取访问指定协议时使用的代理配置
+// https 没有单独配置时依次使用 all 和 http 代理，与只配置 http 代理的旧配置保持兼容
+func (p *ProxyConfig) proxyFor(scheme string) string {
+	switch scheme {
+	case "https":
+		if p.HTTPS != "" {
+			return p.HTTPS
+		}
+		if p.All != "" {
+			return p.All
+		}
+		return p.HTTP
+	case "http":
+		if p.HTTP != "" {
+			return p.HTTP
+		}
+		return p.All
+	default:
+		return p.All
+	}
+}
+
+// ProxyURL 获取访问 target 时使用的代理，target 匹配 no_proxy 或没有配置代理时返回 nil
+func (p *ProxyConfig) ProxyURL(target *url.URL) (*url.URL, error) {
+	if p == nil || !p.UseProxy(target.Host) {
+		return nil, nil
+	}
+	raw := p.proxyFor(target.Scheme)
+	if raw == "" {
+		return nil, nil
+	}
+	return resolveProxyURL(raw)
+}
+
+// ProxyFunc 返回用于 http.Transport.Proxy 的代理选择函数
+func (p *ProxyConfig) ProxyFunc() func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		return p.ProxyURL(req.URL)
+	}
+}
+
+// UseProxy 检查访问 host（可以带端口）时是否使用代理
+// no_proxy 支持：* 匹配所有主机；域名（同时匹配子域名）或 .example.com；
+// 通配符模式 *.local；IP 和 CIDR 10.0.0.0/8；可以附加 :port 只匹配该端口
+func (p *ProxyConfig) UseProxy(host string) bool {
+	hostname, port := splitHostPort(host)
+	hostname = strings.ToLower(strings.TrimSuffix(hostname, "."))
+	ip := net.ParseIP(hostname)
+
+	for _, entry := range p.NoProxy {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if entry == "*" {
+			return false
+		}
+
+		if strings.Contains(entry, "/") {
+			if _, network, err := net.ParseCIDR(entry); err == nil && ip != nil && network.Contains(ip) {
+				return false
+			}
+			continue
+		}
+
+		pattern, entryPort := splitHostPort(entry)
+		if entryPort != "" && entryPort != port {
+			continue
+		}
+		if noProxyMatch(pattern, hostname) {
+			return false
+		}
+	}
+	return true
+}
+
+// noProxyMatch 检查主机名是否匹配单个 no_proxy 模式
+func noProxyMatch(pattern, hostname string) bool {
+	if strings.ContainsAny(pattern, "*?[") {
+		matched, _ := path.Match(pattern, hostname)
+		return matched
+	}
+	if strings.HasPrefix(pattern, ".") {
+		return hostname == pattern[1:] || strings.HasSuffix(hostname, pattern)
+	}
+	if ip := net.ParseIP(pattern); ip != nil {
+		return ip.Equal(net.ParseIP(hostname))
+	}
+	return hostname == pattern || strings.HasSuffix(hostname, "."+pattern)
+}
+
+// splitHostPort 拆分主机和端口，没有端口时端口为空
+func splitHostPort(host string) (string, string) {
+	if h, port, err := net.SplitHostPort(host); err == nil {
+		return h, port
+	}
+	return strings.Trim(host, "[]"), ""
+}
+
+// resolveProxyURL 解析代理 URL：展开环境变量，省略协议时使用 http，
+// URL 中没有用户信息时从 netrc 中查找代理主机的凭据
+func resolveProxyURL(raw string) (*url.URL, error) {
+	raw = os.ExpandEnv(raw)
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+
+	proxyURL, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	if !proxySchemes[proxyURL.Scheme] {
+		return nil, fmt.Errorf("unsupported proxy scheme %s (supported: http, https, socks5, socks5h)", proxyURL.Scheme)
+	}
+	if proxyURL.Hostname() == "" {
+		return nil, fmt.Errorf("missing proxy host in %s", proxyURL.Redacted())
+	}
+
+	if proxyURL.User == nil {
+		if login, password, ok := LookupNetrc(proxyURL.Hostname()); ok && login != "" {
+			proxyURL.User = url.UserPassword(login, password)
+		}
+	}
+	return proxyURL, nil
+}
+
+// Environ 导出给子进程（git、uv、pixi 等）的代理环境变量，同时设置大写和小写形式
+// no_proxy 中的 *.example.com 转换为 curl 支持的 .example.com
+func (p *ProxyConfig) Environ() []string {
+	if p == nil {
+		return nil
+	}
+
+	var env []string
+	set := func(key, value string) {
+		env = append(env, key+"="+value, strings.ToLower(key)+"="+value)
+	}
+	for _, item := range []struct{ key, scheme string }{
+		{"HTTP_PROXY", "http"},
+		{"HTTPS_PROXY", "https"},
+		{"ALL_PROXY", ""},
+	} {
+		raw := p.proxyFor(item.scheme)
+		if raw == "" {
+			continue
+		}
+		if proxyURL, err := resolveProxyURL(raw); err == nil {
+			set(item.key, proxyURL.String())
+		}
+	}
+
+	if len(p.NoProxy) > 0 {
+		entries := make([]string, 0, len(p.NoProxy))
+		for _, entry := range p.NoProxy {
+			entry = strings.TrimSpace(entry)
+			if strings.HasPrefix(entry, "*.") {
+				entry = entry[1:]
+			}
+			entries = append(entries, entry)
+		}
+		set("NO_PROXY", strings.Join(entries, ","))
+	}
+	return env
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// 签名格式
+const (
+	SignatureOpenPGP  = "openpgp"  // OpenPGP 分离签名（.asc 或二进制 .sig）
+	SignatureMinisign = "minisign" // minisign 签名（.minisig）
+	SignatureSSH      = "ssh"      // ssh-keygen -Y sign -n file 生成的 SSH 签名
+)
+
+// TrustedKey 校验源码签名的可信公钥
+//
+//	trusted_keys:
+//	  openssl:
+//	    type: openpgp
+//	    file: keys/openssl-release.asc
+//	  libsodium:
+//	    type: minisign
+//	    key: RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3
+type TrustedKey struct {
+	Type string `yaml:"type"`           // openpgp, minisign, ssh
+	Key  string `yaml:"key,omitempty"`  // 公钥内容：ASCII 装甲的 OpenPGP 公钥、minisign 公钥或 authorized_keys 格式的 SSH 公钥
+	File string `yaml:"file,omitempty"` // 公钥文件，相对路径基于配置文件所在目录
+}
+
+// isSignatureFormat 是否为支持的签名格式
+func isSignatureFormat(format string) bool {
+	switch format {
+	case SignatureOpenPGP, SignatureMinisign, SignatureSSH:
+		return true
+	}
+	return false
+}
+
+// Validate 验证可信公钥配置
+func (k *TrustedKey) Validate(name string) error {
+	if !isSignatureFormat(k.Type) {
+		return fmt.Errorf("invalid type %q for trusted key %s (expected openpgp, minisign or ssh)", k.Type, name)
+	}
+	if (k.Key == "") == (k.File == "") {
+		return fmt.Errorf("trusted key %s requires exactly one of key or file", name)
+	}
+	return nil
+}
+
+// Material 获取公钥内容
+func (k *TrustedKey) Material() ([]byte, error) {
+	if k.Key != "" {
+		return []byte(k.Key), nil
+	}
+	data, err := os.ReadFile(k.File)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file %s: %w", k.File, err)
+	}
+	return data, nil
+}
+
+// SignatureType 获取签名格式，没有指定 signature_format 时 .minisig 为 minisign，其他为 openpgp
+func (s *SourceInfo) SignatureType() string {
+	if s.SignatureFormat != "" {
+		return s.SignatureFormat
+	}
+	if strings.HasSuffix(s.Signature, ".minisig") {
+		return SignatureMinisign
+	}
+	return SignatureOpenPGP
+}
+
+// validateSourceSignature 验证依赖的签名设置
+// signed_by 引用的公钥可能来自全局配置，在下载时才检查是否存在
+func validateSourceSignature(name string, source SourceInfo) error {
+	if source.Signature == "" {
+		if source.SignatureFormat != "" || len(source.SignedBy) > 0 {
+			return fmt.Errorf("signature_format and signed_by require signature (dependency %s)", name)
+		}
+		return nil
+	}
+	if source.Type != "archive" {
+		return fmt.Errorf("signature is only supported for archive sources (dependency %s)", name)
+	}
+	if !isSignatureFormat(source.SignatureType()) {
+		return fmt.Errorf("invalid signature_format %q for dependency %s (expected openpgp, minisign or ssh)", source.SignatureFormat, name)
+	}
+	for _, key := range source.SignedBy {
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("empty key name in signed_by of dependency %s", name)
+		}
+	}
+	return nil
+}
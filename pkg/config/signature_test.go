@@ -0,0 +1,156 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrustedKey_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     TrustedKey
+		wantErr bool
+	}{
+		{"openpgp file", TrustedKey{Type: "openpgp", File: "keys/openssl.asc"}, false},
+		{"minisign key", TrustedKey{Type: "minisign", Key: "RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3"}, false},
+		{"ssh key", TrustedKey{Type: "ssh", Key: "ssh-ed25519 AAAA release@example.com"}, false},
+		{"missing type", TrustedKey{Key: "x"}, true},
+		{"unknown type", TrustedKey{Type: "x509", Key: "x"}, true},
+		{"no key", TrustedKey{Type: "openpgp"}, true},
+		{"key and file", TrustedKey{Type: "openpgp", Key: "x", File: "y"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.key.Validate("release")
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSourceInfo_SignatureType(t *testing.T) {
+	tests := []struct {
+		source SourceInfo
+		want   string
+	}{
+		{SourceInfo{Signature: "https://example.com/openssl-3.0.0.tar.gz.asc"}, SignatureOpenPGP},
+		{SourceInfo{Signature: "https://example.com/gnupg-2.4.0.tar.bz2.sig"}, SignatureOpenPGP},
+		{SourceInfo{Signature: "https://example.com/libsodium-1.0.19.tar.gz.minisig"}, SignatureMinisign},
+		{SourceInfo{Signature: "https://example.com/tool-1.0.tar.gz.sig", SignatureFormat: "ssh"}, SignatureSSH},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source.Signature, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.source.SignatureType())
+		})
+	}
+}
+
+func TestValidateSourceSignature(t *testing.T) {
+	archive := SourceInfo{Type: "archive", URLS: []string{"https://example.com/a.tar.gz"}}
+
+	tests := []struct {
+		name    string
+		modify  func(s *SourceInfo)
+		wantErr bool
+	}{
+		{"no signature", func(s *SourceInfo) {}, false},
+		{"openpgp", func(s *SourceInfo) { s.Signature = "https://example.com/a.tar.gz.asc" }, false},
+		{"signed by", func(s *SourceInfo) {
+			s.Signature = "a.tar.gz.minisig"
+			s.SignedBy = []string{"release"}
+		}, false},
+		{"git source", func(s *SourceInfo) {
+			s.Type = "git"
+			s.Signature = "https://example.com/a.asc"
+		}, true},
+		{"unknown format", func(s *SourceInfo) {
+			s.Signature = "a.sig"
+			s.SignatureFormat = "x509"
+		}, true},
+		{"format without signature", func(s *SourceInfo) { s.SignatureFormat = "ssh" }, true},
+		{"signed by without signature", func(s *SourceInfo) { s.SignedBy = []string{"release"} }, true},
+		{"empty signed by", func(s *SourceInfo) {
+			s.Signature = "a.asc"
+			s.SignedBy = []string{""}
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := archive
+			tt.modify(&source)
+			err := validateSourceSignature("lib", source)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestConfigLoader_LoadSignaturePaths(t *testing.T) {
+	tempDir := t.TempDir()
+	configContent := `
+project:
+  name: "app"
+  version: "1.0.0"
+
+trusted_keys:
+  openssl:
+    type: openpgp
+    file: keys/openssl.asc
+  remote:
+    type: minisign
+    key: RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3
+
+dependencies:
+  openssl:
+    version: "3.0.0"
+    source:
+      type: archive
+      urls: ["https://example.com/openssl-3.0.0.tar.gz"]
+      signature: sigs/openssl-3.0.0.tar.gz.asc
+      signed_by: [openssl]
+    build_system: none
+  libsodium:
+    version: "1.0.19"
+    source:
+      type: archive
+      urls: ["https://example.com/libsodium-1.0.19.tar.gz"]
+      signature: https://example.com/libsodium-1.0.19.tar.gz.minisig
+    build_system: none
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "buildfly.yaml"), []byte(configContent), 0644))
+
+	config, err := NewConfigLoader(tempDir).Load("buildfly.yaml")
+	require.NoError(t, err)
+
+	assert.Equal(t, filepath.Join(tempDir, "keys", "openssl.asc"), config.TrustedKeys["openssl"].File)
+	assert.Equal(t, filepath.Join(tempDir, "sigs", "openssl-3.0.0.tar.gz.asc"), config.Dependencies["openssl"].Source.Signature)
+	assert.Equal(t, "https://example.com/libsodium-1.0.19.tar.gz.minisig", config.Dependencies["libsodium"].Source.Signature)
+}
+
+func TestConfigLoader_mergeTrustedKeys(t *testing.T) {
+	global := &ProjectConfig{TrustedKeys: map[string]TrustedKey{
+		"openssl": {Type: "openpgp", File: "/global/openssl.asc"},
+		"gnupg":   {Type: "openpgp", File: "/global/gnupg.asc"},
+	}}
+	local := &ProjectConfig{TrustedKeys: map[string]TrustedKey{
+		"openssl": {Type: "openpgp", File: "/project/openssl.asc"},
+	}}
+
+	merged := NewConfigLoader(".").mergeConfigs(global, local)
+	assert.Equal(t, "/global/gnupg.asc", merged.TrustedKeys["gnupg"].File)
+	assert.Equal(t, "/project/openssl.asc", merged.TrustedKeys["openssl"].File)
+	assert.Equal(t, "/global/openssl.asc", global.TrustedKeys["openssl"].File, "global config must not be modified")
+}
@@ -0,0 +1,238 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Toolchain 交叉编译工具链配置
+// 当目标构建标签的 arch/platform 与主机不同时，根据 arch/platform 选择匹配的工具链
+type Toolchain struct {
+	Name               string            `yaml:"-"`
+	Arch               string            `yaml:"arch"`                           // 目标架构，例如 arm64
+	Platform           string            `yaml:"platform"`                       // 目标平台，例如 linux
+	Triple             string            `yaml:"triple,omitempty"`               // 编译器三元组，例如 aarch64-linux-gnu
+	CC                 string            `yaml:"cc,omitempty"`                   // C 编译器，默认 {triple}-gcc
+	CXX                string            `yaml:"cxx,omitempty"`                  // C++ 编译器，默认 {triple}-g++
+	AR                 string            `yaml:"ar,omitempty"`                   // 归档工具，默认 {triple}-ar
+	Ranlib             string            `yaml:"ranlib,omitempty"`               // 默认 {triple}-ranlib
+	Strip              string            `yaml:"strip,omitempty"`                // 默认 {triple}-strip
+	Sysroot            string            `yaml:"sysroot,omitempty"`              // 目标系统根目录
+	CMakeToolchainFile string            `yaml:"cmake_toolchain_file,omitempty"` // CMake 工具链文件，设置后优先使用
+	Host               string            `yaml:"host,omitempty"`                 // configure --host，默认等于 triple
+	EnvVariables       map[string]string `yaml:"env_variables,omitempty"`        // 额外的环境变量
+}
+
+// archAliases 架构别名，用于比较构建标签中的架构
+var archAliases = map[string]string{
+	"x64":     "x86_64",
+	"amd64":   "x86_64",
+	"aarch64": "arm64",
+}
+
+// NormalizeArch 规范化架构名称（x64/amd64 → x86_64，aarch64 → arm64）
+func NormalizeArch(arch string) string {
+	if alias, ok := archAliases[arch]; ok {
+		return alias
+	}
+	return arch
+}
+
+// IsCrossTarget 检查构建标签是否描述了与主机不同的目标（架构或平台）
+func IsCrossTarget(target *BuildTag) bool {
+	if target == nil {
+		return false
+	}
+
+	if target.Arch != "" && target.Arch != "any" {
+		hostArch, _ := detectArchitecture()
+		if NormalizeArch(target.Arch) != NormalizeArch(hostArch) {
+			return true
+		}
+	}
+
+	if target.Platform != "" && target.Platform != "any" {
+		hostPlatform, _ := detectPlatform()
+		if target.Platform != hostPlatform {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Matches 检查工具链是否匹配目标构建标签
+// 目标未指定 arch/platform（或为 any）时按主机的 arch/platform 比较
+func (tc *Toolchain) Matches(target *BuildTag) bool {
+	if target == nil {
+		return false
+	}
+
+	arch := target.Arch
+	if arch == "" || arch == "any" {
+		arch, _ = detectArchitecture()
+	}
+	platform := target.Platform
+	if platform == "" || platform == "any" {
+		platform, _ = detectPlatform()
+	}
+
+	if tc.Arch != "" && NormalizeArch(tc.Arch) != NormalizeArch(arch) {
+		return false
+	}
+	if tc.Platform != "" && tc.Platform != platform {
+		return false
+	}
+	return true
+}
+
+// SelectToolchain 为目标构建标签选择工具链
+// 目标与主机相同时返回 nil；目标不同但没有匹配的工具链时返回错误
+func (pc *ProjectConfig) SelectToolchain(target *BuildTag) (*Toolchain, error) {
+	if !IsCrossTarget(target) {
+		return nil, nil
+	}
+
+	// 按名称排序，保证多个工具链匹配时结果稳定
+	names := make([]string, 0, len(pc.Toolchains))
+	for name := range pc.Toolchains {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		tc := pc.Toolchains[name]
+		if tc.Matches(target) {
+			tc.Name = name
+			return &tc, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no toolchain configured for target arch=%s,platform=%s", target.Arch, target.Platform)
+}
+
+// tool 返回指定工具的路径，未配置时根据三元组推导
+func (tc *Toolchain) tool(configured, suffix string) string {
+	if configured != "" {
+		return configured
+	}
+	if tc.Triple != "" {
+		return tc.Triple + "-" + suffix
+	}
+	return ""
+}
+
+// GetCC 获取 C 编译器
+func (tc *Toolchain) GetCC() string {
+	return tc.tool(tc.CC, "gcc")
+}
+
+// GetCXX 获取 C++ 编译器
+func (tc *Toolchain) GetCXX() string {
+	return tc.tool(tc.CXX, "g++")
+}
+
+// GetHost 获取 configure 的 --host 参数
+func (tc *Toolchain) GetHost() string {
+	if tc.Host != "" {
+		return tc.Host
+	}
+	return tc.Triple
+}
+
+// EnvVars 获取工具链的环境变量
+func (tc *Toolchain) EnvVars() map[string]string {
+	vars := make(map[string]string)
+
+	tools := map[string]string{
+		"CC":     tc.GetCC(),
+		"CXX":    tc.GetCXX(),
+		"AR":     tc.tool(tc.AR, "ar"),
+		"RANLIB": tc.tool(tc.Ranlib, "ranlib"),
+		"STRIP":  tc.tool(tc.Strip, "strip"),
+	}
+	for key, value := range tools {
+		if value != "" {
+			vars[key] = value
+		}
+	}
+
+	if tc.Sysroot != "" {
+		vars["SYSROOT"] = tc.Sysroot
+	}
+
+	for key, value := range tc.EnvVariables {
+		vars[key] = value
+	}
+
+	return vars
+}
+
+// CMakeArgs 获取 CMake 交叉编译参数
+func (tc *Toolchain) CMakeArgs() []string {
+	if tc.CMakeToolchainFile != "" {
+		return []string{fmt.Sprintf("-DCMAKE_TOOLCHAIN_FILE=%s", tc.CMakeToolchainFile)}
+	}
+
+	var args []string
+	if systemName := cmakeSystemName(tc.Platform); systemName != "" {
+		args = append(args, fmt.Sprintf("-DCMAKE_SYSTEM_NAME=%s", systemName))
+	}
+	if tc.Arch != "" {
+		args = append(args, fmt.Sprintf("-DCMAKE_SYSTEM_PROCESSOR=%s", cmakeSystemProcessor(tc.Arch)))
+	}
+	if cc := tc.GetCC(); cc != "" {
+		args = append(args, fmt.Sprintf("-DCMAKE_C_COMPILER=%s", cc))
+	}
+	if cxx := tc.GetCXX(); cxx != "" {
+		args = append(args, fmt.Sprintf("-DCMAKE_CXX_COMPILER=%s", cxx))
+	}
+	if tc.Sysroot != "" {
+		args = append(args,
+			fmt.Sprintf("-DCMAKE_SYSROOT=%s", tc.Sysroot),
+			fmt.Sprintf("-DCMAKE_FIND_ROOT_PATH=%s", tc.Sysroot),
+			"-DCMAKE_FIND_ROOT_PATH_MODE_PROGRAM=NEVER",
+			"-DCMAKE_FIND_ROOT_PATH_MODE_LIBRARY=ONLY",
+			"-DCMAKE_FIND_ROOT_PATH_MODE_INCLUDE=ONLY",
+		)
+	}
+
+	return args
+}
+
+// ConfigureArgs 获取 configure 交叉编译参数
+func (tc *Toolchain) ConfigureArgs() []string {
+	var args []string
+	if host := tc.GetHost(); host != "" {
+		args = append(args, fmt.Sprintf("--host=%s", host))
+	}
+	if tc.Sysroot != "" {
+		args = append(args, fmt.Sprintf("--with-sysroot=%s", tc.Sysroot))
+	}
+	return args
+}
+
+// cmakeSystemName 将平台映射为 CMAKE_SYSTEM_NAME
+func cmakeSystemName(platform string) string {
+	switch strings.ToLower(platform) {
+	case "linux":
+		return "Linux"
+	case "darwin":
+		return "Darwin"
+	case "windows":
+		return "Windows"
+	default:
+		return ""
+	}
+}
+
+// cmakeSystemProcessor 将架构映射为 CMAKE_SYSTEM_PROCESSOR
+func cmakeSystemProcessor(arch string) string {
+	switch NormalizeArch(arch) {
+	case "arm64":
+		return "aarch64"
+	default:
+		return NormalizeArch(arch)
+	}
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+// crossArch 返回与主机不同的架构，用于构造交叉编译目标
+func crossArch() string {
+	hostArch, _ := detectArchitecture()
+	if NormalizeArch(hostArch) == "arm64" {
+		return "x86_64"
+	}
+	return "arm64"
+}
+
+func TestIsCrossTarget(t *testing.T) {
+	hostArch, _ := detectArchitecture()
+	hostPlatform, _ := detectPlatform()
+
+	tests := []struct {
+		name   string
+		target *BuildTag
+		want   bool
+	}{
+		{"nil tag", nil, false},
+		{"empty tag", &BuildTag{}, false},
+		{"host tag", &BuildTag{Arch: hostArch, Platform: hostPlatform}, false},
+		{"any arch", &BuildTag{Arch: "any", Platform: hostPlatform}, false},
+		{"different arch", &BuildTag{Arch: crossArch(), Platform: hostPlatform}, true},
+		{"different platform", &BuildTag{Arch: hostArch, Platform: "windows-not-host"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsCrossTarget(tt.target); got != tt.want {
+				t.Errorf("IsCrossTarget() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectToolchain(t *testing.T) {
+	hostPlatform, _ := detectPlatform()
+	target := &BuildTag{Arch: crossArch(), Platform: hostPlatform}
+
+	pc := &ProjectConfig{
+		Toolchains: map[string]Toolchain{
+			"other": {Arch: "i386", Platform: hostPlatform, Triple: "i686-linux-gnu"},
+			"cross": {Arch: target.Arch, Platform: hostPlatform, Triple: "cross-linux-gnu"},
+		},
+	}
+
+	tc, err := pc.SelectToolchain(target)
+	if err != nil {
+		t.Fatalf("SelectToolchain() error = %v", err)
+	}
+	if tc == nil || tc.Name != "cross" {
+		t.Fatalf("SelectToolchain() = %+v, want toolchain cross", tc)
+	}
+
+	// 只指定 arch 的标签（例如 arch=arm64）按主机平台匹配工具链
+	tc, err = pc.SelectToolchain(&BuildTag{Arch: target.Arch})
+	if err != nil {
+		t.Fatalf("SelectToolchain(arch only) error = %v", err)
+	}
+	if tc == nil || tc.Name != "cross" {
+		t.Fatalf("SelectToolchain(arch only) = %+v, want toolchain cross", tc)
+	}
+
+	// 主机目标不需要工具链
+	hostArch, _ := detectArchitecture()
+	if tc, err := pc.SelectToolchain(&BuildTag{Arch: hostArch, Platform: hostPlatform}); err != nil || tc != nil {
+		t.Errorf("SelectToolchain(host) = %+v, %v, want nil, nil", tc, err)
+	}
+
+	// 没有匹配的工具链时报错
+	empty := &ProjectConfig{}
+	if _, err := empty.SelectToolchain(target); err == nil {
+		t.Error("SelectToolchain() expected error when no toolchain matches")
+	}
+}
+
+func TestToolchainArgs(t *testing.T) {
+	tc := &Toolchain{
+		Arch:     "arm64",
+		Platform: "linux",
+		Triple:   "aarch64-linux-gnu",
+		Sysroot:  "/opt/sysroot",
+	}
+
+	if got := tc.GetCC(); got != "aarch64-linux-gnu-gcc" {
+		t.Errorf("GetCC() = %s", got)
+	}
+	if got := tc.GetCXX(); got != "aarch64-linux-gnu-g++" {
+		t.Errorf("GetCXX() = %s", got)
+	}
+
+	wantConfigure := []string{"--host=aarch64-linux-gnu", "--with-sysroot=/opt/sysroot"}
+	if got := tc.ConfigureArgs(); !reflect.DeepEqual(got, wantConfigure) {
+		t.Errorf("ConfigureArgs() = %v, want %v", got, wantConfigure)
+	}
+
+	cmakeArgs := tc.CMakeArgs()
+	for _, want := range []string{
+		"-DCMAKE_SYSTEM_NAME=Linux",
+		"-DCMAKE_SYSTEM_PROCESSOR=aarch64",
+		"-DCMAKE_C_COMPILER=aarch64-linux-gnu-gcc",
+		"-DCMAKE_CXX_COMPILER=aarch64-linux-gnu-g++",
+		"-DCMAKE_SYSROOT=/opt/sysroot",
+	} {
+		if !contains(cmakeArgs, want) {
+			t.Errorf("CMakeArgs() missing %s, got %v", want, cmakeArgs)
+		}
+	}
+
+	// 指定工具链文件时只使用工具链文件
+	tc.CMakeToolchainFile = "/opt/toolchain.cmake"
+	if got := tc.CMakeArgs(); !reflect.DeepEqual(got, []string{"-DCMAKE_TOOLCHAIN_FILE=/opt/toolchain.cmake"}) {
+		t.Errorf("CMakeArgs() = %v", got)
+	}
+
+	env := tc.EnvVars()
+	if env["CC"] != "aarch64-linux-gnu-gcc" || env["AR"] != "aarch64-linux-gnu-ar" || env["SYSROOT"] != "/opt/sysroot" {
+		t.Errorf("EnvVars() = %v", env)
+	}
+}
+
+func TestToolchainMatches(t *testing.T) {
+	hostArch, _ := detectArchitecture()
+	hostPlatform, _ := detectPlatform()
+	tc := &Toolchain{Arch: crossArch(), Platform: hostPlatform}
+
+	tests := []struct {
+		name   string
+		target *BuildTag
+		want   bool
+	}{
+		{"nil tag", nil, false},
+		{"full tag", &BuildTag{Arch: crossArch(), Platform: hostPlatform}, true},
+		{"arch only", &BuildTag{Arch: crossArch()}, true},
+		{"arch with any platform", &BuildTag{Arch: crossArch(), Platform: "any"}, true},
+		{"host arch", &BuildTag{Arch: hostArch}, false},
+		{"other platform", &BuildTag{Arch: crossArch(), Platform: "windows-not-host"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tc.Matches(tt.target); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
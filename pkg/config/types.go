@@ -24,9 +24,33 @@ type ProjectConfig struct {
 	// BuildTag 当前项目的构建标签
 	BuildTag *BuildTag `yaml:"build_tag,omitempty"`
 
+	// BuildTagDimensions 自定义构建标签维度
+	BuildTagDimensions BuildTagDimensions `yaml:"build_tag_dimensions,omitempty"`
+
+	// Toolchains 交叉编译工具链，按目标构建标签的 arch/platform 选择
+	Toolchains map[string]Toolchain `yaml:"toolchains,omitempty"`
+
+	// Hermetic 使用密封构建环境，只从主机继承白名单中的环境变量，未设置时不启用
+	Hermetic *bool `yaml:"hermetic,omitempty"`
+
+	// VerifyArtifacts 构建后校验产物是否满足构建标签的 runtime/arch：warn（默认）、error、off
+	VerifyArtifacts string `yaml:"verify_artifacts,omitempty"`
+
+	// ChecksumPolicy 没有配置校验和的压缩包依赖如何处理：none（默认，不校验）、tofu（首次下载时记录到 buildfly.lock，之后校验）
+	ChecksumPolicy string `yaml:"checksum_policy,omitempty"`
+
+	// CompilerCache 编译器缓存配置（ccache/sccache）
+	CompilerCache *CompilerCacheConfig `yaml:"compiler_cache,omitempty"`
+
 	// Proxy 代理配置
 	Proxy *ProxyConfig `yaml:"proxy,omitempty"`
 
+	// Credentials 按主机配置的下载凭据，键为主机名（可以带端口）或通配符模式 *.example.com
+	Credentials map[string]HostCredential `yaml:"credentials,omitempty"`
+
+	// TrustedKeys 校验源码签名的可信公钥，键为公钥名称
+	TrustedKeys map[string]TrustedKey `yaml:"trusted_keys,omitempty"`
+
 	// VEnv 虚拟环境配置
 	VEnv *venv.VEnvConfig `yaml:"venv,omitempty"`
 }
@@ -50,21 +74,35 @@ type Dependency struct {
 	CustomScript     string            `yaml:"custom_script,omitempty"`
 	BuildCommands    BuildCommands     `yaml:"build_commands,omitempty"`
 	EnvVariables     map[string]string `yaml:"env_variables,omitempty"`
-	CacheKey         string            `yaml:"-"` // 缓存键，自动生成
-	LastUpdated      time.Time         `yaml:"-"` // 最后更新时间
+	Hermetic         *bool             `yaml:"hermetic,omitempty"` // 覆盖全局 hermetic 设置
+	CacheKey         string            `yaml:"-"`                  // 缓存键，自动生成
+	LastUpdated      time.Time         `yaml:"-"`                  // 最后更新时间
 }
 
 // 源码信息
 type SourceInfo struct {
-	Type      string            `yaml:"type"` // git, archive, direct
-	URLS      []string          `yaml:"urls"`
-	Tag       string            `yaml:"tag,omitempty"`
-	Hash      string            `yaml:"hash,omitempty"`      // SHA256 哈希值（向后兼容）
-	MD5       string            `yaml:"md5,omitempty"`       // MD5 哈希值
-	SHA1      string            `yaml:"sha1,omitempty"`      // SHA1 哈希值
-	SHA256    string            `yaml:"sha256,omitempty"`    // SHA256 哈希值
-	SHA512    string            `yaml:"sha512,omitempty"`    // SHA512 哈希值
-	Checksums map[string]string `yaml:"checksums,omitempty"` // 通用校验和映射
+	Type        string            `yaml:"type"` // git, archive, direct, path
+	URLS        []string          `yaml:"urls"`
+	Path        string            `yaml:"path,omitempty"` // 本地源码目录（type: path），相对路径基于项目根目录
+	Tag         string            `yaml:"tag,omitempty"`
+	Commit      string            `yaml:"commit,omitempty"`       // Git 提交哈希，固定到确切的提交
+	Branch      string            `yaml:"branch,omitempty"`       // Git 分支
+	Submodules  *Submodules       `yaml:"submodules,omitempty"`   // Git 子模块：true/false 或路径列表
+	SparsePaths []string          `yaml:"sparse_paths,omitempty"` // Git 稀疏检出只检出这些目录
+	Filter      string            `yaml:"filter,omitempty"`       // Git 部分克隆过滤器，例如 blob:none
+	Subdir      string            `yaml:"subdir,omitempty"`       // 构建系统使用的源码子目录
+	LFS         *LFS              `yaml:"lfs,omitempty"`          // Git LFS：true/false 或 include 模式列表
+	Hash        string            `yaml:"hash,omitempty"`         // SHA256 哈希值（向后兼容）
+	MD5         string            `yaml:"md5,omitempty"`          // MD5 哈希值
+	SHA1        string            `yaml:"sha1,omitempty"`         // SHA1 哈希值
+	SHA256      string            `yaml:"sha256,omitempty"`       // SHA256 哈希值
+	SHA512      string            `yaml:"sha512,omitempty"`       // SHA512 哈希值
+	Checksums   map[string]string `yaml:"checksums,omitempty"`    // 通用校验和映射
+
+	// Signature 压缩包分离签名的 URL 或本地路径（相对路径基于项目根目录），解压前使用 trusted_keys 校验
+	Signature       string   `yaml:"signature,omitempty"`
+	SignatureFormat string   `yaml:"signature_format,omitempty"` // openpgp、minisign、ssh，默认按签名文件扩展名推断
+	SignedBy        []string `yaml:"signed_by,omitempty"`        // 允许签名的 trusted_keys 名称，默认为所有同格式的可信公钥
 }
 
 // 构建命令
@@ -80,6 +118,10 @@ type BuildProfile struct {
 	Variables    map[string]string `yaml:"variables,omitempty"`
 	Dependencies []string          `yaml:"dependencies"`
 	BuildTag     *BuildTag         `yaml:"build_tag,omitempty"`
+
+	// Matrix 构建矩阵，键为构建标签维度，值为该维度的取值列表
+	// 例如 {build_type: [Release, Debug], compiler: [gcc_11, clang_15]}
+	Matrix map[string][]string `yaml:"matrix,omitempty"`
 }
 
 // 解析后的依赖项
@@ -106,6 +148,29 @@ type ResolutionResult struct {
 	BuildTime    time.Duration        `yaml:"build_time"`
 }
 
+// IsHermetic 检查依赖是否使用密封构建环境，依赖级别的设置优先于全局设置
+func (pc *ProjectConfig) IsHermetic(dep Dependency) bool {
+	if dep.Hermetic != nil {
+		return *dep.Hermetic
+	}
+	return pc.Hermetic != nil && *pc.Hermetic
+}
+
+// SourcePath 获取构建系统使用的源码目录，指定了 subdir 时为源码树中的子目录
+func (s *SourceInfo) SourcePath(root string) string {
+	return filepath.Join(root, s.Subdir)
+}
+
+// IsLocalPath 是否为本地路径源码，就地构建且不使用共享缓存
+func (s *SourceInfo) IsLocalPath() bool {
+	return s.Type == "path"
+}
+
+// HasChecksum 是否在配置中指定了校验和
+func (s *SourceInfo) HasChecksum() bool {
+	return s.Hash != "" || s.MD5 != "" || s.SHA1 != "" || s.SHA256 != "" || s.SHA512 != "" || len(s.Checksums) > 0
+}
+
 // GetURLs 获取所有 URL，支持向后兼容
 func (s *SourceInfo) GetURLs() []string {
 	if len(s.URLS) > 0 {
@@ -216,13 +281,37 @@ func (s *SourceInfo) GetPrimaryURL() string {
 	return urls[0]
 }
 
+// CompilerCacheConfig 编译器缓存配置
+type CompilerCacheConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Tool    string `yaml:"tool,omitempty"`     // ccache, sccache，默认 ccache
+	Dir     string `yaml:"dir,omitempty"`      // 缓存目录，默认 {cache_dir}/ccache
+	MaxSize string `yaml:"max_size,omitempty"` // 最大缓存大小，例如 5G
+}
+
 // ProxyConfig 代理配置
+// 代理 URL 支持 http://、https://、socks5:// 和 socks5h://，可以使用 ${ENV} 引用环境变量，
+// 没有用户信息时从 netrc 中查找代理主机的凭据
 type ProxyConfig struct {
 	HTTP    string   `yaml:"http" json:"http"`
 	HTTPS   string   `yaml:"https" json:"https"`
+	All     string   `yaml:"all,omitempty" json:"all,omitempty"` // 没有单独配置的协议使用的代理
 	NoProxy []string `yaml:"no_proxy" json:"no_proxy"`
 }
 
+// HostCredential 主机凭据配置
+// username/password/token 可以使用 ${ENV} 引用环境变量，凭据不应直接写在提交到仓库的配置文件中
+type HostCredential struct {
+	Type     string `yaml:"type"`               // netrc, bearer, basic, command
+	Username string `yaml:"username,omitempty"` // basic
+	Password string `yaml:"password,omitempty"` // basic
+	Token    string `yaml:"token,omitempty"`    // bearer
+	Command  string `yaml:"command,omitempty"`  // command：输出 git-credential 格式的 username=/password=/token= 行
+
+	// AllowHTTP 允许通过明文 http 发送凭据，默认只在 https 请求中发送
+	AllowHTTP bool `yaml:"allow_http,omitempty"`
+}
+
 // SourceError 源码相关错误
 type SourceError struct {
 	Message string
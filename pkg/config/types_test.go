@@ -152,3 +152,30 @@ func TestSourceInfo_GetPrimaryURL(t *testing.T) {
 		})
 	}
 }
+
+func TestProjectConfig_IsHermetic(t *testing.T) {
+	enabled, disabled := true, false
+
+	tests := []struct {
+		name     string
+		global   *bool
+		override *bool
+		expected bool
+	}{
+		{"default", nil, nil, false},
+		{"global enabled", &enabled, nil, true},
+		{"global disabled", &disabled, nil, false},
+		{"dependency enables", nil, &enabled, true},
+		{"dependency disables", &enabled, &disabled, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pc := &ProjectConfig{Hermetic: tt.global}
+			dep := Dependency{Name: "zlib", Hermetic: tt.override}
+			if got := pc.IsHermetic(dep); got != tt.expected {
+				t.Errorf("IsHermetic() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -45,6 +45,9 @@ type VariableContext struct {
 	// BuildTag 构建标签
 	BuildTag *BuildTag
 
+	// Toolchain 交叉编译工具链（目标与主机相同时为 nil）
+	Toolchain *Toolchain
+
 	// ProjectConfig 项目配置引用
 	ProjectConfig *ProjectConfig
 }
@@ -95,6 +98,14 @@ func (vc *VariableContext) SetDepInfo(name, version string) {
 // SetBuildTag 设置构建标签
 func (vc *VariableContext) SetBuildTag(buildTag *BuildTag) {
 	vc.BuildTag = buildTag
+	if buildTag != nil && buildTag.BuildType != "" {
+		vc.BuildType = buildTag.BuildType
+	}
+}
+
+// SetToolchain 设置交叉编译工具链
+func (vc *VariableContext) SetToolchain(toolchain *Toolchain) {
+	vc.Toolchain = toolchain
 }
 
 // GetBuildTagDir 获取构建标签目录名
@@ -211,7 +222,33 @@ func (vc *VariableContext) ExpandCommand(script string) (string, error) {
 			return ""
 		case "BUILD_TAG_DIR":
 			return vc.GetBuildTagDir()
+		case "TARGET_TRIPLE":
+			if vc.Toolchain != nil {
+				return vc.Toolchain.Triple
+			}
+			return ""
+		case "SYSROOT":
+			if vc.Toolchain != nil {
+				return vc.Toolchain.Sysroot
+			}
+			return ""
+		case "LINK":
+			if vc.BuildTag != nil {
+				return vc.BuildTag.Link
+			}
+			return ""
+		case "SANITIZER":
+			if vc.BuildTag != nil {
+				return vc.BuildTag.Sanitizer
+			}
+			return ""
 		default:
+			// 自定义构建标签维度：${BUILD_TAG_SIMD} → simd
+			if name, ok := strings.CutPrefix(strings.ToUpper(key), "BUILD_TAG_"); ok && vc.BuildTag != nil {
+				if value, exists := vc.BuildTag.Extra[strings.ToLower(name)]; exists {
+					return value
+				}
+			}
 			// 尝试从环境变量获取
 			if envVal := os.Getenv(key); envVal != "" {
 				return envVal
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sanitizerFlags sanitizer 对应的编译器标志
+var sanitizerFlags = map[string][]string{
+	"asan":  {"-fsanitize=address", "-fno-omit-frame-pointer"},
+	"tsan":  {"-fsanitize=thread"},
+	"ubsan": {"-fsanitize=undefined"},
+	"msan":  {"-fsanitize=memory", "-fno-omit-frame-pointer"},
+}
+
+// Sanitizers 获取构建标签中的 sanitizer 列表
+func (bt *BuildTag) Sanitizers() []string {
+	if bt == nil || bt.Sanitizer == "" {
+		return nil
+	}
+	return strings.Split(bt.Sanitizer, "|")
+}
+
+// validateSanitizers 验证 sanitizer 组合
+// tsan 和 msan 不能与其他运行时检测 sanitizer 同时使用，ubsan 可以与任意一个组合
+func validateSanitizers(sanitizers []string) error {
+	runtimes := 0
+	for _, sanitizer := range sanitizers {
+		if _, ok := sanitizerFlags[sanitizer]; !ok {
+			return fmt.Errorf("invalid sanitizer: %s", sanitizer)
+		}
+		if sanitizer != "ubsan" {
+			runtimes++
+		}
+	}
+	if runtimes > 1 {
+		return fmt.Errorf("incompatible sanitizers: %s", strings.Join(sanitizers, "|"))
+	}
+	return nil
+}
+
+// SanitizerFlags 获取 sanitizer 的编译和链接标志
+func (bt *BuildTag) SanitizerFlags() []string {
+	var flags []string
+	seen := make(map[string]bool)
+	for _, sanitizer := range bt.Sanitizers() {
+		for _, flag := range sanitizerFlags[sanitizer] {
+			if !seen[flag] {
+				seen[flag] = true
+				flags = append(flags, flag)
+			}
+		}
+	}
+	return flags
+}
+
+// VariantCMakeArgs 获取构建变体（link/sanitizer）对应的 CMake 参数
+// sanitizer 标志通过 CFLAGS/CXXFLAGS/LDFLAGS 环境变量传递，见 VariantEnvFlags
+func (bt *BuildTag) VariantCMakeArgs() []string {
+	if bt == nil {
+		return nil
+	}
+
+	var args []string
+	switch bt.Link {
+	case "static":
+		args = append(args, "-DBUILD_SHARED_LIBS=OFF", "-DCMAKE_POSITION_INDEPENDENT_CODE=ON")
+	case "shared":
+		args = append(args, "-DBUILD_SHARED_LIBS=ON")
+	}
+	return args
+}
+
+// VariantConfigureArgs 获取构建变体对应的 configure 参数
+func (bt *BuildTag) VariantConfigureArgs() []string {
+	if bt == nil {
+		return nil
+	}
+
+	switch bt.Link {
+	case "static":
+		return []string{"--enable-static", "--disable-shared", "--with-pic"}
+	case "shared":
+		return []string{"--enable-shared", "--disable-static"}
+	}
+	return nil
+}
+
+// VariantEnvFlags 获取构建变体需要追加到 CFLAGS/CXXFLAGS/LDFLAGS 的标志
+func (bt *BuildTag) VariantEnvFlags() map[string]string {
+	flags := bt.SanitizerFlags()
+	if len(flags) == 0 {
+		return nil
+	}
+
+	value := strings.Join(flags, " ")
+	return map[string]string{
+		"CFLAGS":   value,
+		"CXXFLAGS": value,
+		"LDFLAGS":  value,
+	}
+}
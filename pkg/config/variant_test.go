@@ -0,0 +1,39 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuildTagVariants(t *testing.T) {
+	static := &BuildTag{Arch: "x86_64", Platform: "linux", Link: "static"}
+	shared := &BuildTag{Arch: "x86_64", Platform: "linux", Link: "shared"}
+	asan := &BuildTag{Arch: "x86_64", Platform: "linux", Link: "static", Sanitizer: "asan"}
+
+	// 不同变体不应相等，避免缓存互相覆盖
+	if static.Equals(shared) || static.Equals(asan) {
+		t.Error("Expected different variants to be unequal")
+	}
+	if static.ToDirName() == shared.ToDirName() {
+		t.Error("Expected different variants to use different directories")
+	}
+
+	if got := static.VariantCMakeArgs(); !reflect.DeepEqual(got, []string{"-DBUILD_SHARED_LIBS=OFF", "-DCMAKE_POSITION_INDEPENDENT_CODE=ON"}) {
+		t.Errorf("VariantCMakeArgs() = %v", got)
+	}
+	if got := shared.VariantConfigureArgs(); !reflect.DeepEqual(got, []string{"--enable-shared", "--disable-static"}) {
+		t.Errorf("VariantConfigureArgs() = %v", got)
+	}
+
+	flags := (&BuildTag{Sanitizer: "asan|ubsan"}).VariantEnvFlags()
+	want := "-fsanitize=address -fno-omit-frame-pointer -fsanitize=undefined"
+	if flags["CFLAGS"] != want || flags["CXXFLAGS"] != want || flags["LDFLAGS"] != want {
+		t.Errorf("VariantEnvFlags() = %v", flags)
+	}
+
+	// 未指定变体时不添加任何参数
+	var none *BuildTag
+	if none.VariantCMakeArgs() != nil || none.VariantConfigureArgs() != nil || none.VariantEnvFlags() != nil {
+		t.Error("Expected no variant arguments for nil build tag")
+	}
+}
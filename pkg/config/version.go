@@ -0,0 +1,44 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ParseVersionedValue 解析带版本的构建标签值
+// 示例: "glibc_2.35+" → ("glibc", "2.35", true)，"musl" → ("musl", "", false)
+func ParseVersionedValue(value string) (name, version string, orNewer bool) {
+	orNewer = strings.HasSuffix(value, "+")
+	value = strings.TrimSuffix(value, "+")
+
+	if idx := strings.LastIndex(value, "_"); idx >= 0 {
+		return value[:idx], value[idx+1:], orNewer
+	}
+	return value, "", orNewer
+}
+
+// CompareVersions 按数字逐段比较版本号，返回 -1、0 或 1
+// 示例: CompareVersions("2.9", "2.35") = -1
+func CompareVersions(a, b string) int {
+	partsA := strings.Split(a, ".")
+	partsB := strings.Split(b, ".")
+
+	for i := 0; i < len(partsA) || i < len(partsB); i++ {
+		var numA, numB int
+		if i < len(partsA) {
+			numA, _ = strconv.Atoi(partsA[i])
+		}
+		if i < len(partsB) {
+			numB, _ = strconv.Atoi(partsB[i])
+		}
+
+		switch {
+		case numA < numB:
+			return -1
+		case numA > numB:
+			return 1
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,48 @@
+package config
+
+import "testing"
+
+func TestParseVersionedValue(t *testing.T) {
+	tests := []struct {
+		value       string
+		wantName    string
+		wantVersion string
+		wantNewer   bool
+	}{
+		{"glibc_2.35", "glibc", "2.35", false},
+		{"glibc_2.35+", "glibc", "2.35", true},
+		{"gcc_11+", "gcc", "11", true},
+		{"musl", "musl", "", false},
+		{"apple-clang_15", "apple-clang", "15", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			name, version, orNewer := ParseVersionedValue(tt.value)
+			if name != tt.wantName || version != tt.wantVersion || orNewer != tt.wantNewer {
+				t.Errorf("ParseVersionedValue(%q) = (%q, %q, %v), want (%q, %q, %v)",
+					tt.value, name, version, orNewer, tt.wantName, tt.wantVersion, tt.wantNewer)
+			}
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"2.35", "2.35", 0},
+		{"2.9", "2.35", -1},
+		{"2.35", "2.4", 1},
+		{"3.4.29", "3.4.30", -1},
+		{"2.35", "2.35.0", 0},
+		{"11", "9", 1},
+	}
+
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -9,21 +9,27 @@ import (
 	"fmt"
 	"hash"
 	"io"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"buildfly/internal/errors"
+	"buildfly/internal/logging"
 	"buildfly/pkg/config"
+	"buildfly/pkg/utils"
 
 	"github.com/schollz/progressbar/v3"
 )
 
 // ArchiveDownloader 压缩包下载器
 type ArchiveDownloader struct {
-	client *http.Client
+	client      *http.Client
+	logger      *slog.Logger
+	trustedKeys map[string]config.TrustedKey
 }
 
 // Download 下载并解压压缩包
@@ -40,14 +46,63 @@ func (ad *ArchiveDownloader) Download(ctx context.Context, dep config.Dependency
 		return fmt.Errorf("archive verification failed: %w", err)
 	}
 
+	// 记录压缩包的 SHA256，首次使用时写入锁文件
+	if sum, ok := ad.getChecksums(dep.Source)["sha256"]; ok {
+		statsFromContext(ctx).SHA256 = sum
+	} else {
+		sum, err := utils.FileSHA256(archivePath)
+		if err != nil {
+			return errors.DownloadErrorWithCause(err, "failed to calculate archive checksum")
+		}
+		statsFromContext(ctx).SHA256 = sum
+	}
+
+	// 配置了签名时在解压之前校验
+	if dep.Source.Signature != "" {
+		signer, err := ad.verifySignature(ctx, dep, archivePath)
+		if err != nil {
+			return err
+		}
+		statsFromContext(ctx).Signer = signer
+		logging.OrDefault(ad.logger).Debug("Verified archive signature", "dependency", dep.Name, "key", signer.Key, "fingerprint", signer.Fingerprint)
+	}
+
 	// 解压压缩包
-	if err := ad.extractArchive(archivePath, targetDir); err != nil {
+	start := time.Now()
+	err = ad.extractArchive(archivePath, targetDir)
+	statsFromContext(ctx).ExtractTime += time.Since(start)
+	if err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// archiveCacheFile 获取压缩包在用户缓存目录中的路径 ~/.cache/.buildfly/archives/{version}/{文件名}
+func archiveCacheFile(version, url string) (string, error) {
+	userCacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", errors.DownloadErrorWithCause(err, "failed to get user cache dir")
+	}
+	return filepath.Join(userCacheDir, ".buildfly", "archives", version, filepath.Base(url)), nil
+}
+
+// RemoveCachedArchive 删除依赖已缓存的压缩包，下次下载时重新从远程获取
+func RemoveCachedArchive(dep config.Dependency) error {
+	url, err := dep.Source.GetFirstAvailableURL()
+	if err != nil {
+		return errors.DownloadErrorWithCause(err, "failed to get available URL")
+	}
+	cacheFile, err := archiveCacheFile(dep.Version, url)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(cacheFile); err != nil && !os.IsNotExist(err) {
+		return errors.DownloadErrorWithCause(err, fmt.Sprintf("failed to remove cached archive %s", cacheFile))
+	}
+	return nil
+}
+
 // downloadArchive 下载压缩包
 func (ad *ArchiveDownloader) downloadArchive(ctx context.Context, dep config.Dependency, callback ProgressCallback) (string, error) {
 	// 获取第一个可用的 URL
@@ -56,31 +111,21 @@ func (ad *ArchiveDownloader) downloadArchive(ctx context.Context, dep config.Dep
 		return "", errors.DownloadErrorWithCause(err, "failed to get available URL")
 	}
 
-	// 从 URL 中提取完整的文件扩展名
-	ext := ad.getFullExtension(url)
-	if ext == "" {
-		ext = ".tar.gz" // 默认扩展名
-	}
-
-	// ~/.cache/buildfly/archives/
-	userCacheDir, err := os.UserCacheDir()
+	cacheFile, err := archiveCacheFile(dep.Version, url)
 	if err != nil {
-		return "", errors.DownloadErrorWithCause(err, "failed to get user cache dir")
+		return "", err
 	}
-	cacheDir := filepath.Join(userCacheDir, ".buildfly", "archives", dep.Version)
-	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(cacheFile), 0755); err != nil {
 		return "", errors.DownloadErrorWithCause(err, "failed to create cache dir")
 	}
-	fileBaseName := filepath.Base(url)
-	cacheFile := filepath.Join(cacheDir, fileBaseName)
 
 	// 检查缓存文件
 	if _, err := os.Stat(cacheFile); err == nil {
 		if err := ad.Verify(dep, cacheFile); err != nil {
-			fmt.Printf("Cached archive verification failed, re-downloading: %s\n", cacheFile)
+			logging.OrDefault(ad.logger).Warn("Cached archive verification failed, re-downloading", "path", cacheFile, "error", err)
 		} else {
 			// 文件存在且验证通过，直接返回缓存文件路径
-			fmt.Printf("Using cached archive: %s\n", cacheFile)
+			logging.OrDefault(ad.logger).Debug("Using cached archive", "path", cacheFile)
 			return cacheFile, nil
 		}
 	}
@@ -90,27 +135,27 @@ func (ad *ArchiveDownloader) downloadArchive(ctx context.Context, dep config.Dep
 	var lastErr error
 
 	for i, downloadURL := range urls {
-		fmt.Printf("Attempting to download from URL %d/%d: %s\n", i+1, len(urls), downloadURL)
+		logging.OrDefault(ad.logger).Debug("Attempting to download", "url", downloadURL, "attempt", fmt.Sprintf("%d/%d", i+1, len(urls)))
 
 		// 对于本地文件，直接复制到缓存位置
 		if dep.Source.IsLocalURL(downloadURL) {
 			if err := ad.copyLocalFile(downloadURL, cacheFile); err != nil {
 				lastErr = err
-				fmt.Printf("Failed to copy local file %s: %v\n", downloadURL, err)
+				logging.OrDefault(ad.logger).Warn("Failed to copy local file", "path", downloadURL, "error", err)
 				continue
 			}
-			fmt.Printf("Successfully copied local file: %s\n", downloadURL)
+			logging.OrDefault(ad.logger).Debug("Copied local file", "path", downloadURL)
 			return cacheFile, nil
 		}
 
 		// 对于网络文件，使用 HTTP 下载
 		if err := ad.downloadFromHTTP(ctx, downloadURL, cacheFile, dep.Name, callback); err != nil {
 			lastErr = err
-			fmt.Printf("Failed to download from %s: %v\n", downloadURL, err)
+			logging.OrDefault(ad.logger).Warn("Failed to download", "url", downloadURL, "error", err)
 			continue
 		}
 
-		fmt.Printf("Successfully downloaded from: %s\n", downloadURL)
+		logging.OrDefault(ad.logger).Debug("Downloaded", "url", downloadURL)
 		return cacheFile, nil
 	}
 
@@ -210,6 +255,7 @@ func (ad *ArchiveDownloader) downloadFromHTTP(ctx context.Context, url, cacheFil
 
 	// 下载文件
 	written, err := io.Copy(writer, resp.Body)
+	statsFromContext(ctx).Bytes += written
 	if err != nil {
 		os.Remove(tempFile) // 清理失败的下载
 		return errors.DownloadErrorWithCause(err, "failed to write archive file")
@@ -281,7 +327,7 @@ func (ad *ArchiveDownloader) extractTarGz(archivePath, targetDir string) error {
 	fileCmd := execCommand("file", archivePath)
 	output, err := fileCmd.CombinedOutput()
 	if err == nil {
-		fmt.Printf("Archive file type: %s\n", string(output))
+		logging.OrDefault(ad.logger).Debug("Archive file type", "type", strings.TrimSpace(string(output)))
 	}
 
 	// 确保目标目录存在
@@ -291,7 +337,7 @@ func (ad *ArchiveDownloader) extractTarGz(archivePath, targetDir string) error {
 
 	// 使用 --preserve-permissions 参数保留文件权限
 	cmd := execCommand("tar", "xzf", archivePath, "-C", targetDir, "--strip-components=1", "--preserve-permissions")
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = logging.Console()
 	cmd.Stderr = os.Stderr
 	if err := cmd.Run(); err != nil {
 		return errors.DownloadErrorWithCause(err, "failed to extract tar.gz archive")
@@ -303,7 +349,7 @@ func (ad *ArchiveDownloader) extractTarGz(archivePath, targetDir string) error {
 func (ad *ArchiveDownloader) extractTar(archivePath, targetDir string) error {
 	// 使用 --preserve-permissions 参数保留文件权限
 	cmd := execCommand("tar", "xf", archivePath, "-C", targetDir, "--strip-components=1", "--preserve-permissions")
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = logging.Console()
 	cmd.Stderr = os.Stderr
 	if err := cmd.Run(); err != nil {
 		return errors.DownloadErrorWithCause(err, "failed to extract tar archive")
@@ -315,7 +361,7 @@ func (ad *ArchiveDownloader) extractTar(archivePath, targetDir string) error {
 func (ad *ArchiveDownloader) extractZip(archivePath, targetDir string) error {
 	// 使用 -o 参数保留文件权限，-q 静默模式
 	cmd := execCommand("unzip", "-q", "-o", archivePath, "-d", targetDir)
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = logging.Console()
 	cmd.Stderr = os.Stderr
 	if err := cmd.Run(); err != nil {
 		return errors.DownloadErrorWithCause(err, "failed to extract zip archive")
@@ -327,7 +373,7 @@ func (ad *ArchiveDownloader) extractZip(archivePath, targetDir string) error {
 func (ad *ArchiveDownloader) extractTarBz2(archivePath, targetDir string) error {
 	// 使用 --preserve-permissions 参数保留文件权限
 	cmd := execCommand("tar", "xjf", archivePath, "-C", targetDir, "--strip-components=1", "--preserve-permissions")
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = logging.Console()
 	cmd.Stderr = os.Stderr
 	if err := cmd.Run(); err != nil {
 		return errors.DownloadErrorWithCause(err, "failed to extract tar.bz2 archive")
@@ -339,7 +385,7 @@ func (ad *ArchiveDownloader) extractTarBz2(archivePath, targetDir string) error
 func (ad *ArchiveDownloader) extractTarXz(archivePath, targetDir string) error {
 	// 使用 --preserve-permissions 参数保留文件权限
 	cmd := execCommand("tar", "xJf", archivePath, "-C", targetDir, "--strip-components=1", "--preserve-permissions")
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = logging.Console()
 	cmd.Stderr = os.Stderr
 	if err := cmd.Run(); err != nil {
 		return errors.DownloadErrorWithCause(err, "failed to extract tar.xz archive")
@@ -358,16 +404,16 @@ func (ad *ArchiveDownloader) Verify(dep config.Dependency, archivePath string) e
 	checksums := ad.getChecksums(dep.Source)
 
 	if len(checksums) == 0 {
-		fmt.Printf("  No checksum specified for verification\n")
+		logging.OrDefault(ad.logger).Debug("No checksum specified for verification", "dependency", dep.Name)
 		return nil
 	}
 
 	// 验证每个指定的校验和
 	for algorithm, expectedHash := range checksums {
 		if err := ad.verifyChecksumWithAlgorithm(archivePath, expectedHash, algorithm); err != nil {
-			return fmt.Errorf("checksum verification failed (%s): %w", algorithm, err)
+			return checksumError(dep, fmt.Errorf("checksum verification failed (%s): %w", algorithm, err))
 		}
-		fmt.Printf("  ✓ Verified with %s checksum\n", algorithm)
+		logging.OrDefault(ad.logger).Info("✓ Verified checksum", "algorithm", algorithm)
 	}
 
 	return nil
@@ -438,12 +484,21 @@ func (ad *ArchiveDownloader) verifyChecksumWithAlgorithm(filePath, expectedHash,
 
 	actualHash := fmt.Sprintf("%x", hasher.Sum(nil))
 	if actualHash != expectedHash {
-		return errors.DownloadError(fmt.Sprintf("checksum mismatch: expected %s, got %s", expectedHash, actualHash))
+		return errors.ChecksumMismatchError(fmt.Sprintf("checksum mismatch: expected %s, got %s", expectedHash, actualHash))
 	}
 
 	return nil
 }
 
+// checksumError 为校验和不匹配的错误添加指向具体依赖的修复建议，其他错误原样返回
+func checksumError(dep config.Dependency, err error) error {
+	if errors.Category(err) != "CHECKSUM_MISMATCH" {
+		return err
+	}
+	return errors.ChecksumMismatchErrorWithCause(err, fmt.Sprintf("checksum verification failed for %s", dep.Name)).
+		WithHint(fmt.Sprintf("upstream may have re-rolled the tarball; verify the new archive, then run `buildfly lock --refresh %s`", dep.Name))
+}
+
 // verifyChecksum 验证文件校验和（保持向后兼容）
 func (ad *ArchiveDownloader) verifyChecksum(filePath, expectedHash string) error {
 	return ad.verifyChecksumWithAlgorithm(filePath, expectedHash, "sha256")
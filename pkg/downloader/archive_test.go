@@ -1,13 +1,16 @@
 package downloader
 
 import (
+	"context"
 	"crypto/md5"
 	"crypto/sha256"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"buildfly/internal/errors"
 	"buildfly/pkg/config"
 )
 
@@ -223,3 +226,63 @@ func TestArchiveDownloader_VerifyChecksumWithAlgorithm(t *testing.T) {
 		t.Error("Expected verification to fail with unsupported algorithm, but it passed")
 	}
 }
+
+func TestArchiveDownloader_DownloadReportsSHA256(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	// 创建本地压缩包
+	tmpDir := t.TempDir()
+	srcDir := filepath.Join(tmpDir, "zlib-1.3.1")
+	if err := os.MkdirAll(srcDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "zlib.h"), []byte("#define ZLIB_VERSION \"1.3.1\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	archive := filepath.Join(tmpDir, "zlib-1.3.1.tar.gz")
+	if output, err := execCommand("tar", "-czf", archive, "-C", tmpDir, "zlib-1.3.1").CombinedOutput(); err != nil {
+		t.Fatalf("tar: %v\n%s", err, output)
+	}
+	content, err := os.ReadFile(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := fmt.Sprintf("%x", sha256.Sum256(content))
+
+	dm := NewDownloadManager(1)
+	dep := config.Dependency{Name: "zlib", Version: "1.3.1", Source: config.SourceInfo{Type: "archive", URLS: []string{archive}}}
+
+	stats, err := dm.DownloadWithStats(context.Background(), dep, filepath.Join(t.TempDir(), "src"))
+	if err != nil {
+		t.Fatalf("DownloadWithStats() error = %v", err)
+	}
+	if stats.SHA256 != want {
+		t.Errorf("SHA256 = %s, want %s", stats.SHA256, want)
+	}
+
+	// 锁定的校验和不匹配时，缓存的压缩包和重新获取的压缩包都校验失败
+	dep.Source.SHA256 = strings.Repeat("0", 64)
+	_, err = dm.DownloadWithStats(context.Background(), dep, filepath.Join(t.TempDir(), "src"))
+	if err == nil {
+		t.Fatal("expected checksum mismatch")
+	}
+	mismatch := false
+	for _, entry := range errors.Chain(err) {
+		mismatch = mismatch || entry.Code == "CHECKSUM_MISMATCH"
+	}
+	if !mismatch {
+		t.Errorf("expected CHECKSUM_MISMATCH in error chain, got %v", err)
+	}
+	if hint := errors.Hint(err); !strings.Contains(hint, "buildfly lock --refresh zlib") {
+		t.Errorf("hint = %q, want lock --refresh suggestion", hint)
+	}
+
+	// 删除缓存的压缩包后重新从源地址获取
+	if err := RemoveCachedArchive(dep); err != nil {
+		t.Fatal(err)
+	}
+	cacheFile, _ := archiveCacheFile(dep.Version, archive)
+	if _, err := os.Stat(cacheFile); !os.IsNotExist(err) {
+		t.Errorf("cached archive %s not removed", cacheFile)
+	}
+}
@@ -0,0 +1,176 @@
+package downloader
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"buildfly/internal/errors"
+	"buildfly/internal/logging"
+	"buildfly/pkg/config"
+)
+
+// CredentialStore 按主机解析并缓存下载凭据
+// 凭据在第一次访问主机时才解析，凭据命令每个主机只执行一次；解析出的密钥登记到日志脱敏
+type CredentialStore struct {
+	hosts    map[string]config.HostCredential
+	mu       sync.Mutex
+	resolved map[string]*config.Credential // 按主机缓存，nil 表示该主机没有配置凭据
+}
+
+// NewCredentialStore 创建凭据存储，hosts 为配置中按主机的凭据
+func NewCredentialStore(hosts map[string]config.HostCredential) *CredentialStore {
+	return &CredentialStore{hosts: hosts, resolved: make(map[string]*config.Credential)}
+}
+
+// Lookup 获取访问 host（可以带端口）时使用的凭据，没有配置时返回 nil
+func (s *CredentialStore) Lookup(ctx context.Context, host string) (*config.Credential, error) {
+	if s == nil || len(s.hosts) == 0 {
+		return nil, nil
+	}
+	host = strings.ToLower(host)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if credential, ok := s.resolved[host]; ok {
+		return credential, nil
+	}
+
+	pattern, hostCredential := config.MatchCredential(s.hosts, host)
+	if hostCredential == nil {
+		s.resolved[host] = nil
+		return nil, nil
+	}
+	credential, err := hostCredential.Resolve(ctx, host)
+	if err != nil {
+		return nil, errors.DownloadErrorWithCause(err, fmt.Sprintf("failed to resolve credentials for %s", host)).
+			WithHint(fmt.Sprintf("check credentials.%s in the buildfly config", pattern))
+	}
+	for _, secret := range credential.Secrets() {
+		logging.RegisterSecret(secret)
+	}
+	if credential.Token == "" {
+		logging.RegisterSecret(basicAuth(credential.Username, credential.Password))
+	}
+	credential.AllowHTTP = hostCredential.AllowHTTP
+	s.resolved[host] = credential
+	return credential, nil
+}
+
+// lookupURL 获取访问 u 时使用的凭据
+// 凭据默认只通过 https 发送，明文 http 需要在配置中设置 allow_http
+func (s *CredentialStore) lookupURL(ctx context.Context, u *url.URL) (*config.Credential, error) {
+	credential, err := s.Lookup(ctx, u.Host)
+	if err != nil || credential == nil {
+		return nil, err
+	}
+	if u.Scheme != "https" && !credential.AllowHTTP {
+		return nil, nil
+	}
+	return credential, nil
+}
+
+// basicAuth 编码 Basic 认证的用户名和密码
+func basicAuth(username, password string) string {
+	return base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+}
+
+// authorizationHeader 获取凭据对应的 Authorization 请求头
+func authorizationHeader(credential *config.Credential) string {
+	if credential.Token != "" {
+		return "Bearer " + credential.Token
+	}
+	return "Basic " + basicAuth(credential.Username, credential.Password)
+}
+
+// authTransport 为配置了凭据的主机添加 Authorization 请求头
+// 重定向的每一跳都按目标主机单独查找凭据，凭据不会被转发到对象存储等其他主机，
+// 也不会在重定向到明文 http 时发送
+type authTransport struct {
+	base  http.RoundTripper
+	store *CredentialStore
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// 请求已经带有认证信息时不覆盖
+	if req.Header.Get("Authorization") != "" || req.URL.User != nil {
+		return t.base.RoundTrip(req)
+	}
+	credential, err := t.store.lookupURL(req.Context(), req.URL)
+	if err != nil {
+		return nil, err
+	}
+	if credential == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", authorizationHeader(credential))
+	return t.base.RoundTrip(req)
+}
+
+// gitCredentialsKey 上下文中保存 git 命令凭据环境变量的键
+type gitCredentialsKey struct{}
+
+// withGitCredentials 返回携带访问 repoURL 所需凭据的上下文，没有配置凭据时返回原上下文
+// 只处理 http(s) 仓库，ssh 仓库使用 ssh 自己的认证；http 仓库需要配置 allow_http
+func withGitCredentials(ctx context.Context, store *CredentialStore, repoURL string) (context.Context, error) {
+	parsed, err := url.Parse(repoURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.User != nil {
+		return ctx, nil
+	}
+	credential, err := store.lookupURL(ctx, parsed)
+	if err != nil || credential == nil {
+		return ctx, err
+	}
+	return context.WithValue(ctx, gitCredentialsKey{}, gitCredentialEnv(os.Environ(), parsed.Scheme+"://"+parsed.Host, credential)), nil
+}
+
+// gitEnvFromContext 获取上下文中 git 命令的凭据环境变量
+func gitEnvFromContext(ctx context.Context) []string {
+	env, _ := ctx.Value(gitCredentialsKey{}).([]string)
+	return env
+}
+
+// gitCredentialHelper 从环境变量输出凭据的 git 凭据助手，密钥不出现在命令行参数中
+const gitCredentialHelper = `!f() { test "$1" = get && echo "username=${BUILDFLY_GIT_USERNAME}" && echo "password=${BUILDFLY_GIT_PASSWORD}"; }; f`
+
+// gitCredentialEnv 生成让 git 访问 baseURL 时使用凭据的环境变量
+// 通过 GIT_CONFIG_COUNT 添加只作用于该主机的配置，在 env 已有的 GIT_CONFIG_* 之后追加：
+// 用户名密码使用凭据助手（先清空其他助手，避免询问或写入用户的凭据存储），令牌使用 http.extraHeader
+func gitCredentialEnv(env []string, baseURL string, credential *config.Credential) []string {
+	var pairs [][2]string
+	var extra []string
+	if credential.Token != "" {
+		pairs = append(pairs, [2]string{"http." + baseURL + "/.extraHeader", "Authorization: " + authorizationHeader(credential)})
+	} else {
+		pairs = append(pairs,
+			[2]string{"credential." + baseURL + ".helper", ""},
+			[2]string{"credential." + baseURL + ".helper", gitCredentialHelper},
+		)
+		extra = append(extra, "BUILDFLY_GIT_USERNAME="+credential.Username, "BUILDFLY_GIT_PASSWORD="+credential.Password)
+	}
+
+	count := 0
+	for _, kv := range env {
+		if value, ok := strings.CutPrefix(kv, "GIT_CONFIG_COUNT="); ok {
+			count, _ = strconv.Atoi(value)
+		}
+	}
+	result := make([]string, 0, len(extra)+2*len(pairs)+1)
+	for i, pair := range pairs {
+		result = append(result,
+			fmt.Sprintf("GIT_CONFIG_KEY_%d=%s", count+i, pair[0]),
+			fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", count+i, pair[1]),
+		)
+	}
+	result = append(result, fmt.Sprintf("GIT_CONFIG_COUNT=%d", count+len(pairs)))
+	return append(result, extra...)
+}
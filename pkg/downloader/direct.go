@@ -5,12 +5,14 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"buildfly/internal/errors"
+	"buildfly/internal/logging"
 	"buildfly/pkg/config"
 	"buildfly/pkg/utils"
 )
@@ -18,6 +20,7 @@ import (
 // DirectDownloader 直接文件下载器
 type DirectDownloader struct {
 	client *http.Client
+	logger *slog.Logger
 }
 
 // Download 直接下载文件
@@ -32,7 +35,7 @@ func (dd *DirectDownloader) Download(ctx context.Context, dep config.Dependency,
 	var lastErr error
 
 	for i, url := range urls {
-		fmt.Printf("Attempting to download from URL %d/%d: %s\n", i+1, len(urls), url)
+		logging.OrDefault(dd.logger).Debug("Attempting to download", "url", url, "attempt", fmt.Sprintf("%d/%d", i+1, len(urls)))
 
 		// 从 URL 获取文件名
 		filename := getFileNameFromURL(url)
@@ -46,21 +49,21 @@ func (dd *DirectDownloader) Download(ctx context.Context, dep config.Dependency,
 		if dep.Source.IsLocalURL(url) {
 			if err := dd.copyLocalFile(url, targetPath); err != nil {
 				lastErr = err
-				fmt.Printf("Failed to copy local file %s: %v\n", url, err)
+				logging.OrDefault(dd.logger).Warn("Failed to copy local file", "path", url, "error", err)
 				continue
 			}
-			fmt.Printf("Successfully copied local file: %s\n", url)
+			logging.OrDefault(dd.logger).Debug("Copied local file", "path", url)
 			return nil
 		}
 
 		// 对于网络文件，使用 HTTP 下载
 		if err := dd.downloadFile(ctx, url, targetPath, callback); err != nil {
 			lastErr = err
-			fmt.Printf("Failed to download from %s: %v\n", url, err)
+			logging.OrDefault(dd.logger).Warn("Failed to download", "url", url, "error", err)
 			continue
 		}
 
-		fmt.Printf("Successfully downloaded from: %s\n", url)
+		logging.OrDefault(dd.logger).Debug("Downloaded", "url", url)
 		return nil
 	}
 
@@ -138,7 +141,8 @@ func (dd *DirectDownloader) downloadFile(ctx context.Context, url, targetPath st
 	}
 
 	// 复制数据
-	_, err = io.Copy(writer, resp.Body)
+	written, err := io.Copy(writer, resp.Body)
+	statsFromContext(ctx).Bytes += written
 	if err != nil {
 		return errors.DownloadErrorWithCause(err, "failed to write file")
 	}
@@ -156,7 +160,7 @@ func (dd *DirectDownloader) Verify(dep config.Dependency, filePath string) error
 	// 如果指定了哈希，验证文件完整性
 	if dep.Source.Hash != "" {
 		if err := dd.verifyChecksum(filePath, dep.Source.Hash); err != nil {
-			return err
+			return checksumError(dep, err)
 		}
 	}
 
@@ -178,7 +182,7 @@ func (dd *DirectDownloader) verifyChecksum(filePath, expectedHash string) error
 
 	actualHash := fmt.Sprintf("%x", hasher.Sum(nil))
 	if actualHash != expectedHash {
-		return errors.DownloadError(fmt.Sprintf("checksum mismatch: expected %s, got %s", expectedHash, actualHash))
+		return errors.ChecksumMismatchError(fmt.Sprintf("checksum mismatch: expected %s, got %s", expectedHash, actualHash))
 	}
 
 	return nil
@@ -270,7 +274,7 @@ func (dd *DirectDownloader) extractIfNeeded(filePath, targetDir string) error {
 	defer os.RemoveAll(tempDir)
 
 	// 使用 archive 下载器解压
-	archiveDownloader := &ArchiveDownloader{client: dd.client}
+	archiveDownloader := &ArchiveDownloader{client: dd.client, logger: dd.logger}
 	if err := archiveDownloader.extractArchive(filePath, tempDir); err != nil {
 		return err
 	}
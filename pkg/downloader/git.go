@@ -3,17 +3,23 @@ package downloader
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 
 	"buildfly/internal/errors"
+	"buildfly/internal/logging"
 	"buildfly/pkg/config"
 )
 
 // GitDownloader Git 下载器
-type GitDownloader struct{}
+type GitDownloader struct {
+	logger      *slog.Logger
+	mirrorDir   string           // 裸镜像目录，为空时不使用镜像
+	credentials *CredentialStore // 按主机的凭据，通过凭据助手提供给 git
+}
 
 // Download 从 Git 仓库下载
 func (gd *GitDownloader) Download(ctx context.Context, dep config.Dependency, targetDir string, callback ProgressCallback) error {
@@ -29,42 +35,71 @@ func (gd *GitDownloader) Download(ctx context.Context, dep config.Dependency, ta
 		}
 	}
 
+	// 需要 LFS 时先确认 git-lfs 可用，避免克隆后才失败
+	if dep.Source.LFSEnabled() {
+		if err := checkGitLFS(ctx, dep.Name); err != nil {
+			return err
+		}
+	}
+
 	// 获取所有可用的 URL 并尝试克隆
 	urls := dep.Source.GetAllAvailableURLs()
 	var lastErr error
 
 	for i, url := range urls {
-		fmt.Printf("Attempting to clone from URL %d/%d: %s\n", i+1, len(urls), url)
+		logging.OrDefault(gd.logger).Debug("Attempting to clone", "url", url, "attempt", fmt.Sprintf("%d/%d", i+1, len(urls)))
 
-		// 克隆仓库
-		cloneArgs := []string{"clone", url, targetDir}
-		if dep.Source.Tag != "" {
-			cloneArgs = append([]string{"clone", "--branch", dep.Source.Tag, "--depth", "1"}, cloneArgs[1:]...)
+		// 镜像更新、子模块和 LFS 都从原始远程获取，使用该主机的凭据
+		ctx, err := withGitCredentials(ctx, gd.credentials, url)
+		if err != nil {
+			lastErr = err
+			logging.OrDefault(gd.logger).Warn("Failed to resolve git credentials", "url", url, "error", err)
+			continue
 		}
 
-		cmd := exec.CommandContext(ctx, "git", cloneArgs...)
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
+		// 使用镜像时先增量更新镜像，再从本地镜像克隆源码树
+		// 使用镜像的本地路径而不是 file:// URL，git 克隆时以硬链接共享对象，不复制整个对象库
+		// 部分克隆（filter）直接从远程获取，避免在镜像中保存完整的历史
+		fetchURL := url
+		useMirror := gd.mirrorDir != "" && dep.Source.Filter == ""
+		var mirror string
+		if useMirror {
+			var err error
+			mirror, err = gd.updateMirror(ctx, url, dep.Source)
+			if err != nil {
+				lastErr = err
+				logging.OrDefault(gd.logger).Warn("Failed to update git mirror", "url", url, "error", err)
+				continue
+			}
+			fetchURL = mirror
+		}
 
-		if err := cmd.Run(); err != nil {
-			lastErr = errors.DownloadErrorWithCause(err, fmt.Sprintf("failed to clone repository %s", url))
-			fmt.Printf("Failed to clone from %s: %v\n", url, err)
+		if err := gd.cloneRepository(ctx, dep, fetchURL, url, targetDir); err != nil {
+			lastErr = err
+			logging.OrDefault(gd.logger).Warn("Failed to clone", "url", url, "error", err)
+			// 清理失败的克隆
+			os.RemoveAll(targetDir)
 			continue
 		}
 
-		fmt.Printf("Successfully cloned from: %s\n", url)
-
-		// 如果没有指定标签但指定了版本，尝试切换到对应的提交或标签
-		if dep.Source.Tag == "" && dep.Version != "" {
-			if err := gd.checkoutVersion(ctx, targetDir, dep.Version); err != nil {
-				lastErr = errors.DownloadErrorWithCause(err, fmt.Sprintf("failed to checkout version %s", dep.Version))
-				fmt.Printf("Failed to checkout version %s: %v\n", dep.Version, err)
-				// 清理失败的克隆
+		// LFS 对象与镜像并列缓存，部分克隆时保存在源码树中
+		if dep.Source.LFSEnabled() {
+			storage := ""
+			if useMirror {
+				storage = lfsStorageDir(mirror)
+			}
+			if err := gd.fetchLFS(ctx, targetDir, dep.Source.LFS.Include, storage); err != nil {
+				lastErr = err
+				logging.OrDefault(gd.logger).Warn("Failed to fetch Git LFS files", "url", url, "error", err)
 				os.RemoveAll(targetDir)
 				continue
 			}
 		}
 
+		logging.OrDefault(gd.logger).Debug("Cloned repository", "url", url)
+		if !useMirror {
+			statsFromContext(ctx).Bytes += dirSize(filepath.Join(targetDir, ".git"))
+		}
 		return nil
 	}
 
@@ -75,41 +110,188 @@ func (gd *GitDownloader) Download(ctx context.Context, dep config.Dependency, ta
 	return errors.DownloadError("no valid Git URLs found for cloning")
 }
 
-// checkoutVersion 切换到指定版本
-func (gd *GitDownloader) checkoutVersion(ctx context.Context, repoDir, version string) error {
-	// 先尝试获取远程标签
-	cmd := exec.CommandContext(ctx, "git", "fetch", "--tags", "--depth", "1")
-	cmd.Dir = repoDir
-	if err := cmd.Run(); err != nil {
-		// 如果获取标签失败，尝试获取所有分支
-		cmd = exec.CommandContext(ctx, "git", "fetch", "--all")
-		cmd.Dir = repoDir
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("failed to fetch remote references: %w", err)
+// cloneRepository 从 url 克隆仓库，检出固定的提交/标签/分支并初始化子模块
+// url 为本地镜像时，克隆后将 origin 改回 originURL，使子模块的相对 URL 指向原始远程仓库
+func (gd *GitDownloader) cloneRepository(ctx context.Context, dep config.Dependency, url, originURL, targetDir string) error {
+	source := dep.Source
+
+	if source.Commit != "" {
+		if err := gd.fetchCommit(ctx, url, targetDir, source); err != nil {
+			return err
+		}
+	} else {
+		// 标签或分支只克隆最新的一个提交；从本地镜像克隆时对象是硬链接，
+		// 不需要浅克隆（git 在本地克隆时也会忽略 --depth）
+		cloneArgs := []string{"clone"}
+		if ref := gitRef(source); ref != "" {
+			cloneArgs = append(cloneArgs, "--branch", ref)
+			if url == originURL {
+				cloneArgs = append(cloneArgs, "--depth", "1")
+			}
+		}
+		if source.Filter != "" {
+			cloneArgs = append(cloneArgs, "--filter="+source.Filter)
+		}
+		if len(source.SparsePaths) > 0 {
+			// 先只检出根目录的文件，再由 sparse-checkout 检出需要的目录
+			cloneArgs = append(cloneArgs, "--sparse")
+		}
+		cloneArgs = append(cloneArgs, url, targetDir)
+
+		if err := gd.runGit(ctx, "", cloneArgs...); err != nil {
+			return errors.DownloadErrorWithCause(err, fmt.Sprintf("failed to clone repository %s", url))
+		}
+		if err := gd.sparseCheckout(ctx, targetDir, source.SparsePaths); err != nil {
+			return err
+		}
+
+		// 如果没有指定标签或分支但指定了版本，切换到与版本同名的标签、分支或提交
+		if gitRef(source) == "" && dep.Version != "" {
+			if err := gd.checkoutVersion(ctx, targetDir, dep.Version); err != nil {
+				return err
+			}
 		}
 	}
 
-	// 尝试切换到标签
-	cmd = exec.CommandContext(ctx, "git", "checkout", version)
-	cmd.Dir = repoDir
-	if err := cmd.Run(); err == nil {
-		return nil
+	if url != originURL {
+		if err := gd.runGit(ctx, targetDir, "remote", "set-url", "origin", originURL); err != nil {
+			return errors.DownloadErrorWithCause(err, "failed to set remote origin")
+		}
+	}
+
+	if source.SubmodulesEnabled() {
+		if err := gd.updateSubmodules(ctx, targetDir, source.Submodules.Paths); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// gitRef 获取要克隆的标签或分支
+func gitRef(source config.SourceInfo) string {
+	if source.Tag != "" {
+		return source.Tag
 	}
+	return source.Branch
+}
 
-	// 如果标签不存在，尝试切换到分支
-	cmd = exec.CommandContext(ctx, "git", "checkout", version)
-	cmd.Dir = repoDir
-	if err := cmd.Run(); err == nil {
+// fetchCommit 只获取固定的提交（git fetch --depth 1 origin <sha>），检出后确认 HEAD 与之一致
+// 服务端不允许按哈希获取时，回退为获取标签/分支或全部历史
+func (gd *GitDownloader) fetchCommit(ctx context.Context, url, targetDir string, source config.SourceInfo) error {
+	if err := gd.runGit(ctx, "", "init", "--quiet", targetDir); err != nil {
+		return errors.DownloadErrorWithCause(err, fmt.Sprintf("failed to init repository %s", targetDir))
+	}
+	if err := gd.runGit(ctx, targetDir, "remote", "add", "origin", url); err != nil {
+		return errors.DownloadErrorWithCause(err, "failed to add remote origin")
+	}
+
+	// 部分克隆：缺少的对象在检出时按需从 origin 获取
+	var filterArgs []string
+	if source.Filter != "" {
+		filterArgs = []string{"--filter=" + source.Filter}
+		if err := gd.runGit(ctx, targetDir, "config", "remote.origin.promisor", "true"); err != nil {
+			return errors.DownloadErrorWithCause(err, "failed to configure partial clone")
+		}
+		if err := gd.runGit(ctx, targetDir, "config", "remote.origin.partialclonefilter", source.Filter); err != nil {
+			return errors.DownloadErrorWithCause(err, "failed to configure partial clone")
+		}
+	}
+
+	// 检出前设置稀疏检出，只获取并写出需要的目录
+	if err := gd.sparseCheckout(ctx, targetDir, source.SparsePaths); err != nil {
+		return err
+	}
+
+	if err := gd.runGit(ctx, targetDir, append([]string{"fetch", "--depth", "1"}, append(filterArgs, "origin", source.Commit)...)...); err != nil {
+		logging.OrDefault(gd.logger).Warn("Server does not allow fetching commits by hash, fetching full history", "url", url, "commit", source.Commit, "error", err)
+
+		fetchArgs := append(append([]string{"fetch", "--tags"}, filterArgs...), "origin")
+		if source.Branch != "" {
+			fetchArgs = append(fetchArgs, fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", source.Branch, source.Branch))
+		}
+		if err := gd.runGit(ctx, targetDir, fetchArgs...); err != nil {
+			return errors.DownloadErrorWithCause(err, fmt.Sprintf("failed to fetch commit %s from %s", source.Commit, url))
+		}
+	}
+
+	if err := gd.runGit(ctx, targetDir, "checkout", "--quiet", "--detach", source.Commit); err != nil {
+		return errors.DownloadErrorWithCause(err, fmt.Sprintf("failed to checkout commit %s", source.Commit))
+	}
+
+	head, err := gd.GetCommitHash(targetDir)
+	if err != nil {
+		return err
+	}
+	if head != source.Commit {
+		return errors.DownloadError(fmt.Sprintf("expected HEAD at commit %s, got %s", source.Commit, head))
+	}
+	return nil
+}
+
+// sparseCheckout 只检出指定的目录（cone 模式），paths 为空时不做任何事
+func (gd *GitDownloader) sparseCheckout(ctx context.Context, repoDir string, paths []string) error {
+	if len(paths) == 0 {
 		return nil
 	}
+	if err := gd.runGit(ctx, repoDir, append([]string{"sparse-checkout", "set", "--"}, paths...)...); err != nil {
+		return errors.DownloadErrorWithCause(err, fmt.Sprintf("failed to set sparse checkout paths %v", paths))
+	}
+	return nil
+}
 
-	// 如果分支也不存在，尝试切换到提交
-	cmd = exec.CommandContext(ctx, "git", "checkout", version)
-	cmd.Dir = repoDir
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to checkout version/tag/branch %s: %w", version, err)
+// updateSubmodules 递归初始化子模块，paths 为空时初始化所有子模块
+func (gd *GitDownloader) updateSubmodules(ctx context.Context, repoDir string, paths []string) error {
+	var pathArgs []string
+	if len(paths) > 0 {
+		pathArgs = append([]string{"--"}, paths...)
 	}
 
+	shallowArgs := append([]string{"submodule", "update", "--init", "--recursive", "--depth", "1"}, pathArgs...)
+	if err := gd.runGit(ctx, repoDir, shallowArgs...); err != nil {
+		// 子模块固定的提交不在分支顶端时无法浅克隆，改为获取完整历史
+		logging.OrDefault(gd.logger).Debug("Shallow submodule update failed, retrying with full history", "error", err)
+		fullArgs := append([]string{"submodule", "update", "--init", "--recursive"}, pathArgs...)
+		if err := gd.runGit(ctx, repoDir, fullArgs...); err != nil {
+			return errors.DownloadErrorWithCause(err, "failed to update submodules")
+		}
+	}
+	return nil
+}
+
+// runGit 在 dir 中执行 git 命令，输出直接显示给用户
+// 克隆和检出时总是跳过 LFS 的 smudge 过滤器，LFS 文件只在 lfs 启用时由 fetchLFS 获取；
+// 上下文中带有凭据时通过环境变量传给 git
+func (gd *GitDownloader) runGit(ctx context.Context, dir string, args ...string) error {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(append(os.Environ(), "GIT_LFS_SKIP_SMUDGE=1"), gitEnvFromContext(ctx)...)
+	cmd.Stdout = logging.Console()
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// checkoutVersion 检出与版本同名的标签、分支或提交
+// 克隆时没有指定 --branch，仓库中已有全部标签和远程分支，按此顺序解析出唯一的引用后只检出一次；
+// 都不存在时返回下载错误
+func (gd *GitDownloader) checkoutVersion(ctx context.Context, repoDir, version string) error {
+	ref := ""
+	for _, candidate := range []string{"refs/tags/" + version, "refs/remotes/origin/" + version, version} {
+		cmd := exec.CommandContext(ctx, "git", "rev-parse", "--verify", "--quiet", candidate+"^{commit}")
+		cmd.Dir = repoDir
+		if err := cmd.Run(); err == nil {
+			ref = candidate
+			break
+		}
+	}
+	if ref == "" {
+		return errors.DownloadError(fmt.Sprintf("version %s not found as a tag, branch or commit", version)).
+			WithHint("set source.tag, source.branch or source.commit to an existing ref")
+	}
+
+	if err := gd.runGit(ctx, repoDir, "checkout", "--quiet", "--detach", ref); err != nil {
+		return errors.DownloadErrorWithCause(err, fmt.Sprintf("failed to checkout version %s (%s)", version, ref))
+	}
 	return nil
 }
 
@@ -146,6 +328,11 @@ func (gd *GitDownloader) Verify(dep config.Dependency, repoPath string) error {
 		}
 	}
 
+	// 如果固定了提交，验证 HEAD 是否一致
+	if dep.Source.Commit != "" && currentCommit != dep.Source.Commit {
+		return errors.DownloadError(fmt.Sprintf("expected commit %s, got %s", dep.Source.Commit, currentCommit))
+	}
+
 	// 如果指定了哈希，验证提交是否匹配
 	if dep.Source.Hash != "" {
 		if currentCommit != dep.Source.Hash {
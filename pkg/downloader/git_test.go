@@ -0,0 +1,397 @@
+package downloader
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"buildfly/internal/errors"
+	"buildfly/pkg/config"
+)
+
+// gitInDir 在 dir 中执行 git 命令并返回去掉首尾空白的输出
+func gitInDir(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// createTestRepo 创建带一个提交的本地仓库
+func createTestRepo(t *testing.T, dir, file string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	gitInDir(t, dir, "init", "--quiet", "--initial-branch", "main")
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(file), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitInDir(t, dir, "add", ".")
+	gitInDir(t, dir, "commit", "--quiet", "-m", "add "+file)
+}
+
+// setupGitTest 检查 git 可用并设置测试用的提交者，允许本地仓库之间的子模块使用 file 协议，
+// 并允许本地仓库提供部分克隆
+func setupGitTest(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	t.Setenv("GIT_CONFIG_COUNT", "4")
+	t.Setenv("GIT_CONFIG_KEY_0", "protocol.file.allow")
+	t.Setenv("GIT_CONFIG_VALUE_0", "always")
+	t.Setenv("GIT_CONFIG_KEY_1", "user.name")
+	t.Setenv("GIT_CONFIG_VALUE_1", "buildfly")
+	t.Setenv("GIT_CONFIG_KEY_2", "user.email")
+	t.Setenv("GIT_CONFIG_VALUE_2", "buildfly@example.com")
+	t.Setenv("GIT_CONFIG_KEY_3", "uploadpack.allowFilter")
+	t.Setenv("GIT_CONFIG_VALUE_3", "true")
+}
+
+func TestGitDownloader_CommitAndSubmodules(t *testing.T) {
+	setupGitTest(t)
+
+	root := t.TempDir()
+	libDir := filepath.Join(root, "lib")
+	repoDir := filepath.Join(root, "repo")
+	createTestRepo(t, libDir, "lib.h")
+	createTestRepo(t, repoDir, "first.txt")
+
+	gitInDir(t, repoDir, "submodule", "--quiet", "add", libDir, "third_party/lib")
+	gitInDir(t, repoDir, "commit", "--quiet", "-m", "add submodule")
+	pinned := gitInDir(t, repoDir, "rev-parse", "HEAD")
+
+	// 固定的提交之后还有新的提交
+	if err := os.WriteFile(filepath.Join(repoDir, "second.txt"), []byte("second"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitInDir(t, repoDir, "add", ".")
+	gitInDir(t, repoDir, "commit", "--quiet", "-m", "second")
+
+	dep := config.Dependency{
+		Name:    "repo",
+		Version: "1.0",
+		Source: config.SourceInfo{
+			Type:       "git",
+			URLS:       []string{"file://" + repoDir},
+			Commit:     pinned,
+			Branch:     "main",
+			Submodules: &config.Submodules{Enabled: true},
+		},
+	}
+
+	targetDir := filepath.Join(root, "checkout")
+	downloader := &GitDownloader{}
+	if err := downloader.Download(context.Background(), dep, targetDir, nil); err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+
+	if head := gitInDir(t, targetDir, "rev-parse", "HEAD"); head != pinned {
+		t.Errorf("HEAD = %s, want %s", head, pinned)
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "second.txt")); !os.IsNotExist(err) {
+		t.Error("Expected checkout of the pinned commit, found a later file")
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "third_party", "lib", "lib.h")); err != nil {
+		t.Errorf("Expected submodule to be initialized: %v", err)
+	}
+	if err := downloader.Verify(dep, targetDir); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+
+	// 不存在的提交
+	dep.Source.Commit = strings.Repeat("0", 40)
+	if err := downloader.Download(context.Background(), dep, filepath.Join(root, "missing"), nil); err == nil {
+		t.Error("Expected error for unknown commit")
+	}
+}
+
+func TestGitDownloader_SparseCheckout(t *testing.T) {
+	setupGitTest(t)
+
+	root := t.TempDir()
+	repoDir := filepath.Join(root, "repo")
+	createTestRepo(t, repoDir, "README.md")
+	for _, file := range []string{"cpp/lib.h", "java/Lib.java"} {
+		path := filepath.Join(repoDir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(file), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gitInDir(t, repoDir, "add", ".")
+	gitInDir(t, repoDir, "commit", "--quiet", "-m", "add sources")
+	pinned := gitInDir(t, repoDir, "rev-parse", "HEAD")
+
+	tests := []struct {
+		name   string
+		source config.SourceInfo
+	}{
+		{"default branch", config.SourceInfo{Type: "git", SparsePaths: []string{"cpp"}, Filter: "blob:none"}},
+		{"commit", config.SourceInfo{Type: "git", Commit: pinned, SparsePaths: []string{"cpp"}, Filter: "blob:none"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := tt.source
+			source.URLS = []string{"file://" + repoDir}
+			dep := config.Dependency{Name: "repo", Source: source}
+
+			targetDir := filepath.Join(t.TempDir(), "checkout")
+			if err := (&GitDownloader{}).Download(context.Background(), dep, targetDir, nil); err != nil {
+				t.Fatalf("Download() error = %v", err)
+			}
+
+			if _, err := os.Stat(filepath.Join(targetDir, "cpp", "lib.h")); err != nil {
+				t.Errorf("Expected sparse path to be checked out: %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(targetDir, "java")); !os.IsNotExist(err) {
+				t.Error("Expected paths outside sparse_paths to be skipped")
+			}
+			if filter := gitInDir(t, targetDir, "config", "--get", "remote.origin.partialclonefilter"); filter != "blob:none" {
+				t.Errorf("partialclonefilter = %q, want blob:none", filter)
+			}
+		})
+	}
+}
+
+func TestGitDownloader_CheckoutVersion(t *testing.T) {
+	setupGitTest(t)
+
+	root := t.TempDir()
+	repoDir := filepath.Join(root, "repo")
+	createTestRepo(t, repoDir, "first.txt")
+	first := gitInDir(t, repoDir, "rev-parse", "HEAD")
+	gitInDir(t, repoDir, "tag", "v1.0")
+	gitInDir(t, repoDir, "branch", "release-1.x")
+
+	if err := os.WriteFile(filepath.Join(repoDir, "second.txt"), []byte("second"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitInDir(t, repoDir, "add", ".")
+	gitInDir(t, repoDir, "commit", "--quiet", "-m", "second")
+
+	tests := []struct {
+		name    string
+		version string
+		want    string
+	}{
+		{"tag", "v1.0", first},
+		{"branch", "release-1.x", first},
+		{"commit", first, first},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dep := config.Dependency{Name: "repo", Version: tt.version, Source: config.SourceInfo{
+				Type: "git",
+				URLS: []string{"file://" + repoDir},
+			}}
+
+			targetDir := filepath.Join(t.TempDir(), "checkout")
+			if err := (&GitDownloader{}).Download(context.Background(), dep, targetDir, nil); err != nil {
+				t.Fatalf("Download() error = %v", err)
+			}
+			if head := gitInDir(t, targetDir, "rev-parse", "HEAD"); head != tt.want {
+				t.Errorf("HEAD = %s, want %s", head, tt.want)
+			}
+		})
+	}
+
+	// 不存在的版本返回下载错误，不会停留在默认分支上
+	dep := config.Dependency{Name: "repo", Version: "v9.9", Source: config.SourceInfo{
+		Type: "git",
+		URLS: []string{"file://" + repoDir},
+	}}
+	err := (&GitDownloader{}).Download(context.Background(), dep, filepath.Join(root, "missing"), nil)
+	if err == nil {
+		t.Fatal("Expected error for unknown version")
+	}
+	if code := errors.ExitCode(err); code != errors.ExitDownload {
+		t.Errorf("ExitCode() = %d, want %d", code, errors.ExitDownload)
+	}
+	if !strings.Contains(err.Error(), "v9.9") || !strings.Contains(errors.Hint(err), "source.tag") {
+		t.Errorf("Expected error naming the version with a hint, got %v (hint %q)", err, errors.Hint(err))
+	}
+}
+
+func TestGitDownloader_Mirror(t *testing.T) {
+	setupGitTest(t)
+
+	root := t.TempDir()
+	repoDir := filepath.Join(root, "repo")
+	createTestRepo(t, repoDir, "v1.txt")
+	gitInDir(t, repoDir, "tag", "v1")
+
+	url := "file://" + repoDir
+	mirrorDir := filepath.Join(root, "cache", "git")
+	downloader := &GitDownloader{mirrorDir: mirrorDir}
+	dep := config.Dependency{
+		Name:    "repo",
+		Version: "1",
+		Source:  config.SourceInfo{Type: "git", URLS: []string{url}, Tag: "v1"},
+	}
+
+	stats := &DownloadStats{}
+	if err := downloader.Download(withDownloadStats(context.Background(), stats), dep, filepath.Join(root, "v1"), nil); err != nil {
+		t.Fatalf("Download(v1) error = %v", err)
+	}
+	if stats.Bytes <= 0 {
+		t.Errorf("Expected mirror creation to be counted as transferred bytes, got %d", stats.Bytes)
+	}
+
+	// 源码树的 origin 指向原始远程仓库
+	if origin := gitInDir(t, filepath.Join(root, "v1"), "config", "--get", "remote.origin.url"); origin != url {
+		t.Errorf("origin = %s, want %s", origin, url)
+	}
+
+	// 源码树与镜像以硬链接共享对象，没有复制对象库
+	mirrorPacks, _ := filepath.Glob(filepath.Join(mirrorPath(mirrorDir, url), "objects", "pack", "*.pack"))
+	if len(mirrorPacks) == 0 {
+		t.Fatal("Expected mirror to contain a pack")
+	}
+	mirrorPack, err := os.Stat(mirrorPacks[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	clonePack, err := os.Stat(filepath.Join(root, "v1", ".git", "objects", "pack", filepath.Base(mirrorPacks[0])))
+	if err != nil || !os.SameFile(mirrorPack, clonePack) {
+		t.Errorf("Expected pack of source tree to be hard-linked from the mirror (err=%v)", err)
+	}
+
+	// 新标签从已有镜像增量获取
+	if err := os.WriteFile(filepath.Join(repoDir, "v2.txt"), []byte("v2"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitInDir(t, repoDir, "add", ".")
+	gitInDir(t, repoDir, "commit", "--quiet", "-m", "v2")
+	gitInDir(t, repoDir, "tag", "v2")
+
+	dep.Version, dep.Source.Tag = "2", "v2"
+	if err := downloader.Download(context.Background(), dep, filepath.Join(root, "v2"), nil); err != nil {
+		t.Fatalf("Download(v2) error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "v2", "v2.txt")); err != nil {
+		t.Errorf("Expected v2 checkout: %v", err)
+	}
+
+	// LFS 对象与镜像并列存放，计入镜像大小
+	lfsDir := lfsStorageDir(mirrorPath(mirrorDir, url))
+	if err := os.MkdirAll(filepath.Join(lfsDir, "objects"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(lfsDir, "objects", "blob"), []byte("lfs object"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mirrors, err := ListGitMirrors(mirrorDir)
+	if err != nil {
+		t.Fatalf("ListGitMirrors() error = %v", err)
+	}
+	if len(mirrors) != 1 || mirrors[0].URL != url || mirrors[0].LFSSize != 10 || mirrors[0].Size <= mirrors[0].LFSSize {
+		t.Fatalf("unexpected mirrors: %+v", mirrors)
+	}
+
+	// 最近使用过的镜像保留，超过期限的镜像删除
+	if removed, err := PruneGitMirrors(context.Background(), mirrorDir, time.Hour); err != nil || len(removed) != 0 {
+		t.Errorf("PruneGitMirrors(1h) = %v, %v", removed, err)
+	}
+	if removed, err := PruneGitMirrors(context.Background(), mirrorDir, 0); err != nil || len(removed) != 1 {
+		t.Errorf("PruneGitMirrors(0) = %v, %v", removed, err)
+	}
+	if _, err := os.Stat(mirrors[0].Path); !os.IsNotExist(err) {
+		t.Error("Expected pruned mirror to be removed")
+	}
+	if _, err := os.Stat(lfsDir); !os.IsNotExist(err) {
+		t.Error("Expected LFS objects of pruned mirror to be removed")
+	}
+}
+
+func TestGitDownloader_LFSNotInstalled(t *testing.T) {
+	setupGitTest(t)
+	if exec.Command("git", "lfs", "version").Run() == nil {
+		t.Skip("git-lfs is installed")
+	}
+
+	root := t.TempDir()
+	repoDir := filepath.Join(root, "repo")
+	createTestRepo(t, repoDir, "data.bin")
+
+	dep := config.Dependency{
+		Name:   "assets",
+		Source: config.SourceInfo{Type: "git", URLS: []string{"file://" + repoDir}, LFS: &config.LFS{Enabled: true}},
+	}
+	err := (&GitDownloader{}).Download(context.Background(), dep, filepath.Join(root, "checkout"), nil)
+	if err == nil || !strings.Contains(err.Error(), "git-lfs is not installed") {
+		t.Fatalf("Download() error = %v, want git-lfs not installed", err)
+	}
+	if errors.Hint(err) == "" {
+		t.Error("Expected a remediation hint")
+	}
+}
+
+func TestLockMirror(t *testing.T) {
+	previousTimeout, previousStale := mirrorLockTimeout, mirrorLockStale
+	mirrorLockTimeout, mirrorLockStale = time.Second, 300*time.Millisecond
+	t.Cleanup(func() { mirrorLockTimeout, mirrorLockStale = previousTimeout, previousStale })
+
+	mirror := filepath.Join(t.TempDir(), "repo.git")
+	lockFile := mirror + ".lock"
+
+	// 没有刷新的残留锁会被清理
+	if err := os.WriteFile(lockFile, []byte("99999\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Minute)
+	if err := os.Chtimes(lockFile, old, old); err != nil {
+		t.Fatal(err)
+	}
+	unlock, err := lockMirror(context.Background(), mirror)
+	if err != nil {
+		t.Fatalf("lockMirror() with stale lock error = %v", err)
+	}
+
+	// 持有锁期间刷新锁文件，其他进程等到超时也不会把它当作残留的锁
+	start := time.Now()
+	if _, err := lockMirror(context.Background(), mirror); err == nil {
+		t.Fatal("Expected timeout while the lock is held")
+	} else if code := errors.ExitCode(err); code != errors.ExitDownload {
+		t.Errorf("ExitCode() = %d, want %d", code, errors.ExitDownload)
+	}
+	if elapsed := time.Since(start); elapsed < mirrorLockTimeout {
+		t.Errorf("lockMirror() returned after %s, want at least %s", elapsed, mirrorLockTimeout)
+	}
+
+	// 释放后可以再次获取
+	unlock()
+	if _, err := os.Stat(lockFile); !os.IsNotExist(err) {
+		t.Errorf("Expected lock file to be removed, stat error = %v", err)
+	}
+	unlock, err = lockMirror(context.Background(), mirror)
+	if err != nil {
+		t.Fatalf("lockMirror() after unlock error = %v", err)
+	}
+	unlock()
+
+	// 等待期间取消上下文
+	if err := os.WriteFile(lockFile, []byte("1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := lockMirror(ctx, mirror); err == nil {
+		t.Error("Expected error when the context is canceled")
+	}
+}
@@ -0,0 +1,64 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"buildfly/internal/errors"
+	"buildfly/internal/logging"
+)
+
+// lfsStorageDir 获取与镜像并列存放的 LFS 对象目录：{mirror_dir}/{host_path}-{hash}.lfs
+func lfsStorageDir(mirror string) string {
+	return strings.TrimSuffix(mirror, ".git") + ".lfs"
+}
+
+// checkGitLFS 检查 git-lfs 是否已安装
+func checkGitLFS(ctx context.Context, depName string) error {
+	if err := exec.CommandContext(ctx, "git", "lfs", "version").Run(); err != nil {
+		return errors.DownloadErrorWithCause(err, fmt.Sprintf("dependency %s requires Git LFS, but git-lfs is not installed", depName)).
+			WithHint("install git-lfs (https://git-lfs.com) and make sure `git lfs version` works, or remove `lfs` from the source")
+	}
+	return nil
+}
+
+// fetchLFS 获取并检出 LFS 文件，include 为空时获取所有文件
+// storage 不为空时 LFS 对象保存在该目录，多个源码树共享，之后只下载缺少的对象
+func (gd *GitDownloader) fetchLFS(ctx context.Context, repoDir string, include []string, storage string) error {
+	// 只在本仓库中安装 LFS 过滤器，使 git status 等命令能正确处理 LFS 文件
+	if err := gd.runGit(ctx, repoDir, "lfs", "install", "--local", "--skip-smudge"); err != nil {
+		return errors.DownloadErrorWithCause(err, "failed to set up Git LFS")
+	}
+
+	if storage != "" {
+		if err := os.MkdirAll(storage, 0755); err != nil {
+			return errors.DownloadErrorWithCause(err, fmt.Sprintf("failed to create Git LFS storage %s", storage))
+		}
+		if err := gd.runGit(ctx, repoDir, "config", "lfs.storage", storage); err != nil {
+			return errors.DownloadErrorWithCause(err, "failed to configure Git LFS storage")
+		}
+	}
+
+	// 不共享存储时对象在 .git/lfs 中，随源码树的 .git 一起统计下载量
+	before := dirSize(storage)
+	fetchArgs := []string{"lfs", "fetch"}
+	if len(include) > 0 {
+		fetchArgs = append(fetchArgs, "--include="+strings.Join(include, ","))
+	}
+	fetchArgs = append(fetchArgs, "origin")
+	logging.OrDefault(gd.logger).Debug("Fetching Git LFS objects", "repo", repoDir, "include", include, "storage", storage)
+	if err := gd.runGit(ctx, repoDir, fetchArgs...); err != nil {
+		return errors.DownloadErrorWithCause(err, "failed to fetch Git LFS objects")
+	}
+	if storage != "" {
+		statsFromContext(ctx).Bytes += dirSize(storage) - before
+	}
+
+	if err := gd.runGit(ctx, repoDir, append([]string{"lfs", "checkout"}, include...)...); err != nil {
+		return errors.DownloadErrorWithCause(err, "failed to checkout Git LFS files")
+	}
+	return nil
+}
@@ -4,8 +4,8 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
-	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -31,6 +31,8 @@ type DownloadManager struct {
 	semaphore   chan struct{}
 	mu          sync.RWMutex
 	proxy       *config.ProxyConfig
+	credentials *CredentialStore
+	logger      *slog.Logger
 }
 
 // NewDownloadManager 创建下载管理器
@@ -54,34 +56,27 @@ func NewDownloadManagerWithProxy(maxConcurrent int, proxy *config.ProxyConfig) *
 
 // NewDownloadManagerFromConfig 从配置创建下载管理器
 func NewDownloadManagerFromConfig(maxConcurrent int, config *config.ProjectConfig) *DownloadManager {
-	return NewDownloadManagerWithProxy(maxConcurrent, config.Proxy)
+	dm := NewDownloadManagerWithProxy(maxConcurrent, config.Proxy)
+	if len(config.Credentials) > 0 {
+		dm.SetCredentials(NewCredentialStore(config.Credentials))
+	}
+	if len(config.TrustedKeys) > 0 {
+		dm.SetTrustedKeys(config.TrustedKeys)
+	}
+	return dm
 }
 
 // createHTTPClient 创建 HTTP 客户端，支持代理配置
+// 配置了代理时按请求协议选择代理并跳过 no_proxy 中的主机，否则使用 HTTP(S)_PROXY 环境变量
 func createHTTPClient(proxy *config.ProxyConfig) *http.Client {
 	client := &http.Client{
 		Timeout: 30 * time.Minute,
 	}
 
 	if proxy != nil {
-		if proxy.HTTP != "" || proxy.HTTPS != "" {
-			proxyURL, err := url.Parse(proxy.HTTP)
-			if err != nil {
-				// 如果解析失败，使用 HTTPS 代理
-				if proxy.HTTPS != "" {
-					proxyURL, err = url.Parse(proxy.HTTPS)
-					if err != nil {
-						// 如果都失败，返回不使用代理的客户端
-						return client
-					}
-				} else {
-					return client
-				}
-			}
-			client.Transport = &http.Transport{
-				Proxy: http.ProxyURL(proxyURL),
-			}
-		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = proxy.ProxyFunc()
+		client.Transport = transport
 	}
 
 	return client
@@ -89,9 +84,68 @@ func createHTTPClient(proxy *config.ProxyConfig) *http.Client {
 
 // registerDownloaders 注册下载器
 func (dm *DownloadManager) registerDownloaders() {
-	dm.RegisterDownloader("git", &GitDownloader{})
-	dm.RegisterDownloader("archive", &ArchiveDownloader{client: dm.client})
-	dm.RegisterDownloader("direct", &DirectDownloader{client: dm.client})
+	dm.RegisterDownloader("git", &GitDownloader{logger: dm.logger})
+	dm.RegisterDownloader("archive", &ArchiveDownloader{client: dm.client, logger: dm.logger})
+	dm.RegisterDownloader("direct", &DirectDownloader{client: dm.client, logger: dm.logger})
+}
+
+// SetLogger 设置日志记录器，同时应用到内置的下载器
+func (dm *DownloadManager) SetLogger(logger *slog.Logger) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+
+	dm.logger = logger
+	for _, downloader := range dm.downloaders {
+		switch d := downloader.(type) {
+		case *GitDownloader:
+			d.logger = logger
+		case *ArchiveDownloader:
+			d.logger = logger
+		case *DirectDownloader:
+			d.logger = logger
+		}
+	}
+}
+
+// SetCredentials 设置按主机的下载凭据，应用到 HTTP 下载和 Git 克隆
+func (dm *DownloadManager) SetCredentials(store *CredentialStore) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+
+	base := dm.client.Transport
+	if auth, ok := base.(*authTransport); ok {
+		base = auth.base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	// 下载器共享同一个 client，替换 Transport 即可应用到所有 HTTP 下载
+	dm.client.Transport = &authTransport{base: base, store: store}
+	dm.credentials = store
+
+	if gd, ok := dm.downloaders["git"].(*GitDownloader); ok {
+		gd.credentials = store
+	}
+}
+
+// SetTrustedKeys 设置校验压缩包签名的可信公钥
+func (dm *DownloadManager) SetTrustedKeys(keys map[string]config.TrustedKey) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+
+	if ad, ok := dm.downloaders["archive"].(*ArchiveDownloader); ok {
+		ad.trustedKeys = keys
+	}
+}
+
+// SetGitMirrorDir 设置 Git 裸镜像目录，Git 依赖从镜像增量获取
+func (dm *DownloadManager) SetGitMirrorDir(dir string) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+
+	if gd, ok := dm.downloaders["git"].(*GitDownloader); ok {
+		gd.SetMirrorDir(dir)
+	}
 }
 
 // RegisterDownloader 注册下载器
@@ -0,0 +1,254 @@
+package downloader
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"buildfly/internal/errors"
+	"buildfly/internal/logging"
+	"buildfly/pkg/config"
+)
+
+// mirrorUsedMarker 记录镜像最近使用时间的文件，git gc 不会修改它
+const mirrorUsedMarker = "buildfly-last-used"
+
+// mirrorLockTimeout 等待其他进程更新同一镜像的最长时间
+var mirrorLockTimeout = 10 * time.Minute
+
+// mirrorLockStale 锁文件超过该时间没有刷新时视为残留的锁（持有锁的进程已退出）
+// 持有锁期间定期刷新锁文件的修改时间，长时间的克隆不会被误判为残留
+var mirrorLockStale = time.Minute
+
+// mirrorNamePattern 镜像目录名中需要替换的字符
+var mirrorNamePattern = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// GitMirrorDir 获取缓存目录下 Git 镜像的存放目录
+func GitMirrorDir(cacheDir string) string {
+	return filepath.Join(cacheDir, "git")
+}
+
+// mirrorPath 获取远程仓库对应的裸镜像路径：{mirror_dir}/{host_path}-{hash}.git
+func mirrorPath(mirrorDir, url string) string {
+	name := url
+	if i := strings.Index(name, "://"); i >= 0 {
+		name = name[i+3:]
+	}
+	name = strings.TrimSuffix(strings.TrimSuffix(name, "/"), ".git")
+	name = strings.Trim(mirrorNamePattern.ReplaceAllString(name, "_"), "_")
+	if len(name) > 64 {
+		name = name[len(name)-64:]
+	}
+
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(mirrorDir, fmt.Sprintf("%s-%s.git", name, hex.EncodeToString(sum[:])[:12]))
+}
+
+// SetMirrorDir 设置 Git 镜像目录，为空时每次都从远程完整克隆
+func (gd *GitDownloader) SetMirrorDir(dir string) {
+	gd.mirrorDir = dir
+}
+
+// updateMirror 创建或增量更新远程仓库的裸镜像，返回镜像路径
+// 固定的提交或标签已经在镜像中时不访问远程仓库
+func (gd *GitDownloader) updateMirror(ctx context.Context, url string, source config.SourceInfo) (string, error) {
+	mirror := mirrorPath(gd.mirrorDir, url)
+	if err := os.MkdirAll(gd.mirrorDir, 0755); err != nil {
+		return "", errors.DownloadErrorWithCause(err, fmt.Sprintf("failed to create git mirror directory %s", gd.mirrorDir))
+	}
+
+	unlock, err := lockMirror(ctx, mirror)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	before := dirSize(mirror)
+	if _, err := os.Stat(mirror); os.IsNotExist(err) {
+		logging.OrDefault(gd.logger).Debug("Creating git mirror", "url", url, "path", mirror)
+		if err := gd.runGit(ctx, "", "clone", "--mirror", url, mirror); err != nil {
+			os.RemoveAll(mirror)
+			return "", errors.DownloadErrorWithCause(err, fmt.Sprintf("failed to mirror repository %s", url))
+		}
+	} else if !gd.mirrorHasRef(ctx, mirror, source) {
+		logging.OrDefault(gd.logger).Debug("Updating git mirror", "url", url, "path", mirror)
+		if err := gd.runGit(ctx, mirror, "fetch", "--prune", "--tags", "origin"); err != nil {
+			return "", errors.DownloadErrorWithCause(err, fmt.Sprintf("failed to update git mirror of %s", url))
+		}
+	} else {
+		logging.OrDefault(gd.logger).Debug("Git mirror is up to date", "url", url, "path", mirror)
+	}
+
+	// 固定的提交不在任何分支或标签上时单独获取
+	if source.Commit != "" && !gd.mirrorHasRef(ctx, mirror, source) {
+		if err := gd.runGit(ctx, mirror, "fetch", "origin", source.Commit); err != nil {
+			return "", errors.DownloadErrorWithCause(err, fmt.Sprintf("failed to fetch commit %s into git mirror", source.Commit))
+		}
+	}
+	statsFromContext(ctx).Bytes += dirSize(mirror) - before
+
+	// 记录最近使用时间，用于清理长期未使用的镜像
+	if err := os.WriteFile(filepath.Join(mirror, mirrorUsedMarker), []byte(time.Now().Format(time.RFC3339)+"\n"), 0644); err != nil {
+		logging.OrDefault(gd.logger).Debug("Failed to record git mirror usage", "path", mirror, "error", err)
+	}
+	return mirror, nil
+}
+
+// mirrorHasRef 检查镜像中是否已有需要的提交或标签；分支和未固定的版本总是需要更新
+func (gd *GitDownloader) mirrorHasRef(ctx context.Context, mirror string, source config.SourceInfo) bool {
+	var ref string
+	switch {
+	case source.Commit != "":
+		ref = source.Commit + "^{commit}"
+	case source.Tag != "":
+		ref = "refs/tags/" + source.Tag
+	default:
+		return false
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--verify", "--quiet", ref)
+	cmd.Dir = mirror
+	return cmd.Run() == nil
+}
+
+// lockMirror 获取镜像的独占锁，避免并行构建（例如构建矩阵）同时更新同一个镜像
+// 超过 mirrorLockTimeout 仍未获取到锁时返回错误；没有刷新的残留锁会被清理
+func lockMirror(ctx context.Context, mirror string) (func(), error) {
+	lockFile := mirror + ".lock"
+	deadline := time.Now().Add(mirrorLockTimeout)
+	for {
+		file, err := os.OpenFile(lockFile, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			fmt.Fprintf(file, "%d\n", os.Getpid())
+			file.Close()
+			return keepMirrorLock(lockFile), nil
+		}
+		if !os.IsExist(err) {
+			return nil, errors.DownloadErrorWithCause(err, fmt.Sprintf("failed to lock git mirror %s", mirror))
+		}
+
+		// 清理残留的锁
+		if info, statErr := os.Stat(lockFile); statErr == nil && time.Since(info.ModTime()) > mirrorLockStale {
+			os.Remove(lockFile)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, errors.DownloadError(fmt.Sprintf("timed out waiting for git mirror lock %s", lockFile)).
+				WithHint("another buildfly process is updating this mirror; if none is running, remove the lock file")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, errors.DownloadErrorWithCause(ctx.Err(), fmt.Sprintf("canceled while waiting for git mirror lock %s", lockFile))
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
+
+// keepMirrorLock 在持有锁期间定期刷新锁文件的修改时间，返回释放锁的函数
+func keepMirrorLock(lockFile string) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(mirrorLockStale / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				os.Chtimes(lockFile, now, now)
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+		os.Remove(lockFile)
+	}
+}
+
+// GitMirror Git 镜像信息
+type GitMirror struct {
+	Path     string
+	URL      string
+	Size     int64 // 包含 LFS 对象
+	LFSSize  int64
+	LastUsed time.Time
+}
+
+// ListGitMirrors 列出镜像目录中的所有镜像，按路径排序
+func ListGitMirrors(mirrorDir string) ([]GitMirror, error) {
+	entries, err := os.ReadDir(mirrorDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.CacheErrorWithCause(err, fmt.Sprintf("failed to read git mirror directory %s", mirrorDir))
+	}
+
+	var mirrors []GitMirror
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasSuffix(entry.Name(), ".git") {
+			continue
+		}
+		path := filepath.Join(mirrorDir, entry.Name())
+		info, err := os.Stat(filepath.Join(path, mirrorUsedMarker))
+		if err != nil {
+			if info, err = entry.Info(); err != nil {
+				continue
+			}
+		}
+
+		mirror := GitMirror{Path: path, LFSSize: dirSize(lfsStorageDir(path)), LastUsed: info.ModTime()}
+		mirror.Size = dirSize(path) + mirror.LFSSize
+		cmd := exec.Command("git", "config", "--get", "remote.origin.url")
+		cmd.Dir = path
+		if output, err := cmd.Output(); err == nil {
+			mirror.URL = strings.TrimSpace(string(output))
+		}
+		mirrors = append(mirrors, mirror)
+	}
+
+	sort.Slice(mirrors, func(i, j int) bool { return mirrors[i].Path < mirrors[j].Path })
+	return mirrors, nil
+}
+
+// PruneGitMirrors 删除超过 maxAge 未使用的镜像及其 LFS 对象，并对保留的镜像执行 git gc --auto
+// 返回被删除的镜像
+func PruneGitMirrors(ctx context.Context, mirrorDir string, maxAge time.Duration) ([]GitMirror, error) {
+	mirrors, err := ListGitMirrors(mirrorDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []GitMirror
+	for _, mirror := range mirrors {
+		if time.Since(mirror.LastUsed) > maxAge {
+			for _, path := range []string{mirror.Path, lfsStorageDir(mirror.Path)} {
+				if err := os.RemoveAll(path); err != nil {
+					return removed, errors.CacheErrorWithCause(err, fmt.Sprintf("failed to remove git mirror %s", path))
+				}
+			}
+			removed = append(removed, mirror)
+			continue
+		}
+
+		cmd := exec.CommandContext(ctx, "git", "gc", "--auto", "--quiet")
+		cmd.Dir = mirror.Path
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return removed, errors.CacheErrorWithCause(err, fmt.Sprintf("git gc failed for %s: %s", mirror.Path, strings.TrimSpace(string(output))))
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,344 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"buildfly/internal/errors"
+	"buildfly/pkg/config"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/ssh"
+)
+
+// maxSignatureSize 签名文件的大小上限
+const maxSignatureSize = 1 << 20
+
+// Signer 通过校验的签名者
+type Signer struct {
+	Type        string // openpgp, minisign, ssh
+	Key         string // trusted_keys 中的公钥名称
+	Fingerprint string // OpenPGP 主密钥指纹、minisign 密钥 ID 或 SSH 公钥 SHA256 指纹
+	Identity    string // OpenPGP 用户 ID、minisign 可信注释或 SSH 公钥注释
+}
+
+// verifySignature 使用可信公钥校验压缩包的分离签名，返回签名者
+func (ad *ArchiveDownloader) verifySignature(ctx context.Context, dep config.Dependency, archivePath string) (*Signer, error) {
+	format := dep.Source.SignatureType()
+	names, err := signingKeys(ad.trustedKeys, dep.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := ad.fetchSignature(ctx, dep.Source.Signature)
+	if err != nil {
+		return nil, errors.DownloadErrorWithCause(err, fmt.Sprintf("failed to fetch signature of %s", dep.Name)).
+			WithHint(fmt.Sprintf("check source.signature of %s: %s", dep.Name, dep.Source.Signature))
+	}
+
+	var failures []string
+	for _, name := range names {
+		key := ad.trustedKeys[name]
+		material, err := key.Material()
+		if err != nil {
+			return nil, errors.ConfigErrorWithCause(err, fmt.Sprintf("failed to load trusted key %s", name))
+		}
+
+		signer, err := verifyDetachedSignature(format, material, archivePath, signature)
+		if err == nil {
+			signer.Type = format
+			signer.Key = name
+			return signer, nil
+		}
+		failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+	}
+
+	return nil, errors.SignatureError(fmt.Sprintf("signature of %s is not valid for any trusted key (%s)", dep.Name, strings.Join(failures, "; "))).
+		WithHint(fmt.Sprintf("do not use this archive of %s; check that %s belongs to this release and that the signer's key is in trusted_keys", dep.Name, dep.Source.Signature))
+}
+
+// signingKeys 获取可以为依赖签名的可信公钥名称：signed_by 中的公钥，没有指定时为所有同格式的公钥
+func signingKeys(trustedKeys map[string]config.TrustedKey, source config.SourceInfo) ([]string, error) {
+	format := source.SignatureType()
+
+	if len(source.SignedBy) > 0 {
+		for _, name := range source.SignedBy {
+			key, ok := trustedKeys[name]
+			if !ok {
+				return nil, errors.ConfigError(fmt.Sprintf("trusted key %s in signed_by is not configured", name)).
+					WithHint("add the key to trusted_keys in buildfly.yaml or ~/.config/buildfly/config.yaml")
+			}
+			if key.Type != format {
+				return nil, errors.ConfigError(fmt.Sprintf("trusted key %s is a %s key, the signature is %s", name, key.Type, format)).
+					WithHint("set source.signature_format to the format of the signature file")
+			}
+		}
+		return source.SignedBy, nil
+	}
+
+	var names []string
+	for name, key := range trustedKeys {
+		if key.Type == format {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, errors.ConfigError(fmt.Sprintf("no %s key in trusted_keys to verify the signature", format)).
+			WithHint("add the upstream release signing key to trusted_keys in buildfly.yaml")
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// fetchSignature 读取本地签名文件或通过 HTTP 下载签名
+func (ad *ArchiveDownloader) fetchSignature(ctx context.Context, location string) ([]byte, error) {
+	var reader io.Reader
+	if (&config.SourceInfo{}).IsLocalURL(location) {
+		file, err := os.Open(location)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		reader = file
+	} else {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := ad.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+		}
+		reader = resp.Body
+	}
+
+	data, err := io.ReadAll(io.LimitReader(reader, maxSignatureSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxSignatureSize {
+		return nil, fmt.Errorf("signature is larger than %d bytes", maxSignatureSize)
+	}
+	return data, nil
+}
+
+// verifyDetachedSignature 按格式校验文件的分离签名
+func verifyDetachedSignature(format string, key []byte, path string, signature []byte) (*Signer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	switch format {
+	case config.SignatureOpenPGP:
+		return verifyOpenPGP(key, file, signature)
+	case config.SignatureMinisign:
+		return verifyMinisign(key, file, signature)
+	case config.SignatureSSH:
+		return verifySSH(key, file, signature)
+	default:
+		return nil, fmt.Errorf("unsupported signature format %s", format)
+	}
+}
+
+// isArmored 数据是否为 ASCII 装甲格式
+func isArmored(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte("-----BEGIN "))
+}
+
+// verifyOpenPGP 校验 OpenPGP 分离签名，公钥和签名都可以是 ASCII 装甲或二进制格式
+func verifyOpenPGP(key []byte, signed io.Reader, signature []byte) (*Signer, error) {
+	var keyring openpgp.EntityList
+	var err error
+	if isArmored(key) {
+		keyring, err = openpgp.ReadArmoredKeyRing(bytes.NewReader(key))
+	} else {
+		keyring, err = openpgp.ReadKeyRing(bytes.NewReader(key))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid OpenPGP key: %w", err)
+	}
+
+	var entity *openpgp.Entity
+	if isArmored(signature) {
+		entity, err = openpgp.CheckArmoredDetachedSignature(keyring, signed, bytes.NewReader(signature), nil)
+	} else {
+		entity, err = openpgp.CheckDetachedSignature(keyring, signed, bytes.NewReader(signature), nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	signer := &Signer{Fingerprint: fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint)}
+	if identity := entity.PrimaryIdentity(); identity != nil {
+		signer.Identity = identity.Name
+	}
+	return signer, nil
+}
+
+// minisignLines 获取 minisign 文件中的内容行，跳过 untrusted comment
+func minisignLines(data []byte) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		if line == "" || strings.HasPrefix(line, "untrusted comment:") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// verifyMinisign 校验 minisign 签名，支持 Ed（直接签名）和 ED（BLAKE2b 预哈希）两种算法
+// 公钥可以是 minisign -G 生成的 .pub 文件内容或其中的 base64 公钥
+func verifyMinisign(key []byte, signed io.Reader, signature []byte) (*Signer, error) {
+	keyLines := minisignLines(key)
+	if len(keyLines) == 0 {
+		return nil, fmt.Errorf("invalid minisign key")
+	}
+	publicKey, err := base64.StdEncoding.DecodeString(strings.TrimSpace(keyLines[0]))
+	if err != nil || len(publicKey) != 2+8+ed25519.PublicKeySize || string(publicKey[:2]) != "Ed" {
+		return nil, fmt.Errorf("invalid minisign key")
+	}
+	keyID, pub := publicKey[2:10], ed25519.PublicKey(publicKey[10:])
+
+	sigLines := minisignLines(signature)
+	if len(sigLines) != 3 || !strings.HasPrefix(sigLines[1], "trusted comment: ") {
+		return nil, fmt.Errorf("invalid minisign signature")
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(sigLines[0]))
+	if err != nil || len(sig) != 2+8+ed25519.SignatureSize {
+		return nil, fmt.Errorf("invalid minisign signature")
+	}
+	globalSig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(sigLines[2]))
+	if err != nil || len(globalSig) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("invalid minisign signature")
+	}
+	trustedComment := strings.TrimPrefix(sigLines[1], "trusted comment: ")
+
+	if !bytes.Equal(sig[2:10], keyID) {
+		return nil, fmt.Errorf("signed by a different key (key ID %016X)", binary.LittleEndian.Uint64(sig[2:10]))
+	}
+
+	var message []byte
+	switch string(sig[:2]) {
+	case "ED":
+		h, _ := blake2b.New512(nil)
+		if _, err := io.Copy(h, signed); err != nil {
+			return nil, err
+		}
+		message = h.Sum(nil)
+	case "Ed":
+		if message, err = io.ReadAll(signed); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported minisign algorithm %q", sig[:2])
+	}
+
+	if !ed25519.Verify(pub, message, sig[10:]) {
+		return nil, fmt.Errorf("signature does not match the file")
+	}
+	if !ed25519.Verify(pub, append(append([]byte{}, sig[10:]...), trustedComment...), globalSig) {
+		return nil, fmt.Errorf("trusted comment signature is invalid")
+	}
+
+	return &Signer{
+		Fingerprint: fmt.Sprintf("%016X", binary.LittleEndian.Uint64(keyID)),
+		Identity:    trustedComment,
+	}, nil
+}
+
+// sshSignatureNamespace ssh-keygen -Y sign -n file 使用的命名空间
+const sshSignatureNamespace = "file"
+
+// sshSignatureBlob SSHSIG 签名内容（PROTOCOL.sshsig），不含开头的 SSHSIG 魔数
+type sshSignatureBlob struct {
+	Version       uint32
+	PublicKey     []byte
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Signature     []byte
+}
+
+// sshSignedData 实际被签名的数据，不含开头的 SSHSIG 魔数
+type sshSignedData struct {
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Hash          []byte
+}
+
+// verifySSH 校验 ssh-keygen -Y sign 生成的 SSH 签名，公钥为 authorized_keys 格式
+func verifySSH(key []byte, signed io.Reader, signature []byte) (*Signer, error) {
+	pub, comment, _, _, err := ssh.ParseAuthorizedKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SSH key: %w", err)
+	}
+
+	block, _ := pem.Decode(signature)
+	if block == nil || block.Type != "SSH SIGNATURE" || !bytes.HasPrefix(block.Bytes, []byte("SSHSIG")) {
+		return nil, fmt.Errorf("invalid SSH signature")
+	}
+	var blob sshSignatureBlob
+	if err := ssh.Unmarshal(block.Bytes[len("SSHSIG"):], &blob); err != nil {
+		return nil, fmt.Errorf("invalid SSH signature: %w", err)
+	}
+	if blob.Version != 1 {
+		return nil, fmt.Errorf("unsupported SSH signature version %d", blob.Version)
+	}
+	if blob.Namespace != sshSignatureNamespace {
+		return nil, fmt.Errorf("SSH signature namespace is %q, expected %q", blob.Namespace, sshSignatureNamespace)
+	}
+	if !bytes.Equal(blob.PublicKey, pub.Marshal()) {
+		return nil, fmt.Errorf("signed by a different key")
+	}
+
+	var h hash.Hash
+	switch blob.HashAlgorithm {
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return nil, fmt.Errorf("unsupported SSH signature hash %s", blob.HashAlgorithm)
+	}
+	if _, err := io.Copy(h, signed); err != nil {
+		return nil, err
+	}
+
+	var sig ssh.Signature
+	if err := ssh.Unmarshal(blob.Signature, &sig); err != nil {
+		return nil, fmt.Errorf("invalid SSH signature: %w", err)
+	}
+	data := append([]byte("SSHSIG"), ssh.Marshal(sshSignedData{
+		Namespace:     blob.Namespace,
+		Reserved:      blob.Reserved,
+		HashAlgorithm: blob.HashAlgorithm,
+		Hash:          h.Sum(nil),
+	})...)
+	if err := pub.Verify(data, &sig); err != nil {
+		return nil, fmt.Errorf("signature does not match the file")
+	}
+
+	return &Signer{Fingerprint: ssh.FingerprintSHA256(pub), Identity: comment}, nil
+}
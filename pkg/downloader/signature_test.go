@@ -0,0 +1,355 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"buildfly/internal/errors"
+	"buildfly/pkg/config"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/ssh"
+)
+
+// testSigner 测试用的签名密钥，生成公钥和对内容的分离签名
+type testSigner interface {
+	publicKey() string
+	sign(content []byte) []byte
+}
+
+// openPGPTestSigner OpenPGP 测试密钥
+type openPGPTestSigner struct {
+	t      *testing.T
+	entity *openpgp.Entity
+	armor  bool
+}
+
+func newOpenPGPTestSigner(t *testing.T, name string, armored bool) *openPGPTestSigner {
+	entity, err := openpgp.NewEntity(name, "", name+"@example.com", &packet.Config{Algorithm: packet.PubKeyAlgoEdDSA})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &openPGPTestSigner{t: t, entity: entity, armor: armored}
+}
+
+func (s *openPGPTestSigner) publicKey() string {
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	if err := s.entity.Serialize(w); err != nil {
+		s.t.Fatal(err)
+	}
+	w.Close()
+	return buf.String()
+}
+
+func (s *openPGPTestSigner) sign(content []byte) []byte {
+	var buf bytes.Buffer
+	var err error
+	if s.armor {
+		err = openpgp.ArmoredDetachSign(&buf, s.entity, bytes.NewReader(content), nil)
+	} else {
+		err = openpgp.DetachSign(&buf, s.entity, bytes.NewReader(content), nil)
+	}
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// minisignTestSigner minisign 测试密钥
+type minisignTestSigner struct {
+	keyID   []byte
+	private ed25519.PrivateKey
+	public  ed25519.PublicKey
+}
+
+func newMinisignTestSigner(t *testing.T) *minisignTestSigner {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyID := make([]byte, 8)
+	rand.Read(keyID)
+	return &minisignTestSigner{keyID: keyID, private: private, public: public}
+}
+
+func (s *minisignTestSigner) publicKey() string {
+	key := append(append([]byte("Ed"), s.keyID...), s.public...)
+	return "untrusted comment: minisign public key\n" + base64.StdEncoding.EncodeToString(key) + "\n"
+}
+
+func (s *minisignTestSigner) sign(content []byte) []byte {
+	hash := blake2b.Sum512(content)
+	sig := ed25519.Sign(s.private, hash[:])
+	comment := "timestamp:1700000000\tfile:release.tar.gz\thashed"
+	global := ed25519.Sign(s.private, append(append([]byte{}, sig...), comment...))
+	return []byte("untrusted comment: signature from minisign secret key\n" +
+		base64.StdEncoding.EncodeToString(append(append([]byte("ED"), s.keyID...), sig...)) + "\n" +
+		"trusted comment: " + comment + "\n" +
+		base64.StdEncoding.EncodeToString(global) + "\n")
+}
+
+// sshTestSigner SSH 测试密钥
+type sshTestSigner struct {
+	t      *testing.T
+	signer ssh.Signer
+}
+
+func newSSHTestSigner(t *testing.T) *sshTestSigner {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &sshTestSigner{t: t, signer: signer}
+}
+
+func (s *sshTestSigner) publicKey() string {
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(s.signer.PublicKey()))) + " release@example.com\n"
+}
+
+func (s *sshTestSigner) sign(content []byte) []byte {
+	hash := sha512.Sum512(content)
+	data := append([]byte("SSHSIG"), ssh.Marshal(sshSignedData{Namespace: "file", HashAlgorithm: "sha512", Hash: hash[:]})...)
+	sig, err := s.signer.Sign(rand.Reader, data)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	blob := append([]byte("SSHSIG"), ssh.Marshal(sshSignatureBlob{
+		Version:       1,
+		PublicKey:     s.signer.PublicKey().Marshal(),
+		Namespace:     "file",
+		HashAlgorithm: "sha512",
+		Signature:     ssh.Marshal(sig),
+	})...)
+	return pem.EncodeToMemory(&pem.Block{Type: "SSH SIGNATURE", Bytes: blob})
+}
+
+func TestVerifyDetachedSignature(t *testing.T) {
+	content := []byte("openssl-3.0.0 release tarball")
+	path := filepath.Join(t.TempDir(), "release.tar.gz")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		format       string
+		signer       testSigner
+		other        testSigner
+		wantIdentity string
+	}{
+		{config.SignatureOpenPGP, newOpenPGPTestSigner(t, "Release", true), newOpenPGPTestSigner(t, "Other", true), "Release <Release@example.com>"},
+		{config.SignatureOpenPGP, newOpenPGPTestSigner(t, "Binary", false), newOpenPGPTestSigner(t, "Other", false), "Binary <Binary@example.com>"},
+		{config.SignatureMinisign, newMinisignTestSigner(t), newMinisignTestSigner(t), "timestamp:1700000000\tfile:release.tar.gz\thashed"},
+		{config.SignatureSSH, newSSHTestSigner(t), newSSHTestSigner(t), "release@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			signature := tt.signer.sign(content)
+
+			signer, err := verifyDetachedSignature(tt.format, []byte(tt.signer.publicKey()), path, signature)
+			if err != nil {
+				t.Fatalf("verifyDetachedSignature() error = %v", err)
+			}
+			if signer.Identity != tt.wantIdentity {
+				t.Errorf("Identity = %q, want %q", signer.Identity, tt.wantIdentity)
+			}
+			if signer.Fingerprint == "" {
+				t.Error("Fingerprint is empty")
+			}
+
+			// 其他密钥的签名
+			if _, err := verifyDetachedSignature(tt.format, []byte(tt.other.publicKey()), path, signature); err == nil {
+				t.Error("expected error for signature by an untrusted key")
+			}
+
+			// 文件被篡改
+			if _, err := verifyDetachedSignature(tt.format, []byte(tt.signer.publicKey()), path, tt.signer.sign([]byte("tampered"))); err == nil {
+				t.Error("expected error for signature of different content")
+			}
+		})
+	}
+}
+
+func TestVerifySSH_RejectsOtherNamespace(t *testing.T) {
+	signer := newSSHTestSigner(t)
+	content := []byte("release")
+	path := filepath.Join(t.TempDir(), "release.tar.gz")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 使用 git 命名空间的签名不能作为文件签名
+	hash := sha512.Sum512(content)
+	data := append([]byte("SSHSIG"), ssh.Marshal(sshSignedData{Namespace: "git", HashAlgorithm: "sha512", Hash: hash[:]})...)
+	sig, err := signer.signer.Sign(rand.Reader, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob := append([]byte("SSHSIG"), ssh.Marshal(sshSignatureBlob{
+		Version:       1,
+		PublicKey:     signer.signer.PublicKey().Marshal(),
+		Namespace:     "git",
+		HashAlgorithm: "sha512",
+		Signature:     ssh.Marshal(sig),
+	})...)
+	signature := pem.EncodeToMemory(&pem.Block{Type: "SSH SIGNATURE", Bytes: blob})
+
+	if _, err := verifyDetachedSignature(config.SignatureSSH, []byte(signer.publicKey()), path, signature); err == nil ||
+		!strings.Contains(err.Error(), "namespace") {
+		t.Errorf("expected namespace error, got %v", err)
+	}
+}
+
+func TestSigningKeys(t *testing.T) {
+	keys := map[string]config.TrustedKey{
+		"openssl":   {Type: "openpgp", Key: "a"},
+		"gnupg":     {Type: "openpgp", Key: "b"},
+		"libsodium": {Type: "minisign", Key: "c"},
+	}
+
+	tests := []struct {
+		name    string
+		source  config.SourceInfo
+		want    []string
+		wantErr bool
+	}{
+		{"all keys of format", config.SourceInfo{Signature: "a.asc"}, []string{"gnupg", "openssl"}, false},
+		{"signed by", config.SourceInfo{Signature: "a.asc", SignedBy: []string{"openssl"}}, []string{"openssl"}, false},
+		{"minisign", config.SourceInfo{Signature: "a.minisig"}, []string{"libsodium"}, false},
+		{"unknown key", config.SourceInfo{Signature: "a.asc", SignedBy: []string{"missing"}}, nil, true},
+		{"wrong format", config.SourceInfo{Signature: "a.asc", SignedBy: []string{"libsodium"}}, nil, true},
+		{"no key of format", config.SourceInfo{Signature: "a.sig", SignatureFormat: "ssh"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := signingKeys(keys, tt.source)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("signingKeys() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("signingKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArchiveDownloader_DownloadVerifiesSignature(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	// 创建本地压缩包
+	tmpDir := t.TempDir()
+	srcDir := filepath.Join(tmpDir, "openssl-3.0.0")
+	if err := os.MkdirAll(srcDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "README"), []byte("OpenSSL 3.0.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	archive := filepath.Join(tmpDir, "openssl-3.0.0.tar.gz")
+	if output, err := execCommand("tar", "-czf", archive, "-C", tmpDir, "openssl-3.0.0").CombinedOutput(); err != nil {
+		t.Fatalf("tar: %v\n%s", err, output)
+	}
+	content, err := os.ReadFile(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	release := newOpenPGPTestSigner(t, "Release", true)
+	attacker := newOpenPGPTestSigner(t, "Attacker", true)
+
+	// 远程签名
+	signatures := map[string][]byte{
+		"/good.asc": release.sign(content),
+		"/bad.asc":  attacker.sign(content),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature, ok := signatures[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(signature)
+	}))
+	defer server.Close()
+
+	// 本地签名
+	localSignature := archive + ".asc"
+	if err := os.WriteFile(localSignature, release.sign(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dm := NewDownloadManager(1)
+	dm.SetTrustedKeys(map[string]config.TrustedKey{"release": {Type: "openpgp", Key: release.publicKey()}})
+
+	tests := []struct {
+		name      string
+		signature string
+		wantCode  string
+	}{
+		{"local signature", localSignature, ""},
+		{"remote signature", server.URL + "/good.asc", ""},
+		{"untrusted signer", server.URL + "/bad.asc", "SIGNATURE_INVALID"},
+		{"missing signature", server.URL + "/missing.asc", "DOWNLOAD_ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dep := config.Dependency{Name: "openssl", Version: "3.0.0", Source: config.SourceInfo{
+				Type: "archive", URLS: []string{archive}, Signature: tt.signature,
+			}}
+			targetDir := filepath.Join(t.TempDir(), "src")
+
+			stats, err := dm.DownloadWithStats(context.Background(), dep, targetDir)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Fatalf("DownloadWithStats() error = %v", err)
+				}
+				if stats.Signer == nil || stats.Signer.Key != "release" || stats.Signer.Type != "openpgp" {
+					t.Errorf("Signer = %+v, want release openpgp key", stats.Signer)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("expected signature verification error")
+			}
+			found := false
+			for _, entry := range errors.Chain(err) {
+				found = found || entry.Code == tt.wantCode
+			}
+			if !found {
+				t.Errorf("expected %s in error chain, got %v", tt.wantCode, err)
+			}
+			if hint := errors.Hint(err); tt.wantCode == "SIGNATURE_INVALID" && !strings.Contains(hint, "trusted_keys") {
+				t.Errorf("hint = %q, want trusted_keys suggestion", hint)
+			}
+			// 签名校验失败时不解压
+			if _, err := os.Stat(filepath.Join(targetDir, "README")); !os.IsNotExist(err) {
+				t.Error("archive extracted despite failed signature verification")
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package downloader
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"time"
+
+	"buildfly/pkg/config"
+)
+
+// DownloadStats 单次下载的统计信息
+type DownloadStats struct {
+	Bytes        int64         // 从网络传输的字节数，Git 仓库按克隆后 .git 目录的大小估算
+	DownloadTime time.Duration // 下载耗时（不含解压）
+	ExtractTime  time.Duration // 解压耗时
+	SHA256       string        // 压缩包的 SHA256，非压缩包依赖为空
+	Signer       *Signer       // 校验通过的压缩包签名，没有配置签名时为 nil
+}
+
+// downloadStatsKey 上下文中保存下载统计的键
+type downloadStatsKey struct{}
+
+// withDownloadStats 返回携带下载统计的上下文，下载器将统计信息累加到 stats
+func withDownloadStats(ctx context.Context, stats *DownloadStats) context.Context {
+	return context.WithValue(ctx, downloadStatsKey{}, stats)
+}
+
+// statsFromContext 获取上下文中的下载统计，没有时返回一个丢弃结果的统计对象
+func statsFromContext(ctx context.Context) *DownloadStats {
+	if stats, ok := ctx.Value(downloadStatsKey{}).(*DownloadStats); ok {
+		return stats
+	}
+	return &DownloadStats{}
+}
+
+// DownloadWithStats 下载单个依赖并返回传输字节数和各阶段耗时
+func (dm *DownloadManager) DownloadWithStats(ctx context.Context, dep config.Dependency, targetDir string) (*DownloadStats, error) {
+	stats := &DownloadStats{}
+	start := time.Now()
+	err := dm.DownloadWithProgress(withDownloadStats(ctx, stats), dep, targetDir, nil)
+	stats.DownloadTime = time.Since(start) - stats.ExtractTime
+	return stats, err
+}
+
+// dirSize 计算目录下所有文件的大小
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.WalkDir(dir, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		if info, err := entry.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
@@ -1,9 +1,15 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 )
 
 // CopyFile 复制文件，默认保留原权限
@@ -176,3 +182,129 @@ func PathExists(path string) bool {
 	_, err := os.Stat(path)
 	return err == nil
 }
+
+// IsVCSDir 是否为版本控制目录，计算指纹、同步和监视源码时跳过
+func IsVCSDir(name string) bool {
+	return name == ".git" || name == ".hg" || name == ".svn"
+}
+
+// DirFingerprint 根据目录中所有文件的相对路径、大小、修改时间和权限计算指纹
+// 用于检测本地源码是否有变化，跳过版本控制目录
+func DirFingerprint(dir string) (string, error) {
+	h := sha256.New()
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != dir && IsVCSDir(entry.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00%d\x00%o\n", filepath.ToSlash(relPath), info.Size(), info.ModTime().UnixNano(), info.Mode())
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// syncManifest dst 中记录上次同步过的文件列表，用于删除源码中已经移除的文件
+const syncManifest = ".buildfly-synced"
+
+// SyncDir 将 src 中新增或修改过的文件复制到 dst，并保留修改时间，
+// 使 make 等基于修改时间的构建工具只重新编译变化的文件。
+// 上次同步过但已从 src 中删除的文件也会从 dst 中删除；其他多出的文件（例如构建产物）保持不变，
+// 跳过版本控制目录。返回复制和删除的文件数
+func SyncDir(src, dst string) (copied, removed int, err error) {
+	manifestPath := filepath.Join(dst, syncManifest)
+	var previous []string
+	if data, err := os.ReadFile(manifestPath); err == nil {
+		previous = strings.Split(strings.TrimSpace(string(data)), "\n")
+	}
+
+	synced := make(map[string]bool)
+	err = filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		targetPath := filepath.Join(dst, relPath)
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != src && IsVCSDir(entry.Name()) {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(targetPath, info.Mode().Perm())
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		synced[filepath.ToSlash(relPath)] = true
+
+		// 大小和修改时间都相同时认为文件没有变化
+		if target, err := os.Stat(targetPath); err == nil && target.Size() == info.Size() && target.ModTime().Equal(info.ModTime()) {
+			return nil
+		}
+		if err := CopyFile(path, targetPath); err != nil {
+			return err
+		}
+		copied++
+		return os.Chtimes(targetPath, info.ModTime(), info.ModTime())
+	})
+	if err != nil {
+		return copied, removed, err
+	}
+
+	// 只删除清单中记录过的文件，不会误删构建产物
+	for _, relPath := range previous {
+		if relPath == "" || synced[relPath] || !filepath.IsLocal(relPath) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dst, filepath.FromSlash(relPath))); err == nil {
+			removed++
+		} else if !os.IsNotExist(err) {
+			return copied, removed, err
+		}
+	}
+
+	paths := make([]string, 0, len(synced))
+	for relPath := range synced {
+		paths = append(paths, relPath)
+	}
+	sort.Strings(paths)
+	return copied, removed, os.WriteFile(manifestPath, []byte(strings.Join(paths, "\n")+"\n"), 0644)
+}
+
+// FileSHA256 计算文件内容的 SHA256，返回小写十六进制字符串
+func FileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestCopyFile(t *testing.T) {
@@ -205,3 +206,111 @@ func TestCopyFileToNonExistentDir(t *testing.T) {
 		t.Error("Destination file was not created")
 	}
 }
+
+func TestDirFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "src", "lib.c")
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte("int x;"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := DirFingerprint(dir)
+	if err != nil {
+		t.Fatalf("DirFingerprint() error = %v", err)
+	}
+
+	// 版本控制目录的变化不影响指纹
+	if err := os.MkdirAll(filepath.Join(dir, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".git", "index"), []byte("index"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if second, _ := DirFingerprint(dir); second != first {
+		t.Error("Expected .git to be ignored")
+	}
+
+	// 修改时间变化时指纹变化
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(file, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if third, _ := DirFingerprint(dir); third == first {
+		t.Error("Expected fingerprint to change after modifying a file")
+	}
+}
+
+func TestSyncDir(t *testing.T) {
+	root := t.TempDir()
+	srcDir := filepath.Join(root, "src")
+	dstDir := filepath.Join(root, "dst")
+
+	files := map[string]string{"Makefile": "all:", "src/a.c": "a", "src/b.c": "b"}
+	for relPath, content := range files {
+		path := filepath.Join(srcDir, relPath)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if copied, removed, err := SyncDir(srcDir, dstDir); err != nil || copied != 3 || removed != 0 {
+		t.Fatalf("SyncDir() = %d, %d, %v, want 3 copied", copied, removed, err)
+	}
+
+	// 构建产物保留，只复制修改过的文件
+	if err := os.WriteFile(filepath.Join(dstDir, "src", "a.o"), []byte("obj"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Hour)
+	if err := os.WriteFile(filepath.Join(srcDir, "src", "b.c"), []byte("b2"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filepath.Join(srcDir, "src", "b.c"), later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Remove(filepath.Join(srcDir, "src", "a.c")); err != nil {
+		t.Fatal(err)
+	}
+
+	if copied, removed, err := SyncDir(srcDir, dstDir); err != nil || copied != 1 || removed != 1 {
+		t.Fatalf("SyncDir() = %d, %d, %v, want 1 copied and 1 removed", copied, removed, err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dstDir, "src", "b.c")); string(content) != "b2" {
+		t.Errorf("b.c = %q, want b2", content)
+	}
+	if info, err := os.Stat(filepath.Join(dstDir, "src", "b.c")); err != nil || !info.ModTime().Equal(later) {
+		t.Errorf("Expected modification time to be preserved: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dstDir, "src", "a.o")); err != nil {
+		t.Errorf("Expected build output to be kept: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dstDir, "src", "a.c")); !os.IsNotExist(err) {
+		t.Errorf("Expected file removed from source to be deleted, got %v", err)
+	}
+}
+
+func TestFileSHA256(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "hello.txt")
+	if err := os.WriteFile(file, []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := FileSHA256(file)
+	if err != nil {
+		t.Fatalf("FileSHA256() error = %v", err)
+	}
+	if want := "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"; got != want {
+		t.Errorf("FileSHA256() = %s, want %s", got, want)
+	}
+
+	if _, err := FileSHA256(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
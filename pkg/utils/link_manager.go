@@ -3,11 +3,14 @@ package utils
 import (
 	"bufio"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
 	"time"
+
+	"buildfly/internal/logging"
 )
 
 // LinkManager 依赖链接管理器，处理原子性的符号链接操作
@@ -76,7 +79,7 @@ func (lm *LinkManager) AtomicInstall(depName, version, buildTag string, sourceDi
 	// 记录到安装清单
 	if err := lm.recordInstall(manifest); err != nil {
 		// 记录失败不影响安装，但需要警告
-		fmt.Printf("Warning: failed to record install manifest: %v\n", err)
+		slog.Warn("Failed to record install manifest", "error", err)
 	}
 
 	return nil
@@ -205,7 +208,7 @@ func (lm *LinkManager) executeLinks(linksDir string, manifest *InstallManifest)
 				linkInfo.TargetPath, linkInfo.SourcePath, err)
 		}
 
-		fmt.Printf("  Linked %s -> %s\n", linkInfo.TargetPath, linkInfo.SourcePath)
+		fmt.Fprintf(logging.Console(), "  Linked %s -> %s\n", linkInfo.TargetPath, linkInfo.SourcePath)
 	}
 
 	return nil
@@ -255,7 +258,7 @@ func (lm *LinkManager) AtomicUninstall(depName string) error {
 	}
 
 	if len(linksToRemove) == 0 {
-		fmt.Printf("No installed links found for dependency: %s\n", depName)
+		fmt.Fprintf(logging.Console(), "No installed links found for dependency: %s\n", depName)
 		return nil
 	}
 
@@ -278,7 +281,7 @@ func (lm *LinkManager) AtomicUninstall(depName string) error {
 
 	// 从清单中移除记录
 	if err := lm.removeFromManifest(depName); err != nil {
-		fmt.Printf("Warning: failed to remove from manifest: %v\n", err)
+		slog.Warn("Failed to remove from install manifest", "error", err)
 	}
 
 	return nil
@@ -323,7 +326,7 @@ func (lm *LinkManager) executeUninstall(tempDir string) error {
 				if err := os.RemoveAll(targetPath); err != nil {
 					return fmt.Errorf("failed to remove target path %s: %w", targetPath, err)
 				}
-				fmt.Printf("  Removed %s\n", targetPath)
+				fmt.Fprintf(logging.Console(), "  Removed %s\n", targetPath)
 			}
 		}
 
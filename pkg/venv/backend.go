@@ -1,6 +1,9 @@
 package venv
 
-import "os/exec"
+import (
+	"log/slog"
+	"os/exec"
+)
 
 // VEnvBackend 虚拟环境后端接口
 type VEnvBackend interface {
@@ -30,6 +33,9 @@ type VEnvBackend interface {
 
 	// 安装工具
 	InstallTool(toolName, version string) error
+
+	// 设置日志记录器
+	SetLogger(logger *slog.Logger)
 }
 
 // BackendInfo 后端信息
@@ -3,12 +3,15 @@ package venv
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"time"
+
+	"buildfly/internal/logging"
 )
 
 // Manager 虚拟环境管理器
@@ -19,6 +22,7 @@ type Manager struct {
 	backend     VEnvBackend
 	backendType BackendType
 	platform    *PlatformInfo
+	logger      *slog.Logger
 }
 
 // NewManager 创建新的虚拟环境管理器
@@ -63,6 +67,17 @@ func NewManager(config *VEnvConfig, projectRoot string) (*Manager, error) {
 	}, nil
 }
 
+// SetLogger 设置日志记录器，同时应用到 backend
+func (m *Manager) SetLogger(logger *slog.Logger) {
+	m.logger = logger
+	m.backend.SetLogger(logger)
+}
+
+// log 获取日志记录器，未设置时使用默认记录器
+func (m *Manager) log() *slog.Logger {
+	return logging.OrDefault(m.logger)
+}
+
 // NewManagerWithBackend 使用指定 backend 创建管理器
 func NewManagerWithBackend(config *VEnvConfig, projectRoot string, backendType BackendType) (*Manager, error) {
 	if config == nil {
@@ -110,6 +125,7 @@ func (m *Manager) SwitchBackend(backendType BackendType) error {
 		return fmt.Errorf("failed to create backend %s: %w", backendType, err)
 	}
 
+	backend.SetLogger(m.logger)
 	m.backend = backend
 	m.backendType = backendType
 
@@ -121,7 +137,7 @@ func (m *Manager) SwitchBackend(backendType BackendType) error {
 
 // Initialize 初始化虚拟环境
 func (m *Manager) Initialize() error {
-	fmt.Printf("Initializing C++ virtual environment with %s backend at: %s\n", m.backendType, m.rootDir)
+	m.log().Info("Initializing C++ virtual environment", "backend", m.backendType, "root", m.rootDir)
 
 	// 使用 backend 初始化环境
 	if err := m.backend.Initialize(m.config, m.rootDir); err != nil {
@@ -132,7 +148,7 @@ func (m *Manager) Initialize() error {
 		return fmt.Errorf("failed to create environment info: %w", err)
 	}
 
-	fmt.Println("Virtual environment initialized successfully")
+	m.log().Info("Virtual environment initialized successfully")
 	return nil
 }
 
@@ -141,7 +157,7 @@ func (m *Manager) ensureBackendInstalled() error {
 	backendInfo := m.backend.GetInfo()
 
 	if !backendInfo.Installed {
-		fmt.Printf("%s not found, installing...\n", m.backendType)
+		m.log().Info("Backend not found, installing...", "backend", m.backendType)
 
 		// 根据 backend 类型获取版本
 		var version string
@@ -155,9 +171,9 @@ func (m *Manager) ensureBackendInstalled() error {
 		if err := m.backend.Install(version); err != nil {
 			return fmt.Errorf("failed to install %s: %w", m.backendType, err)
 		}
-		fmt.Printf("%s installed successfully\n", m.backendType)
+		m.log().Info("Backend installed successfully", "backend", m.backendType)
 	} else {
-		fmt.Printf("%s already installed: %s (version: %s)\n", m.backendType, backendInfo.Path, backendInfo.Version)
+		m.log().Debug("Backend already installed", "backend", m.backendType, "path", backendInfo.Path, "version", backendInfo.Version)
 	}
 
 	return nil
@@ -284,7 +300,7 @@ func (m *Manager) Activate() error {
 		return fmt.Errorf("failed to set environment variables: %w", err)
 	}
 
-	fmt.Printf("Virtual environment activated: %s\n", m.rootDir)
+	m.log().Info("Virtual environment activated", "root", m.rootDir)
 	return nil
 }
 
@@ -298,7 +314,7 @@ func (m *Manager) Deactivate() error {
 	// 清理环境变量
 	m.clearEnvironmentVariables()
 
-	fmt.Println("Virtual environment deactivated")
+	m.log().Info("Virtual environment deactivated")
 	return nil
 }
 
@@ -389,14 +405,14 @@ func (m *Manager) Reset() error {
 		return fmt.Errorf("virtual environment not initialized")
 	}
 
-	fmt.Printf("Resetting virtual environment: %s\n", m.rootDir)
+	m.log().Info("Resetting virtual environment", "root", m.rootDir)
 
 	// 删除整个环境目录
 	if err := os.RemoveAll(m.rootDir); err != nil {
 		return fmt.Errorf("failed to remove environment directory: %w", err)
 	}
 
-	fmt.Println("Virtual environment reset successfully")
+	m.log().Info("Virtual environment reset successfully")
 	return nil
 }
 
@@ -477,7 +493,7 @@ func (m *Manager) Run(args []string) error {
 
 	// 如果环境未初始化，自动初始化
 	if !m.isInitialized() {
-		fmt.Println("Virtual environment not initialized, creating...")
+		m.log().Info("Virtual environment not initialized, creating...")
 		if err := m.Initialize(); err != nil {
 			return fmt.Errorf("failed to initialize virtual environment: %w", err)
 		}
@@ -556,7 +572,7 @@ func (m *Manager) installTool(toolName, version string) error {
 
 // installGCC 安装GCC（仅在Linux/macOS上）
 func (m *Manager) installGCC() error {
-	fmt.Printf("Installing GCC %s...\n", m.config.CPPTools.GCC.Version)
+	m.log().Info("Installing GCC...", "version", m.config.CPPTools.GCC.Version)
 
 	// 这里简化处理，实际实现可能需要根据平台使用不同的安装方法
 	// 例如：在Ubuntu上使用apt，在macOS上使用brew，或者从源码编译
@@ -570,7 +586,7 @@ func (m *Manager) installGCC() error {
 			}
 
 			cmd = exec.Command("sudo", "apt", "install", "build-essential")
-			cmd.Stdout = os.Stdout
+			cmd.Stdout = logging.Console()
 			cmd.Stderr = os.Stderr
 			if err := cmd.Run(); err != nil {
 				return fmt.Errorf("failed to install build-essential: %w", err)
@@ -579,7 +595,7 @@ func (m *Manager) installGCC() error {
 	case "darwin":
 		if m.platform.PackageManager == "brew" {
 			cmd := exec.Command("brew", "install", "gcc")
-			cmd.Stdout = os.Stdout
+			cmd.Stdout = logging.Console()
 			cmd.Stderr = os.Stderr
 			if err := cmd.Run(); err != nil {
 				return fmt.Errorf("failed to install gcc via brew: %w", err)
@@ -590,19 +606,19 @@ func (m *Manager) installGCC() error {
 	// 更新工具信息
 	m.updateToolInfo("gcc", m.config.CPPTools.GCC.Version)
 
-	fmt.Println("GCC installed successfully")
+	m.log().Info("GCC installed successfully")
 	return nil
 }
 
 // installClang 安装Clang
 func (m *Manager) installClang() error {
-	fmt.Printf("Installing Clang %s...\n", m.config.CPPTools.Clang.Version)
+	m.log().Info("Installing Clang...", "version", m.config.CPPTools.Clang.Version)
 
 	switch m.platform.OS {
 	case "linux":
 		if m.platform.PackageManager == "apt" {
 			cmd := exec.Command("sudo", "apt", "install", "-y", "clang")
-			cmd.Stdout = os.Stdout
+			cmd.Stdout = logging.Console()
 			cmd.Stderr = os.Stderr
 			if err := cmd.Run(); err != nil {
 				return fmt.Errorf("failed to install clang: %w", err)
@@ -610,13 +626,13 @@ func (m *Manager) installClang() error {
 		}
 	case "darwin":
 		// macOS通常已经预装了Clang
-		fmt.Println("Clang is typically pre-installed on macOS")
+		m.log().Info("Clang is typically pre-installed on macOS")
 	}
 
 	// 更新工具信息
 	m.updateToolInfo("clang", m.config.CPPTools.Clang.Version)
 
-	fmt.Println("Clang installed successfully")
+	m.log().Info("Clang installed successfully")
 	return nil
 }
 
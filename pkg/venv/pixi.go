@@ -2,11 +2,14 @@ package venv
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
+
+	"buildfly/internal/logging"
 )
 
 // PixiBackend Pixi 后端实现
@@ -15,6 +18,7 @@ type PixiBackend struct {
 	rootDir  string
 	config   *VEnvConfig
 	platform *PlatformInfo
+	logger   *slog.Logger
 }
 
 // NewPixiBackend 创建 Pixi 后端实例
@@ -24,6 +28,16 @@ func NewPixiBackend() *PixiBackend {
 	}
 }
 
+// SetLogger 设置日志记录器
+func (p *PixiBackend) SetLogger(logger *slog.Logger) {
+	p.logger = logger
+}
+
+// log 获取日志记录器，未设置时使用默认记录器
+func (p *PixiBackend) log() *slog.Logger {
+	return logging.OrDefault(p.logger)
+}
+
 // Detect 检测 Pixi 是否已安装
 func (p *PixiBackend) Detect() (*BackendInfo, error) {
 	info, err := p.detectPixi()
@@ -103,7 +117,7 @@ func (p *PixiBackend) getPixiVersion(pixiPath string) (string, error) {
 
 // Install 安装 Pixi
 func (p *PixiBackend) Install(version string) error {
-	fmt.Printf("Installing Pixi %s...\n", version)
+	p.log().Info("Installing Pixi...", "version", version)
 
 	switch runtime.GOOS {
 	case "windows":
@@ -128,14 +142,14 @@ func (p *PixiBackend) installPixiUnix(version string) error {
 	}
 
 	cmd.Env = env
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = logging.Console()
 	cmd.Stderr = os.Stderr
 
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to install Pixi: %w", err)
 	}
 
-	fmt.Println("Pixi installed successfully")
+	p.log().Info("Pixi installed successfully")
 	return nil
 }
 
@@ -150,14 +164,14 @@ func (p *PixiBackend) installPixiWindows(version string) error {
 	// 使用 PowerShell 安装脚本
 	cmd := exec.Command("powershell", "-ExecutionPolicy", "ByPass", "-c", "irm -useb https://pixi.sh/install.ps1 | iex")
 	cmd.Env = env
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = logging.Console()
 	cmd.Stderr = os.Stderr
 
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to install Pixi on Windows: %w", err)
 	}
 
-	fmt.Println("Pixi installed successfully")
+	p.log().Info("Pixi installed successfully")
 	return nil
 }
 
@@ -166,7 +180,7 @@ func (p *PixiBackend) Initialize(config *VEnvConfig, rootDir string) error {
 	p.config = config
 	p.rootDir = rootDir
 
-	fmt.Printf("Initializing Pixi environment at: %s\n", rootDir)
+	p.log().Info("Initializing Pixi environment", "root", rootDir)
 
 	// 确保目录存在
 	if err := os.MkdirAll(rootDir, 0755); err != nil {
@@ -188,7 +202,7 @@ func (p *PixiBackend) Initialize(config *VEnvConfig, rootDir string) error {
 		return fmt.Errorf("failed to install C++ tools: %w", err)
 	}
 
-	fmt.Println("Pixi environment initialized successfully")
+	p.log().Info("Pixi environment initialized successfully")
 	return nil
 }
 
@@ -198,7 +212,7 @@ func (p *PixiBackend) CreateConfig(config *VEnvConfig, rootDir string) error {
 
 	// 检查文件是否已存在
 	if _, err := os.Stat(pixiTomlPath); err == nil {
-		fmt.Printf("Pixi config file already exists: %s\n", pixiTomlPath)
+		p.log().Debug("Pixi config file already exists", "path", pixiTomlPath)
 		return nil
 	}
 
@@ -243,7 +257,7 @@ pip = "*"
 		return fmt.Errorf("failed to write pixi.toml: %w", err)
 	}
 
-	fmt.Printf("Created Pixi config file: %s\n", pixiTomlPath)
+	p.log().Info("Created Pixi config file", "path", pixiTomlPath)
 	return nil
 }
 
@@ -387,7 +401,7 @@ func (p *PixiBackend) initPixiProject() error {
 	// 运行 pixi install
 	cmd := exec.Command(p.info.Path, "install")
 	cmd.Dir = p.rootDir
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = logging.Console()
 	cmd.Stderr = os.Stderr
 
 	if err := cmd.Run(); err != nil {
@@ -403,19 +417,19 @@ func (p *PixiBackend) installCPPTools() error {
 		return fmt.Errorf("Pixi is not installed")
 	}
 
-	fmt.Println("Installing C++ build tools with Pixi...")
+	p.log().Info("Installing C++ build tools with Pixi...")
 
 	// Pixi 会根据 pixi.toml 自动安装所有依赖
 	cmd := exec.Command(p.info.Path, "install")
 	cmd.Dir = p.rootDir
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = logging.Console()
 	cmd.Stderr = os.Stderr
 
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to install C++ tools: %w", err)
 	}
 
-	fmt.Println("C++ tools installed successfully")
+	p.log().Info("C++ tools installed successfully")
 	return nil
 }
 
@@ -437,7 +451,7 @@ func (p *PixiBackend) Activate() error {
 		os.Setenv("PATH", newPath)
 	}
 
-	fmt.Printf("Pixi environment activated: %s\n", p.rootDir)
+	p.log().Debug("Pixi environment activated", "root", p.rootDir)
 	return nil
 }
 
@@ -446,7 +460,7 @@ func (p *PixiBackend) Deactivate() error {
 	os.Unsetenv("BUILDFLY_ENV_ROOT")
 	os.Unsetenv("BUILDFLY_BACKEND")
 
-	fmt.Println("Pixi environment deactivated")
+	p.log().Debug("Pixi environment deactivated")
 	return nil
 }
 
@@ -466,7 +480,7 @@ func (p *PixiBackend) Run(args []string) error {
 
 	cmd := exec.Command(p.info.Path, cmdArgs...)
 	cmd.Dir = p.rootDir
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = logging.Console()
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
 
@@ -507,7 +521,7 @@ func (p *PixiBackend) InstallTool(toolName, version string) error {
 
 	cmd := exec.Command(p.info.Path, "add", pkgSpec)
 	cmd.Dir = p.rootDir
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = logging.Console()
 	cmd.Stderr = os.Stderr
 
 	if err := cmd.Run(); err != nil {
@@ -2,11 +2,14 @@ package venv
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
+
+	"buildfly/internal/logging"
 )
 
 // UVBackend UV 后端实现
@@ -15,6 +18,7 @@ type UVBackend struct {
 	rootDir  string
 	config   *VEnvConfig
 	detector *UVDetector
+	logger   *slog.Logger
 }
 
 // NewUVBackend 创建 UV 后端实例
@@ -24,6 +28,16 @@ func NewUVBackend() *UVBackend {
 	}
 }
 
+// SetLogger 设置日志记录器
+func (u *UVBackend) SetLogger(logger *slog.Logger) {
+	u.logger = logger
+}
+
+// log 获取日志记录器，未设置时使用默认记录器
+func (u *UVBackend) log() *slog.Logger {
+	return logging.OrDefault(u.logger)
+}
+
 // Detect 检测 UV 是否已安装
 func (u *UVBackend) Detect() (*BackendInfo, error) {
 	info, err := u.detector.DetectUV()
@@ -58,7 +72,7 @@ func (u *UVBackend) Initialize(config *VEnvConfig, rootDir string) error {
 	u.config = config
 	u.rootDir = rootDir
 
-	fmt.Printf("Initializing UV environment at: %s\n", rootDir)
+	u.log().Info("Initializing UV environment", "root", rootDir)
 
 	// 检测并安装 UV
 	if err := u.ensureUVInstalled(); err != nil {
@@ -85,7 +99,7 @@ func (u *UVBackend) Initialize(config *VEnvConfig, rootDir string) error {
 		return fmt.Errorf("failed to create activation scripts: %w", err)
 	}
 
-	fmt.Println("UV environment initialized successfully")
+	u.log().Info("UV environment initialized successfully")
 	return nil
 }
 
@@ -97,7 +111,7 @@ func (u *UVBackend) CreateConfig(config *VEnvConfig, rootDir string) error {
 
 	// 检查文件是否已存在
 	if _, err := os.Stat(uvConfigPath); err == nil {
-		fmt.Printf("UV config file already exists: %s\n", uvConfigPath)
+		u.log().Debug("UV config file already exists", "path", uvConfigPath)
 		return nil
 	}
 
@@ -123,7 +137,7 @@ dev-dependencies = [
 		return fmt.Errorf("failed to write UV config file: %w", err)
 	}
 
-	fmt.Printf("Created UV config file: %s\n", uvConfigPath)
+	u.log().Info("Created UV config file", "path", uvConfigPath)
 	return nil
 }
 
@@ -138,13 +152,13 @@ func (u *UVBackend) ensureUVInstalled() error {
 	}
 
 	if !u.info.Installed {
-		fmt.Println("UV not found, installing...")
+		u.log().Info("UV not found, installing...")
 		if err := u.detector.InstallUV(u.config.UVVersion); err != nil {
 			return fmt.Errorf("failed to install UV: %w", err)
 		}
-		fmt.Println("UV installed successfully")
+		u.log().Info("UV installed successfully")
 	} else {
-		fmt.Printf("UV already installed: %s (version: %s)\n", u.info.Path, u.info.Version)
+		u.log().Debug("UV already installed", "path", u.info.Path, "version", u.info.Version)
 	}
 
 	return nil
@@ -152,7 +166,7 @@ func (u *UVBackend) ensureUVInstalled() error {
 
 // createPythonEnvironment 使用UV创建Python虚拟环境
 func (u *UVBackend) createPythonEnvironment() error {
-	fmt.Println("Creating Python virtual environment with UV...")
+	u.log().Info("Creating Python virtual environment with UV...")
 
 	if u.info == nil || !u.info.Installed {
 		return fmt.Errorf("UV is not installed")
@@ -169,28 +183,28 @@ func (u *UVBackend) createPythonEnvironment() error {
 
 	// 检查是否已经有虚拟环境
 	if _, err := os.Stat(filepath.Join(u.rootDir, "pyvenv.cfg")); err == nil {
-		fmt.Printf("Python virtual environment already exists at: %s\n", u.rootDir)
+		u.log().Debug("Python virtual environment already exists", "root", u.rootDir)
 	} else {
 		// 使用UV直接在rootDir下创建虚拟环境
 		cmd := exec.Command(u.info.Path, "venv", u.rootDir, "--python", u.config.Python.Version)
 		cmd.Dir = filepath.Dir(u.rootDir)
 		cmd.Env = env
-		cmd.Stdout = os.Stdout
+		cmd.Stdout = logging.Console()
 		cmd.Stderr = os.Stderr
 
 		if err := cmd.Run(); err != nil {
 			return fmt.Errorf("failed to create Python virtual environment: %w", err)
 		}
 
-		fmt.Printf("Python %s virtual environment created at: %s\n", u.config.Python.Version, u.rootDir)
+		u.log().Info("Python virtual environment created", "python", u.config.Python.Version, "root", u.rootDir)
 	}
 
 	// 安装Python包
 	if len(u.config.Python.Packages) > 0 {
-		fmt.Printf("Installing Python packages: %v\n", u.config.Python.Packages)
+		u.log().Info("Installing Python packages", "packages", strings.Join(u.config.Python.Packages, " "))
 		for _, pkg := range u.config.Python.Packages {
 			if err := u.installPythonPackage(pkg); err != nil {
-				fmt.Printf("Warning: failed to install package %s: %v\n", pkg, err)
+				u.log().Warn("Failed to install package", "package", pkg, "error", err)
 			}
 		}
 	}
@@ -217,7 +231,7 @@ func (u *UVBackend) installPythonPackage(packageName string) error {
 	}
 
 	cmd.Env = env
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = logging.Console()
 	cmd.Stderr = os.Stderr
 
 	return cmd.Run()
@@ -225,7 +239,7 @@ func (u *UVBackend) installPythonPackage(packageName string) error {
 
 // installCPPTools 安装C++构建工具
 func (u *UVBackend) installCPPTools() error {
-	fmt.Println("Installing C++ build tools...")
+	u.log().Info("Installing C++ build tools...")
 
 	if u.info == nil || !u.info.Installed {
 		return fmt.Errorf("UV is not installed")
@@ -250,7 +264,7 @@ func (u *UVBackend) installCPPTools() error {
 
 // installCMake 安装CMake
 func (u *UVBackend) installCMake() error {
-	fmt.Printf("Installing CMake %s...\n", u.config.CPPTools.CMake.Version)
+	u.log().Info("Installing CMake...", "version", u.config.CPPTools.CMake.Version)
 
 	// 设置环境变量
 	env := os.Environ()
@@ -265,20 +279,20 @@ func (u *UVBackend) installCMake() error {
 	cmd := exec.Command(u.info.Path, "tool", "install", "cmake=="+u.config.CPPTools.CMake.Version)
 	cmd.Dir = u.rootDir
 	cmd.Env = env
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = logging.Console()
 	cmd.Stderr = os.Stderr
 
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to install CMake: %w", err)
 	}
 
-	fmt.Println("CMake installed successfully")
+	u.log().Info("CMake installed successfully")
 	return nil
 }
 
 // installNinja 安装Ninja
 func (u *UVBackend) installNinja() error {
-	fmt.Printf("Installing Ninja %s...\n", u.config.CPPTools.Ninja.Version)
+	u.log().Info("Installing Ninja...", "version", u.config.CPPTools.Ninja.Version)
 
 	// 设置环境变量
 	env := os.Environ()
@@ -293,14 +307,14 @@ func (u *UVBackend) installNinja() error {
 	cmd := exec.Command(u.info.Path, "tool", "install", "ninja=="+u.config.CPPTools.Ninja.Version)
 	cmd.Dir = u.rootDir
 	cmd.Env = env
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = logging.Console()
 	cmd.Stderr = os.Stderr
 
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to install Ninja: %w", err)
 	}
 
-	fmt.Println("Ninja installed successfully")
+	u.log().Info("Ninja installed successfully")
 	return nil
 }
 
@@ -367,7 +381,7 @@ if exist "%s\tools\cmake" (
 
 echo BuildFly UV environment activated
 echo Environment root: %%BUILDFLY_ENV_ROOT%%
-`, u.rootDir, u.rootDir, u.rootDir, u.rootDir, u.rootDir, u.rootDir, u.rootDir, u.rootDir, u.rootDir, u.rootDir)
+`, u.rootDir, u.rootDir, u.rootDir, u.rootDir, u.rootDir, u.rootDir, u.rootDir, u.rootDir, u.rootDir, u.rootDir, u.rootDir)
 
 	windowsScriptFile := filepath.Join(u.rootDir, "activate.bat")
 	if err := os.WriteFile(windowsScriptFile, []byte(windowsScript), 0755); err != nil {
@@ -389,7 +403,7 @@ func (u *UVBackend) Activate() error {
 	newPath := binDir + string(filepath.ListSeparator) + currentPath
 	os.Setenv("PATH", newPath)
 
-	fmt.Printf("UV environment activated: %s\n", u.rootDir)
+	u.log().Debug("UV environment activated", "root", u.rootDir)
 	return nil
 }
 
@@ -398,7 +412,7 @@ func (u *UVBackend) Deactivate() error {
 	os.Unsetenv("BUILDFLY_ENV_ROOT")
 	os.Unsetenv("BUILDFLY_BACKEND")
 
-	fmt.Println("UV environment deactivated")
+	u.log().Debug("UV environment deactivated")
 	return nil
 }
 
@@ -464,7 +478,7 @@ func (u *UVBackend) Run(args []string) error {
 
 	cmd.Env = env
 	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = logging.Console()
 	cmd.Stderr = os.Stderr
 
 	return cmd.Run()
@@ -502,7 +516,7 @@ func (u *UVBackend) InstallTool(toolName, version string) error {
 
 	cmd := exec.Command(u.info.Path, "tool", "install", pkgSpec)
 	cmd.Dir = u.rootDir
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = logging.Console()
 	cmd.Stderr = os.Stderr
 
 	if err := cmd.Run(); err != nil {